@@ -0,0 +1,286 @@
+// Package cli 命令行工具, 用于脱离数据库与HTTP服务执行工作流定义文件
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/definition"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+	"api-flow/mock"
+)
+
+// 退出码
+const (
+	ExitSuccess = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+// inputFlags 可重复的 --input key=value 参数
+type inputFlags map[string]interface{}
+
+func (f inputFlags) String() string {
+	return fmt.Sprint(map[string]interface{}(f))
+}
+
+// Set 解析 key=value, value 为合法JSON时按JSON解析(数字、布尔、对象等), 否则作为字符串
+func (f inputFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("输入参数格式应为 key=value: %s", value)
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(parts[1]), &parsed); err != nil {
+		parsed = parts[1]
+	}
+	f[parts[0]] = parsed
+	return nil
+}
+
+// Run 执行 run 子命令: api-flow run flow.yaml --input key=value
+func Run(args []string) int {
+	return Execute(args, os.Stdout, os.Stderr)
+}
+
+// Execute 与 Run 相同, 执行结果写入 stdout, 错误信息与执行事件写入 stderr, 返回退出码
+func Execute(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	inputs := inputFlags{}
+	fs.Var(inputs, "input", "流程输入参数 key=value, 可重复")
+	inputsFile := fs.String("inputs-file", "", "从JSON文件读取流程输入参数")
+	jsonOutput := fs.Bool("json", false, "以JSON格式输出执行结果")
+	mocksFile := fs.String("mocks", "", "从JSON文件读取模拟接口, 节点的HTTP请求由模拟接口处理")
+	recordFile := fs.String("record", "", "录制节点的出站请求与响应, 执行结束后写入JSON文件")
+	replayFile := fs.String("replay", "", "从录制文件回放节点的出站请求, 不访问网络")
+	dryRun := fs.Bool("dry-run", false, "试运行: 只渲染节点的请求, 不发送")
+	showEvents := fs.Bool("events", false, "执行过程中向标准错误逐行输出执行事件(JSON)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "用法: api-flow run <flow.yaml|flow.json> [--input key=value ...] [--inputs-file inputs.json] [--mocks mocks.json] [--record out.json] [--replay recording.json] [--dry-run] [--events] [--json]")
+		fs.PrintDefaults()
+	}
+
+	// 允许文件路径出现在参数之前或之后
+	if err := fs.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	path := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return ExitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "多余的参数: %s\n", strings.Join(fs.Args(), " "))
+		return ExitUsage
+	}
+
+	flowInputs := make(map[string]interface{})
+	if *inputsFile != "" {
+		data, err := os.ReadFile(*inputsFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "读取输入文件失败: %v\n", err)
+			return ExitUsage
+		}
+		if err := json.Unmarshal(data, &flowInputs); err != nil {
+			fmt.Fprintf(stderr, "解析输入文件失败: %v\n", err)
+			return ExitUsage
+		}
+	}
+	for key, value := range inputs {
+		flowInputs[key] = value
+	}
+
+	ctx := context.Background()
+	if *mocksFile != "" {
+		server, err := loadMocks(*mocksFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "加载模拟接口失败: %v\n", err)
+			return ExitUsage
+		}
+		ctx = server.Redirect(ctx)
+	}
+	if *replayFile != "" {
+		exchanges, err := loadRecording(*replayFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "加载录制文件失败: %v\n", err)
+			return ExitUsage
+		}
+		ctx = outbound.WithPlayer(ctx, outbound.NewPlayer(exchanges))
+	}
+	if *dryRun {
+		ctx = outbound.WithDryRun(ctx)
+	}
+	var recorder *outbound.Recorder
+	if *recordFile != "" {
+		recorder = outbound.NewRecorder()
+		ctx = outbound.WithRecorder(ctx, recorder)
+	}
+
+	def, err := definition.LoadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "加载工作流定义失败: %v\n", err)
+		return ExitFailure
+	}
+
+	nodeEngine := engine_nodes.NewNodeEngine()
+	problems := def.Validate(func(code string) bool {
+		_, err := nodeEngine.GetExecutor(code)
+		return err == nil
+	})
+	if len(problems) > 0 {
+		fmt.Fprintln(stderr, "工作流定义校验失败:")
+		for _, problem := range problems {
+			fmt.Fprintf(stderr, "  %s\n", problem)
+		}
+		return ExitFailure
+	}
+
+	var stream *engine.EventStream
+	var printed <-chan struct{}
+	if *showEvents {
+		stream = engine.NewEventStream()
+		ctx = engine.WithEventStream(ctx, stream)
+		printed = printEvents(stderr, stream)
+	}
+
+	nodes, edges := def.ToModels()
+	results, runErr := engine.NewRunner(nodeEngine).RunContext(ctx, nodes, edges, flowInputs)
+	if recorder != nil {
+		if err := saveRecording(*recordFile, recorder.Exchanges()); err != nil {
+			fmt.Fprintf(stderr, "保存录制文件失败: %v\n", err)
+			return ExitFailure
+		}
+	}
+
+	failed := runErr != nil
+	for _, result := range results {
+		if result.Status != core.ExecuteStatusSuccess {
+			failed = true
+		}
+	}
+	if stream != nil {
+		status := core.ExecuteStatusSuccess
+		if failed {
+			status = core.ExecuteStatusError
+		}
+		finished := engine.ExecutionEvent{Type: engine.EventInstanceFinished, Status: &status}
+		if runErr != nil {
+			finished.Message = runErr.Error()
+		}
+		stream.Emit(finished)
+		stream.Close()
+		<-printed
+	}
+
+	if *jsonOutput {
+		output := map[string]interface{}{
+			"name":        def.Name,
+			"success":     !failed,
+			"nodeResults": results,
+		}
+		if runErr != nil {
+			output["error"] = runErr.Error()
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(output)
+	} else {
+		printResults(stdout, results)
+		if runErr != nil {
+			fmt.Fprintf(stderr, "执行失败: %v\n", runErr)
+		}
+	}
+
+	if failed {
+		return ExitFailure
+	}
+	return ExitSuccess
+}
+
+// loadMocks 从JSON文件(模拟接口数组)创建模拟接口服务
+func loadMocks(path string) (*mock.Server, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var routes []mock.Route
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, err
+	}
+	server := mock.NewServer()
+	if err := server.SetRoutes(routes); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// recordingFile 录制文件格式, 与接口返回的实例录制一致
+type recordingFile struct {
+	Exchanges []outbound.Exchange `json:"exchanges"`
+}
+
+// loadRecording 读取录制文件
+func loadRecording(path string) ([]outbound.Exchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file recordingFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return file.Exchanges, nil
+}
+
+// saveRecording 写入录制文件
+func saveRecording(path string, exchanges []outbound.Exchange) error {
+	data, err := json.MarshalIndent(recordingFile{Exchanges: exchanges}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// printEvents 在后台逐行输出执行事件, 事件流关闭且输出完毕后关闭返回的通道
+func printEvents(w io.Writer, stream *engine.EventStream) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		encoder := json.NewEncoder(w)
+		after := 0
+		for {
+			events, more := stream.Wait(context.Background(), after)
+			if !more {
+				return
+			}
+			for _, event := range events {
+				encoder.Encode(event)
+				after = event.Seq
+			}
+		}
+	}()
+	return done
+}
+
+// printResults 逐行输出节点执行结果
+func printResults(w io.Writer, results []core.ExecuteResult) {
+	for _, result := range results {
+		data, _ := json.Marshal(result.Data)
+		fmt.Fprintf(w, "[%s] %s %s\n", result.Status, result.NodeKey, data)
+		if result.Error != "" {
+			fmt.Fprintf(w, "    error: %s\n", result.Error)
+		}
+	}
+}
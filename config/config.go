@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"gopkg.in/yaml.v2"
+
+	"api-flow/engine/outbound"
 )
 
 // Config 应用程序配置结构
@@ -20,6 +22,10 @@ type Config struct {
 	Server struct {
 		Port int `yaml:"port"`
 	} `yaml:"server"`
+	// Secrets 节点认证等使用的密钥, 也可以通过环境变量 API_FLOW_SECRET_<NAME> 提供
+	Secrets map[string]string `yaml:"secrets"`
+	// Outbound 节点出站请求的限流与熔断配置
+	Outbound outbound.Config `yaml:"outbound"`
 }
 
 // LoadConfig 从配置文件加载配置
@@ -0,0 +1,8 @@
+package dto
+
+// GraphQLIntrospectionRequest 内省 GraphQL 接口的请求, 字段与 graphql 节点配置一致
+type GraphQLIntrospectionRequest struct {
+	Endpoint string                 `json:"endpoint" binding:"required"`
+	Headers  map[string]interface{} `json:"headers"`
+	Auth     map[string]interface{} `json:"auth"`
+}
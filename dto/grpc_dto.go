@@ -0,0 +1,8 @@
+package dto
+
+// GrpcServicesRequest 列出 gRPC 服务的请求, 描述符也可以通过 multipart 的 file 字段上传
+type GrpcServicesRequest struct {
+	Target        string `json:"target" form:"target"`               // 服务地址 host:port, 使用反射时必填
+	TLS           bool   `json:"tls" form:"tls"`                     // 是否使用TLS
+	DescriptorSet string `json:"descriptorSet" form:"descriptorSet"` // FileDescriptorSet(base64), 为空时使用服务端反射
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"api-flow/engine"
+	"api-flow/engine/engine_nodes"
+	"api-flow/importer"
+)
+
+// OpenAPIImportRequest 从 OpenAPI 文档生成 API 节点的请求
+type OpenAPIImportRequest struct {
+	Spec         string   `json:"spec" form:"spec"`                 // 文档内容(JSON/YAML), 也可以通过 file 上传
+	OperationIDs []string `json:"operationIds" form:"operationIds"` // 选中的接口, 取自接口列表中的 id
+	BaseURL      string   `json:"baseUrl" form:"baseUrl"`           // 覆盖文档中声明的接口地址
+}
+
+// CurlImportRequest 从 cURL 命令生成 API 节点的请求
+type CurlImportRequest struct {
+	Command string `json:"command" binding:"required"`
+}
+
+// HARImportResult HAR 文件导入结果
+type HARImportResult struct {
+	ID      uint                     `json:"id,omitempty"` // 保存为工作流时的工作流ID
+	Nodes   []engine_nodes.Node      `json:"nodes"`
+	Edges   []engine.Edge            `json:"edges"`
+	Skipped []importer.HAREntryError `json:"skipped,omitempty"`
+}
@@ -3,6 +3,7 @@ package dto
 import (
 	"api-flow/engine"
 	"api-flow/engine/core"
+	"api-flow/engine/definition"
 	"api-flow/engine/engine_nodes"
 )
 
@@ -27,8 +28,17 @@ type WorkflowDTO struct {
 // WorkflowExecutionRequest 工作流执行请求
 type WorkflowExecutionRequest struct {
 	WorkflowID uint                   `json:"workflowId" binding:"required"`
-	Sync       bool                   `json:"sync"`
+	Sync       bool                   `json:"sync"` // 兼容旧版本的参数, 不再使用; 后台执行见 Async
 	Inputs     map[string]interface{} `json:"inputs"`
+	Mock       bool                   `json:"mock"`   // 节点的 HTTP 请求由模拟接口处理
+	Record     bool                   `json:"record"` // 录制节点的出站请求与响应, 用于回放
+	// StartNode 部分执行: 只执行该节点及其后续节点, 其余节点使用 FromInstance 实例的结果
+	StartNode    string `json:"startNode"`
+	FromInstance uint   `json:"fromInstance"`
+	// DryRun 试运行: 会发出网络请求的节点只渲染请求, 输出按输出格式生成的占位值
+	DryRun bool `json:"dryRun"`
+	// Async 为 true 时在后台执行, 立即返回运行中的实例; 默认同步执行
+	Async bool `json:"async"`
 }
 
 // WorkflowExecutionResult 工作流执行结果
@@ -37,6 +47,46 @@ type WorkflowExecutionResult struct {
 	WorkflowName string               `json:"workflowName"`
 	Status       core.ExecuteStatus   `json:"status"`
 	NodeResults  []core.ExecuteResult `json:"nodeResults"`
+	Outputs      map[string]interface{} `json:"outputs,omitempty"` // 末端节点的输出, 以节点键为key
 	ErrorMessage string               `json:"errorMessage,omitempty"`
 	Duration     int64                `json:"duration"`
+	Mock         bool                 `json:"mock,omitempty"`
+	InstanceID   uint                 `json:"instanceId,omitempty"`
+	Recorded     bool                 `json:"recorded,omitempty"`
+	ReplayOf     uint                 `json:"replayOf,omitempty"` // 回放的原实例ID
+	RerunOf      uint                 `json:"rerunOf,omitempty"`  // 重新执行的原实例ID
+	StartNode    string               `json:"startNode,omitempty"`
+	FromInstance uint                 `json:"fromInstance,omitempty"`
+	Reused       []string             `json:"reused,omitempty"` // 部分执行时使用原实例结果的节点
+	DryRun       bool                 `json:"dryRun,omitempty"`
+}
+
+// WorkflowDebugRequest 调试执行工作流的请求
+type WorkflowDebugRequest struct {
+	Inputs map[string]interface{} `json:"inputs"`
+	// Breakpoints 在这些节点(节点键)执行前暂停
+	Breakpoints []string `json:"breakpoints"`
+	Mock        bool     `json:"mock"`
+	// Timeout 暂停后等待调试命令的秒数, 超时后会话终止, 默认 600
+	Timeout int `json:"timeout"`
+}
+
+// WorkflowRerunRequest 重新执行流程实例的请求
+type WorkflowRerunRequest struct {
+	// Inputs 覆盖原实例的输入参数, 按顶层字段合并
+	Inputs map[string]interface{} `json:"inputs"`
+	// Version current(默认) 使用工作流当前的节点与连线, original 使用原实例执行时的定义
+	Version string `json:"version"`
+	Mock    bool   `json:"mock"`
+	Record  bool   `json:"record"`
+	// StartNode 只执行该节点及其后续节点, 其余节点使用原实例的结果
+	StartNode string `json:"startNode"`
+}
+
+// WorkflowImportResult 工作流导入结果
+type WorkflowImportResult struct {
+	ID         uint                 `json:"id,omitempty"`
+	Name       string               `json:"name"`
+	KeyMapping map[string]string    `json:"keyMapping,omitempty"` // 冲突节点键的重映射 旧键 -> 新键
+	Problems   []definition.Problem `json:"problems,omitempty"`
 }
@@ -0,0 +1,313 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// 时间序列的分桶粒度
+const (
+	AnalyticsHourly = "hour"
+	AnalyticsDaily  = "day"
+)
+
+// 时间序列最多的分桶数, 超出时需要缩短时间范围或按天统计
+const maxAnalyticsBuckets = 1000
+
+// 错误信息按前 200 个字符分组, 最多返回 50 组
+const (
+	maxErrorMessageLength = 200
+	maxErrorGroups        = 50
+)
+
+// AnalyticsQuery 统计分析的时间范围 [From, To)、分桶粒度与工作流版本(为空时统计全部版本)
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	Version  string
+}
+
+// Validate 校验统计范围
+func (q *AnalyticsQuery) Validate() error {
+	if !q.From.Before(q.To) {
+		return errors.New("from 必须早于 to")
+	}
+	var step time.Duration
+	switch q.Interval {
+	case AnalyticsHourly:
+		step = time.Hour
+	case AnalyticsDaily:
+		step = 24 * time.Hour
+	default:
+		return fmt.Errorf("不支持的分桶粒度: %s", q.Interval)
+	}
+	if q.To.Sub(q.From)/step > maxAnalyticsBuckets {
+		return fmt.Errorf("时间范围内的分桶超过 %d 个, 请缩短时间范围或按天统计", maxAnalyticsBuckets)
+	}
+	return nil
+}
+
+// bucket 时间所在分桶的起始时间
+func (q *AnalyticsQuery) bucket(t time.Time) time.Time {
+	t = t.In(q.From.Location())
+	if q.Interval == AnalyticsDaily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+func (q *AnalyticsQuery) next(bucket time.Time) time.Time {
+	if q.Interval == AnalyticsDaily {
+		return bucket.AddDate(0, 0, 1)
+	}
+	return bucket.Add(time.Hour)
+}
+
+// AnalyticsSample 一次流程实例或节点的执行
+type AnalyticsSample struct {
+	Time     time.Time
+	Duration int64 // 毫秒
+	Failed   bool
+	// Version 流程实例执行时的工作流版本
+	Version string
+}
+
+// NodeSample 一次节点执行
+type NodeSample struct {
+	AnalyticsSample
+	NodeKey  string
+	NodeType string
+	Attempt  int
+	Error    string
+}
+
+// LatencyStats 执行次数、失败率与耗时分布(毫秒, 百分位使用最近秩法)
+type LatencyStats struct {
+	Total       int     `json:"total"`
+	Errors      int     `json:"errors"`
+	ErrorRate   float64 `json:"errorRate"`
+	AvgDuration float64 `json:"avgDuration"`
+	P50         int64   `json:"p50"`
+	P95         int64   `json:"p95"`
+	P99         int64   `json:"p99"`
+}
+
+// NewLatencyStats 统计一组执行
+func NewLatencyStats(samples []AnalyticsSample) LatencyStats {
+	stats := LatencyStats{Total: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+	durations := make([]int64, 0, len(samples))
+	var sum int64
+	for _, sample := range samples {
+		if sample.Failed {
+			stats.Errors++
+		}
+		durations = append(durations, sample.Duration)
+		sum += sample.Duration
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.ErrorRate = float64(stats.Errors) / float64(stats.Total)
+	stats.AvgDuration = float64(sum) / float64(stats.Total)
+	stats.P50 = percentile(durations, 50)
+	stats.P95 = percentile(durations, 95)
+	stats.P99 = percentile(durations, 99)
+	return stats
+}
+
+// percentile 最近秩法, sorted 已升序排列且不为空
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// NodeAnalytics 单个节点的统计, Retries 为重试的请求次数
+type NodeAnalytics struct {
+	NodeKey  string `json:"nodeKey"`
+	NodeType string `json:"nodeType"`
+	LatencyStats
+	Retries int `json:"retries"`
+}
+
+// ErrorAnalytics 按节点与错误信息分组的失败次数
+type ErrorAnalytics struct {
+	NodeKey  string    `json:"nodeKey"`
+	Message  string    `json:"message"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// SeriesPoint 一个时间分桶内流程实例的统计
+type SeriesPoint struct {
+	Time time.Time `json:"time"`
+	LatencyStats
+}
+
+// VersionAnalytics 一个工作流版本的统计, 用于比较不同版本的失败率与耗时
+type VersionAnalytics struct {
+	Version   string    `json:"version"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	LatencyStats
+}
+
+// WorkflowAnalytics 工作流的统计分析
+type WorkflowAnalytics struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Interval string    `json:"interval"`
+	Version  string    `json:"version,omitempty"`
+	// Summary 流程实例的整体统计
+	Summary LatencyStats     `json:"summary"`
+	Nodes   []NodeAnalytics  `json:"nodes"`
+	Errors  []ErrorAnalytics `json:"errors"`
+	Series  []SeriesPoint    `json:"series"`
+	// Versions 时间范围内各版本的统计, 不受 Version 筛选影响
+	Versions []VersionAnalytics `json:"versions"`
+}
+
+// BuildAnalytics 根据时间范围内的流程实例与节点执行生成统计分析
+func BuildAnalytics(query AnalyticsQuery, instances []AnalyticsSample, nodes []NodeSample) *WorkflowAnalytics {
+	analytics := &WorkflowAnalytics{
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+		Version:  query.Version,
+		Versions: versionAnalytics(instances),
+	}
+
+	selected := make([]AnalyticsSample, 0, len(instances))
+	for _, instance := range instances {
+		if query.Version == "" || instance.Version == query.Version {
+			selected = append(selected, instance)
+		}
+	}
+	analytics.Summary = NewLatencyStats(selected)
+	analytics.Series = seriesAnalytics(query, selected)
+
+	selectedNodes := make([]NodeSample, 0, len(nodes))
+	for _, node := range nodes {
+		if query.Version == "" || node.Version == query.Version {
+			selectedNodes = append(selectedNodes, node)
+		}
+	}
+	analytics.Nodes = nodeAnalytics(selectedNodes)
+	analytics.Errors = errorAnalytics(selectedNodes)
+	return analytics
+}
+
+// seriesAnalytics 按分桶统计, 没有执行的分桶也会返回
+func seriesAnalytics(query AnalyticsQuery, instances []AnalyticsSample) []SeriesPoint {
+	buckets := make(map[time.Time][]AnalyticsSample)
+	for _, instance := range instances {
+		bucket := query.bucket(instance.Time)
+		buckets[bucket] = append(buckets[bucket], instance)
+	}
+	series := make([]SeriesPoint, 0)
+	for bucket := query.bucket(query.From); bucket.Before(query.To); bucket = query.next(bucket) {
+		series = append(series, SeriesPoint{Time: bucket, LatencyStats: NewLatencyStats(buckets[bucket])})
+	}
+	return series
+}
+
+// nodeAnalytics 按节点统计, 按节点键排序
+func nodeAnalytics(nodes []NodeSample) []NodeAnalytics {
+	groups := make(map[string][]AnalyticsSample)
+	types := make(map[string]string)
+	retries := make(map[string]int)
+	for _, node := range nodes {
+		groups[node.NodeKey] = append(groups[node.NodeKey], node.AnalyticsSample)
+		types[node.NodeKey] = node.NodeType
+		if node.Attempt > 1 {
+			retries[node.NodeKey] += node.Attempt - 1
+		}
+	}
+	result := make([]NodeAnalytics, 0, len(groups))
+	for key, samples := range groups {
+		result = append(result, NodeAnalytics{
+			NodeKey:      key,
+			NodeType:     types[key],
+			LatencyStats: NewLatencyStats(samples),
+			Retries:      retries[key],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].NodeKey < result[j].NodeKey })
+	return result
+}
+
+// errorAnalytics 按节点与错误信息分组, 按次数降序
+func errorAnalytics(nodes []NodeSample) []ErrorAnalytics {
+	type errorKey struct{ nodeKey, message string }
+	groups := make(map[errorKey]*ErrorAnalytics)
+	for _, node := range nodes {
+		if !node.Failed {
+			continue
+		}
+		key := errorKey{node.NodeKey, truncateMessage(node.Error)}
+		group, ok := groups[key]
+		if !ok {
+			group = &ErrorAnalytics{NodeKey: key.nodeKey, Message: key.message}
+			groups[key] = group
+		}
+		group.Count++
+		if node.Time.After(group.LastSeen) {
+			group.LastSeen = node.Time
+		}
+	}
+	result := make([]ErrorAnalytics, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		if result[i].NodeKey != result[j].NodeKey {
+			return result[i].NodeKey < result[j].NodeKey
+		}
+		return result[i].Message < result[j].Message
+	})
+	if len(result) > maxErrorGroups {
+		result = result[:maxErrorGroups]
+	}
+	return result
+}
+
+// versionAnalytics 按版本统计, 按首次执行时间排序
+func versionAnalytics(instances []AnalyticsSample) []VersionAnalytics {
+	groups := make(map[string][]AnalyticsSample)
+	for _, instance := range instances {
+		groups[instance.Version] = append(groups[instance.Version], instance)
+	}
+	result := make([]VersionAnalytics, 0, len(groups))
+	for version, samples := range groups {
+		analytics := VersionAnalytics{Version: version, FirstSeen: samples[0].Time, LastSeen: samples[0].Time, LatencyStats: NewLatencyStats(samples)}
+		for _, sample := range samples {
+			if sample.Time.Before(analytics.FirstSeen) {
+				analytics.FirstSeen = sample.Time
+			}
+			if sample.Time.After(analytics.LastSeen) {
+				analytics.LastSeen = sample.Time
+			}
+		}
+		result = append(result, analytics)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FirstSeen.Before(result[j].FirstSeen) })
+	return result
+}
+
+func truncateMessage(message string) string {
+	if utf8.RuneCountInString(message) <= maxErrorMessageLength {
+		return message
+	}
+	return string([]rune(message)[:maxErrorMessageLength]) + "..."
+}
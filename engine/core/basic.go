@@ -42,6 +42,22 @@ const (
 	ExecuteStatusError
 )
 
+// String 状态名称, 用于日志与命令行输出
+func (s ExecuteStatus) String() string {
+	switch s {
+	case ExecuteStatusReady:
+		return "ready"
+	case ExecuteStatusRunning:
+		return "running"
+	case ExecuteStatusSuccess:
+		return "success"
+	case ExecuteStatusError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
 // ExecuteResult 节点执行结果
 type ExecuteResult struct {
 	NodeID  string                 `json:"nodeId"`
@@ -19,11 +19,11 @@ const (
 )
 
 type ParamDefination struct {
-	Field       string        `json:"field"`
-	Datatype    ParamDataType `json:"type"`
-	Description string        `json:"desc"`
-	Default     interface{}   `json:"default"`
-	Options     []interface{} `json:"options,omitempty"`
+	Field       string        `json:"field" yaml:"field"`
+	Datatype    ParamDataType `json:"type" yaml:"type"`
+	Description string        `json:"desc" yaml:"desc,omitempty"`
+	Default     interface{}   `json:"default" yaml:"default,omitempty"`
+	Options     []interface{} `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 func NewParamDefination(field string, datatype ParamDataType, description string) *ParamDefination {
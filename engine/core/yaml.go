@@ -0,0 +1,23 @@
+package core
+
+import "fmt"
+
+// NormalizeYAML 将 yaml.v2 解析出的 map[interface{}]interface{} 递归转换为 map[string]interface{},
+// 使 YAML 与 JSON 解析结果可以统一处理
+func NormalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprint(key)] = NormalizeYAML(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = NormalizeYAML(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
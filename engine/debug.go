@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// 调试命令
+const (
+	// DebugContinue 执行当前节点, 运行到下一个断点
+	DebugContinue = "continue"
+	// DebugStep 执行当前节点, 在下一个节点前暂停
+	DebugStep = "step"
+	// DebugSkip 不执行当前节点, 在下一个节点前暂停
+	DebugSkip = "skip"
+)
+
+// DebugStatus 调试会话状态
+type DebugStatus string
+
+const (
+	DebugRunning   DebugStatus = "running"
+	DebugPaused    DebugStatus = "paused"
+	DebugCompleted DebugStatus = "completed"
+	DebugAborted   DebugStatus = "aborted"
+)
+
+// DefaultDebugTimeout 暂停后等待调试命令的默认时长, 超时后会话终止
+const DefaultDebugTimeout = 10 * time.Minute
+
+var (
+	// ErrDebugNotPaused 会话未暂停, 不能执行调试命令
+	ErrDebugNotPaused = errors.New("调试会话未暂停")
+	// ErrDebugTimeout 暂停后长时间没有调试命令
+	ErrDebugTimeout = errors.New("调试会话超时")
+	// ErrDebugAborted 调试会话被终止
+	ErrDebugAborted = errors.New("调试会话已终止")
+)
+
+// DebugCommand 调试命令, inputs 按顶层字段覆盖当前节点解析后的输入参数(跳过时忽略)
+type DebugCommand struct {
+	Action string                 `json:"action"`
+	Inputs map[string]interface{} `json:"inputs,omitempty"`
+}
+
+// DebugPause 暂停时的节点与上下文
+type DebugPause struct {
+	NodeKey  string `json:"nodeKey"`
+	NodeName string `json:"nodeName"`
+	NodeType string `json:"nodeType"`
+	// Breakpoint 因断点暂停, 为 false 时因单步暂停
+	Breakpoint bool `json:"breakpoint"`
+	// Inputs 解析表达式后的输入参数
+	Inputs map[string]interface{} `json:"inputs"`
+	Since  time.Time              `json:"since"`
+}
+
+// DebugState 调试会话的当前状态
+type DebugState struct {
+	ID          string               `json:"id"`
+	Status      DebugStatus          `json:"status"`
+	Breakpoints []string             `json:"breakpoints"`
+	Paused      *DebugPause          `json:"paused,omitempty"`
+	Results     []core.ExecuteResult `json:"results"`
+	Error       string               `json:"error,omitempty"`
+}
+
+type debugSessionKey struct{}
+
+func debugSessionFrom(ctx context.Context) *DebugSession {
+	session, _ := ctx.Value(debugSessionKey{}).(*DebugSession)
+	return session
+}
+
+// DebugSession 调试会话: 执行工作流时在断点处暂停, 等待继续、单步或跳过命令
+type DebugSession struct {
+	ID      string
+	Timeout time.Duration
+
+	mu          sync.Mutex
+	status      DebugStatus
+	breakpoints map[string]bool
+	stepping    bool
+	paused      *DebugPause
+	results     []core.ExecuteResult
+	err         string
+	changed     chan struct{}
+
+	commands chan DebugCommand
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewDebugSession 创建调试会话, timeout 为暂停后等待命令的时长, 为 0 时使用 DefaultDebugTimeout
+func NewDebugSession(breakpoints []string, timeout time.Duration) *DebugSession {
+	if timeout <= 0 {
+		timeout = DefaultDebugTimeout
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	session := &DebugSession{
+		ID:       hex.EncodeToString(id),
+		Timeout:  timeout,
+		status:   DebugRunning,
+		changed:  make(chan struct{}),
+		commands: make(chan DebugCommand, 1),
+		done:     make(chan struct{}),
+	}
+	session.SetBreakpoints(breakpoints)
+	return session
+}
+
+// Run 在后台执行工作流, 执行到断点时暂停
+func (s *DebugSession) Run(ctx context.Context, runner *Runner, nodes []engine_nodes.Node, edges []Edge, inputs map[string]interface{}) {
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, debugSessionKey{}, s))
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	go func() {
+		defer cancel()
+		// 节点执行时 panic 也要结束会话, 否则等待调试命令的调用方会一直阻塞
+		defer func() {
+			if r := recover(); r != nil {
+				s.finish(nil, fmt.Errorf("调试执行异常: %v", r))
+			}
+		}()
+		results, err := runner.RunContext(ctx, nodes, edges, inputs)
+		s.finish(results, err)
+	}()
+}
+
+// Done 会话结束(完成或终止)时关闭
+func (s *DebugSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// SetBreakpoints 设置断点(节点键)
+func (s *DebugSession) SetBreakpoints(keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakpoints = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		s.breakpoints[key] = true
+	}
+}
+
+// State 当前状态
+func (s *DebugSession) State() DebugState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state()
+}
+
+func (s *DebugSession) state() DebugState {
+	breakpoints := make([]string, 0, len(s.breakpoints))
+	for key := range s.breakpoints {
+		breakpoints = append(breakpoints, key)
+	}
+	sort.Strings(breakpoints)
+	return DebugState{
+		ID:          s.ID,
+		Status:      s.status,
+		Breakpoints: breakpoints,
+		Paused:      s.paused,
+		Results:     append([]core.ExecuteResult{}, s.results...),
+		Error:       s.err,
+	}
+}
+
+// Wait 等待会话暂停或结束, ctx 结束时返回当前状态
+func (s *DebugSession) Wait(ctx context.Context) DebugState {
+	for {
+		s.mu.Lock()
+		if s.status != DebugRunning {
+			state := s.state()
+			s.mu.Unlock()
+			return state
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return s.State()
+		}
+	}
+}
+
+// Command 向暂停的会话发送调试命令
+func (s *DebugSession) Command(command DebugCommand) error {
+	switch command.Action {
+	case DebugContinue, DebugStep, DebugSkip:
+	default:
+		return fmt.Errorf("未知的调试命令: %s", command.Action)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != DebugPaused {
+		return ErrDebugNotPaused
+	}
+	s.status = DebugRunning
+	s.paused = nil
+	s.stepping = command.Action != DebugContinue
+	s.notify()
+	s.commands <- command
+	return nil
+}
+
+// Abort 终止会话, 正在执行的节点的请求会被取消
+func (s *DebugSession) Abort() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// pause 节点执行前调用, 单步或命中断点时暂停并等待调试命令
+func (s *DebugSession) pause(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) (DebugCommand, error) {
+	if ctx.Err() != nil {
+		return DebugCommand{}, ErrDebugAborted
+	}
+	s.mu.Lock()
+	breakpoint := s.breakpoints[node.NodeKey]
+	if !breakpoint && !s.stepping {
+		s.results = append([]core.ExecuteResult{}, results...)
+		s.mu.Unlock()
+		return DebugCommand{Action: DebugContinue}, nil
+	}
+	snapshot := make(map[string]interface{}, len(inputs))
+	for key, value := range inputs {
+		snapshot[key] = value
+	}
+	s.status = DebugPaused
+	s.paused = &DebugPause{
+		NodeKey:    node.NodeKey,
+		NodeName:   node.Name,
+		NodeType:   node.NodeType,
+		Breakpoint: breakpoint,
+		Inputs:     snapshot,
+		Since:      time.Now(),
+	}
+	s.results = append([]core.ExecuteResult{}, results...)
+	s.notify()
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.Timeout)
+	defer timer.Stop()
+	select {
+	case command := <-s.commands:
+		return command, nil
+	case <-timer.C:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// 超时的同时收到了命令
+		if s.status != DebugPaused {
+			return <-s.commands, nil
+		}
+		return DebugCommand{}, ErrDebugTimeout
+	case <-ctx.Done():
+		return DebugCommand{}, ErrDebugAborted
+	}
+}
+
+func (s *DebugSession) finish(results []core.ExecuteResult, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = nil
+	if results != nil {
+		s.results = results
+	}
+	if err != nil {
+		s.status = DebugAborted
+		s.err = err.Error()
+	} else {
+		s.status = DebugCompleted
+	}
+	s.notify()
+	close(s.done)
+}
+
+// notify 通知等待状态变化的调用方, 调用时需持有锁
+func (s *DebugSession) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,90 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"api-flow/engine/core"
+)
+
+// Format 定义文件格式
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat 解析格式名称, 为空时默认为 yaml
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("不支持的定义文件格式: %s", name)
+	}
+}
+
+// FormatFromPath 根据文件扩展名推断格式
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ContentType 格式对应的 HTTP Content-Type
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "application/x-yaml; charset=utf-8"
+}
+
+// Marshal 序列化工作流定义
+func Marshal(def *WorkflowDefinition, format Format) ([]byte, error) {
+	if format == FormatJSON {
+		return json.MarshalIndent(def, "", "  ")
+	}
+	return yaml.Marshal(def)
+}
+
+// Unmarshal 解析工作流定义
+//
+// YAML 会先转换为 JSON 再解析, 保证两种格式得到的配置值类型一致(数字为 float64, 对象为 map[string]interface{})
+func Unmarshal(data []byte, format Format) (*WorkflowDefinition, error) {
+	if format == FormatYAML {
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("解析YAML失败: %v", err)
+		}
+		jsonData, err := json.Marshal(core.NormalizeYAML(raw))
+		if err != nil {
+			return nil, fmt.Errorf("解析YAML失败: %v", err)
+		}
+		data = jsonData
+	}
+
+	def := &WorkflowDefinition{}
+	if err := json.Unmarshal(data, def); err != nil {
+		return nil, fmt.Errorf("解析定义文件失败: %v", err)
+	}
+	return def, nil
+}
+
+// LoadFile 从磁盘读取工作流定义, 格式由扩展名决定
+func LoadFile(path string) (*WorkflowDefinition, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data, format)
+}
@@ -0,0 +1,144 @@
+// Package definition 工作流定义文件(YAML/JSON)
+//
+// 定义文件用于把工作流保存到git、在不同环境之间迁移，以及脱离服务端执行。
+// 文件中不包含任何服务端生成的ID与时间戳，节点之间只通过 key 关联。
+package definition
+
+import (
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/models"
+)
+
+// Version 当前定义文件格式版本
+const Version = 1
+
+// WorkflowDefinition 工作流定义
+type WorkflowDefinition struct {
+	// Version 格式版本, 目前只支持 1
+	Version     int    `json:"version" yaml:"version"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Inputs 流程入参定义, 来源于 execInput 节点, 仅用于说明, 导入时忽略
+	Inputs core.ParamFormat `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	// Outputs 流程出参定义, 来源于末端节点的输出格式, 仅用于说明, 导入时忽略
+	Outputs core.ParamFormat `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Nodes   []NodeDefinition `json:"nodes" yaml:"nodes"`
+	Edges   []EdgeDefinition `json:"edges,omitempty" yaml:"edges,omitempty"`
+}
+
+// NodeDefinition 节点定义
+type NodeDefinition struct {
+	Key         string                 `json:"key" yaml:"key"`
+	Type        string                 `json:"type" yaml:"type"`
+	Name        string                 `json:"name" yaml:"name"`
+	Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Status      string                 `json:"status,omitempty" yaml:"status,omitempty"`
+	Config      map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	// UI 编辑器布局信息(坐标、颜色等)
+	UI map[string]interface{} `json:"ui,omitempty" yaml:"ui,omitempty"`
+}
+
+// EdgeDefinition 连线定义
+type EdgeDefinition struct {
+	Source string                 `json:"source" yaml:"source"`
+	Target string                 `json:"target" yaml:"target"`
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// FromWorkflow 根据数据库中的工作流构建定义
+func FromWorkflow(workflow *engine.Workflow, nodes []engine_nodes.Node, edges []engine.Edge) *WorkflowDefinition {
+	def := &WorkflowDefinition{
+		Version:     Version,
+		Name:        workflow.Name,
+		Description: workflow.Description,
+		Inputs:      WorkflowInputs(nodes),
+		Outputs:     WorkflowOutputs(nodes, edges),
+		Nodes:       make([]NodeDefinition, 0, len(nodes)),
+		Edges:       make([]EdgeDefinition, 0, len(edges)),
+	}
+	for _, node := range nodes {
+		def.Nodes = append(def.Nodes, NodeDefinition{
+			Key:         node.NodeKey,
+			Type:        node.NodeType,
+			Name:        node.Name,
+			Description: node.Description,
+			Status:      node.Status,
+			Config:      node.Config,
+			UI:          node.Ui,
+		})
+	}
+	for _, edge := range edges {
+		def.Edges = append(def.Edges, EdgeDefinition{
+			Source: edge.SourceNodeKey,
+			Target: edge.TargetNodeKey,
+			Config: edge.Config,
+		})
+	}
+	return def
+}
+
+// ToModels 将定义转换为节点与连线模型, 节点ID与节点key保持一致(与编辑器的保存逻辑相同)
+func (d *WorkflowDefinition) ToModels() ([]engine_nodes.Node, []engine.Edge) {
+	nodes := make([]engine_nodes.Node, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		node := engine_nodes.Node{
+			NodeKey:     n.Key,
+			NodeType:    n.Type,
+			Name:        n.Name,
+			Description: n.Description,
+			Status:      n.Status,
+			Config:      core.ItemConfig(n.Config),
+			Ui:          models.Record(n.UI),
+		}
+		node.ID = n.Key
+		if node.Status == "" {
+			node.Status = "active"
+		}
+		nodes = append(nodes, node)
+	}
+	edges := make([]engine.Edge, 0, len(d.Edges))
+	for _, e := range d.Edges {
+		edges = append(edges, engine.Edge{
+			SourceNodeKey: e.Source,
+			TargetNodeKey: e.Target,
+			Config:        core.ItemConfig(e.Config),
+		})
+	}
+	return nodes, edges
+}
+
+// WorkflowInputs 流程入参定义, 取自 execInput 节点的 config.params
+func WorkflowInputs(nodes []engine_nodes.Node) core.ParamFormat {
+	inputs := core.ParamFormat{}
+	for i := range nodes {
+		if nodes[i].NodeType == engine_nodes.InputNodeType.Code {
+			inputs = append(inputs, engine_nodes.InputNodeParams(&nodes[i])...)
+		}
+	}
+	return inputs
+}
+
+// WorkflowOutputs 流程出参定义, 取自没有后续节点的末端节点的输出格式, 字段名为 nodeKey.field
+func WorkflowOutputs(nodes []engine_nodes.Node, edges []engine.Edge) core.ParamFormat {
+	hasNext := make(map[string]bool)
+	for _, edge := range edges {
+		hasNext[edge.SourceNodeKey] = true
+	}
+	outputs := core.ParamFormat{}
+	for _, node := range nodes {
+		if hasNext[node.NodeKey] || node.NodeType == engine_nodes.InputNodeType.Code {
+			continue
+		}
+		for _, param := range engine_nodes.NodeOutputFormat(&node) {
+			if param == nil || param.Field == "" {
+				continue
+			}
+			output := *param
+			output.Field = node.NodeKey + "." + param.Field
+			outputs = append(outputs, &output)
+		}
+	}
+	return outputs
+}
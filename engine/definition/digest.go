@@ -0,0 +1,38 @@
+package definition
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// Digest 定义中影响执行的部分(节点的 key、类型与配置, 连线)的摘要, 用作工作流版本;
+// 名称、描述与编辑器布局变化时摘要不变, 节点与连线的顺序不影响摘要
+func (d *WorkflowDefinition) Digest() string {
+	type digestNode struct {
+		Key    string                 `json:"key"`
+		Type   string                 `json:"type"`
+		Config map[string]interface{} `json:"config,omitempty"`
+	}
+	nodes := make([]digestNode, 0, len(d.Nodes))
+	for _, node := range d.Nodes {
+		nodes = append(nodes, digestNode{Key: node.Key, Type: node.Type, Config: node.Config})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Key < nodes[j].Key })
+	edges := append([]EdgeDefinition{}, d.Edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+
+	// map 按 key 排序序列化, 结果是确定的
+	data, _ := json.Marshal(struct {
+		Nodes []digestNode     `json:"nodes"`
+		Edges []EdgeDefinition `json:"edges"`
+	}{nodes, edges})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
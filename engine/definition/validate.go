@@ -0,0 +1,137 @@
+package definition
+
+import (
+	"fmt"
+	"strings"
+
+	"api-flow/engine"
+	"api-flow/engine/engine_nodes"
+)
+
+// Problem 定义文件校验问题
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// Validate 校验定义文件, nodeTypeExists 用于判断节点类型是否存在
+func (d *WorkflowDefinition) Validate(nodeTypeExists func(code string) bool) []Problem {
+	problems := make([]Problem, 0)
+	add := func(path, format string, args ...interface{}) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if d.Version != Version {
+		add("version", "不支持的格式版本 %d, 当前版本为 %d", d.Version, Version)
+	}
+	if strings.TrimSpace(d.Name) == "" {
+		add("name", "工作流名称不能为空")
+	}
+	if len(d.Nodes) == 0 {
+		add("nodes", "工作流没有节点")
+	}
+
+	keys := make(map[string]bool)
+	for i, node := range d.Nodes {
+		path := fmt.Sprintf("nodes[%d]", i)
+		if node.Key == "" {
+			add(path+".key", "节点键不能为空")
+		} else if keys[node.Key] {
+			add(path+".key", "节点键 %s 重复", node.Key)
+		}
+		keys[node.Key] = true
+
+		if node.Type == "" {
+			add(path+".type", "节点类型不能为空")
+		} else if nodeTypeExists != nil && !nodeTypeExists(node.Type) {
+			add(path+".type", "节点类型 %s 不存在", node.Type)
+		}
+		if node.Name == "" {
+			add(path+".name", "节点名称不能为空")
+		}
+	}
+
+	for i, edge := range d.Edges {
+		path := fmt.Sprintf("edges[%d]", i)
+		if edge.Source == "" || edge.Target == "" {
+			add(path, "连线的源节点和目标节点不能为空")
+			continue
+		}
+		if !keys[edge.Source] {
+			add(path+".source", "源节点 %s 不存在", edge.Source)
+		}
+		if !keys[edge.Target] {
+			add(path+".target", "目标节点 %s 不存在", edge.Target)
+		}
+	}
+
+	_, edges := d.ToModels()
+	if err := engine.CheckCircularDependency(edges); err != nil {
+		add("edges", err.Error())
+	}
+
+	return problems
+}
+
+// RemapKeys 为冲突的节点键生成新键, 并同步更新连线与配置中的 ${key.xxx} 表达式
+// 返回旧键到新键的映射
+func (d *WorkflowDefinition) RemapKeys(conflict func(key string) bool) map[string]string {
+	mapping := make(map[string]string)
+	for i := range d.Nodes {
+		oldKey := d.Nodes[i].Key
+		if oldKey == "" || !conflict(oldKey) {
+			continue
+		}
+		newKey := engine_nodes.GenerateNodeKey()
+		for conflict(newKey) {
+			newKey = engine_nodes.GenerateNodeKey()
+		}
+		mapping[oldKey] = newKey
+		d.Nodes[i].Key = newKey
+	}
+	if len(mapping) == 0 {
+		return mapping
+	}
+
+	for i := range d.Edges {
+		if newKey, ok := mapping[d.Edges[i].Source]; ok {
+			d.Edges[i].Source = newKey
+		}
+		if newKey, ok := mapping[d.Edges[i].Target]; ok {
+			d.Edges[i].Target = newKey
+		}
+	}
+	for i := range d.Nodes {
+		if d.Nodes[i].Config != nil {
+			d.Nodes[i].Config = replaceExpressionKeys(d.Nodes[i].Config, mapping).(map[string]interface{})
+		}
+	}
+	return mapping
+}
+
+// replaceExpressionKeys 替换配置中表达式引用的节点键
+func replaceExpressionKeys(value interface{}, mapping map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for oldKey, newKey := range mapping {
+			v = strings.Replace(v, "${"+oldKey+".", "${"+newKey+".", -1)
+		}
+		return v
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = replaceExpressionKeys(val, mapping)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceExpressionKeys(v[i], mapping)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -2,6 +2,10 @@ package engine
 
 import (
 	"api-flow/engine/core"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -19,7 +23,60 @@ func (Edge) TableName() string {
 	return "edges"
 }
 
+// GenerateEdgeID 生成连线ID
+func GenerateEdgeID() string {
+	return fmt.Sprintf("edge-%d-%d", time.Now().UnixNano(), rand.Intn(10000))
+}
+
+// NewEdge 创建连接两个节点的连线(未保存)
+func NewEdge(sourceNodeKey, targetNodeKey string) Edge {
+	edge := Edge{
+		SourceNodeKey: sourceNodeKey,
+		TargetNodeKey: targetNodeKey,
+		Config:        core.ItemConfig{},
+	}
+	edge.ID = GenerateEdgeID()
+	return edge
+}
+
 // MigrateEdge 创建连线表
 func MigrateEdge(db *gorm.DB) error {
 	return db.AutoMigrate(&Edge{}).Error
 }
+
+// CheckCircularDependency 检查连线是否构成循环依赖
+func CheckCircularDependency(edges []Edge) error {
+	// 构建邻接表
+	graph := make(map[string][]string)
+	for _, edge := range edges {
+		graph[edge.SourceNodeKey] = append(graph[edge.SourceNodeKey], edge.TargetNodeKey)
+	}
+	// 深度优先搜索
+	visited := make(map[string]bool)
+	recStack := make(map[string]bool)
+	var dfs func(node string) bool
+	dfs = func(node string) bool {
+		if !visited[node] {
+			visited[node] = true
+			recStack[node] = true
+
+			for _, neighbor := range graph[node] {
+				if !visited[neighbor] && dfs(neighbor) {
+					return true
+				} else if recStack[neighbor] {
+					return true
+				}
+			}
+		}
+		recStack[node] = false
+		return false
+	}
+	for node := range graph {
+		if !visited[node] {
+			if dfs(node) {
+				return errors.New("工作流存在循环依赖")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package engine_nodes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"api-flow/engine/jsonpath"
+	"api-flow/engine/jsonschema"
+)
+
+// apiAssertions API 节点的 assertions 配置
+type apiAssertions struct {
+	// Status 允许的状态码: 200、"2xx"、"200-299", 未配置时要求 2xx
+	Status []interface{} `json:"status"`
+	// Headers 响应头断言, 响应头名称不区分大小写
+	Headers []headerAssertion `json:"headers"`
+	// Body 响应体 JSONPath 断言
+	Body []bodyAssertion `json:"body"`
+	// MaxLatency 最大总耗时(毫秒)
+	MaxLatency float64 `json:"maxLatency"`
+	// Schema 响应体需满足的 JSON Schema
+	Schema interface{} `json:"schema"`
+
+	statusRanges [][2]int
+}
+
+// ValueMatcher 取值的匹配条件, 可以同时配置多个
+type ValueMatcher struct {
+	Equals   json.RawMessage `json:"equals"`
+	Contains *string         `json:"contains"`
+	Regex    string          `json:"regex"`
+	Exists   *bool           `json:"exists"`
+
+	regex *regexp.Regexp
+}
+
+type headerAssertion struct {
+	Name string `json:"name"`
+	ValueMatcher
+}
+
+type bodyAssertion struct {
+	Path string `json:"path"`
+	ValueMatcher
+
+	path *jsonpath.Path
+}
+
+// AssertionResult 单条断言的结果
+type AssertionResult struct {
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+var statusPattern = regexp.MustCompile(`^([1-5])xx$`)
+
+// parseAPIAssertions 解析节点配置中的 assertions, 未配置时返回 nil
+func parseAPIAssertions(config map[string]interface{}) (*apiAssertions, error) {
+	raw, ok := config["assertions"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("assertions配置无效: %v", err)
+	}
+	var assertions apiAssertions
+	if err := json.Unmarshal(data, &assertions); err != nil {
+		return nil, fmt.Errorf("assertions配置无效: %v", err)
+	}
+
+	if len(assertions.Status) == 0 {
+		assertions.Status = []interface{}{"2xx"}
+	}
+	for _, status := range assertions.Status {
+		r, err := parseStatusRange(status)
+		if err != nil {
+			return nil, err
+		}
+		assertions.statusRanges = append(assertions.statusRanges, r)
+	}
+	for i := range assertions.Headers {
+		if assertions.Headers[i].Name == "" {
+			return nil, fmt.Errorf("assertions.headers[%d].name 不能为空", i)
+		}
+		if err := assertions.Headers[i].Compile(); err != nil {
+			return nil, fmt.Errorf("assertions.headers[%d]: %v", i, err)
+		}
+	}
+	for i := range assertions.Body {
+		if assertions.Body[i].path, err = jsonpath.Compile(assertions.Body[i].Path); err != nil {
+			return nil, fmt.Errorf("assertions.body[%d]: %v", i, err)
+		}
+		if err := assertions.Body[i].Compile(); err != nil {
+			return nil, fmt.Errorf("assertions.body[%d]: %v", i, err)
+		}
+	}
+	if assertions.Schema != nil {
+		if _, ok := assertions.Schema.(map[string]interface{}); !ok {
+			if _, ok := assertions.Schema.(bool); !ok {
+				return nil, errors.New("assertions.schema 必须是JSON对象")
+			}
+		}
+	}
+	return &assertions, nil
+}
+
+// parseStatusRange 解析状态码范围: 200、"200"、"2xx"、"200-299"
+func parseStatusRange(value interface{}) ([2]int, error) {
+	text := strings.TrimSpace(fmt.Sprint(value))
+	if m := statusPattern.FindStringSubmatch(strings.ToLower(text)); m != nil {
+		base, _ := strconv.Atoi(m[1])
+		return [2]int{base * 100, base*100 + 99}, nil
+	}
+	parts := strings.SplitN(text, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("无效的状态码断言: %s", text)
+	}
+	to := from
+	if len(parts) == 2 {
+		if to, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil || to < from {
+			return [2]int{}, fmt.Errorf("无效的状态码断言: %s", text)
+		}
+	}
+	return [2]int{from, to}, nil
+}
+
+// Compile 编译正则表达式, 使用 Match 前必须调用
+func (m *ValueMatcher) Compile() error {
+	if m.Regex != "" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return fmt.Errorf("无效的正则表达式 %s: %v", m.Regex, err)
+		}
+		m.regex = re
+	}
+	if m.Equals == nil && m.Contains == nil && m.regex == nil && m.Exists == nil {
+		// 没有任何条件时默认要求存在
+		exists := true
+		m.Exists = &exists
+	}
+	return nil
+}
+
+// Match 检查取值是否满足条件, 不满足时返回原因
+func (m *ValueMatcher) Match(value interface{}, found bool) string {
+	if m.Exists != nil {
+		if *m.Exists && !found {
+			return "不存在"
+		}
+		if !*m.Exists {
+			if found {
+				return fmt.Sprintf("应不存在, 实际为 %s", displayValue(value))
+			}
+			return ""
+		}
+	}
+	if !found {
+		return "不存在"
+	}
+	if m.Equals != nil {
+		var expected interface{}
+		if err := json.Unmarshal(m.Equals, &expected); err == nil && !reflect.DeepEqual(normalizeJSON(value), expected) {
+			return fmt.Sprintf("应等于 %s, 实际为 %s", string(m.Equals), displayValue(value))
+		}
+	}
+	text := stringify(value)
+	if m.Contains != nil && !strings.Contains(text, *m.Contains) {
+		return fmt.Sprintf("应包含 %q, 实际为 %s", *m.Contains, displayValue(value))
+	}
+	if m.regex != nil && !m.regex.MatchString(text) {
+		return fmt.Sprintf("应匹配 %s, 实际为 %s", m.Regex, displayValue(value))
+	}
+	return ""
+}
+
+// evaluate 执行全部断言
+func (a *apiAssertions) evaluate(result *apiResponse) []AssertionResult {
+	results := make([]AssertionResult, 0)
+	add := func(assertionType, target, message string) {
+		results = append(results, AssertionResult{Type: assertionType, Target: target, Passed: message == "", Message: message})
+	}
+
+	code := result.resp.StatusCode
+	message := fmt.Sprintf("状态码 %d 不在允许范围 %s 中", code, a.statusText())
+	for _, r := range a.statusRanges {
+		if code >= r[0] && code <= r[1] {
+			message = ""
+			break
+		}
+	}
+	add("status", a.statusText(), message)
+
+	for _, header := range a.Headers {
+		values := result.resp.Header.Values(header.Name)
+		found := len(values) > 0
+		message := header.Match(strings.Join(values, ", "), found)
+		if message != "" {
+			message = fmt.Sprintf("响应头 %s %s", header.Name, message)
+		}
+		add("header", header.Name, message)
+	}
+
+	if len(a.Body) > 0 || a.Schema != nil {
+		data := result.Data()
+		for _, body := range a.Body {
+			value, found := body.path.Lookup(data)
+			message := body.Match(value, found)
+			if message != "" {
+				message = fmt.Sprintf("%s %s", body.Path, message)
+			}
+			add("body", body.Path, message)
+		}
+		if a.Schema != nil {
+			errs := jsonschema.Validate(a.Schema, data)
+			messages := make([]string, 0, len(errs))
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			message := ""
+			if len(messages) > 0 {
+				message = "响应体不符合 JSON Schema: " + strings.Join(messages, "; ")
+			}
+			add("schema", "$", message)
+		}
+	}
+
+	if a.MaxLatency > 0 {
+		total := durationMillis(result.timing.total)
+		message := ""
+		if total > a.MaxLatency {
+			message = fmt.Sprintf("耗时 %.1fms 超过 %vms", total, a.MaxLatency)
+		}
+		add("latency", "total", message)
+	}
+	return results
+}
+
+func (a *apiAssertions) statusText() string {
+	parts := make([]string, 0, len(a.Status))
+	for _, status := range a.Status {
+		parts = append(parts, fmt.Sprint(status))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// assertionFailures 汇总未通过的断言, 每条一行
+func assertionFailures(results []AssertionResult) string {
+	lines := make([]string, 0)
+	for _, result := range results {
+		if !result.Passed {
+			lines = append(lines, fmt.Sprintf("%d. %s", len(lines)+1, result.Message))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("断言失败(%d项):\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+func displayValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(data) > 200 {
+		return string(data[:200]) + "..."
+	}
+	return string(data)
+}
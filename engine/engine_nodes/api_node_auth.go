@@ -0,0 +1,411 @@
+package engine_nodes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-flow/secrets"
+)
+
+// API 节点支持的认证方式
+const (
+	AuthTypeBasic  = "basic"
+	AuthTypeBearer = "bearer"
+	AuthTypeAPIKey = "apiKey"
+	AuthTypeOAuth2 = "oauth2"
+)
+
+// OAuth2 授权方式
+const (
+	OAuth2ClientCredentials = "client_credentials"
+	OAuth2RefreshToken      = "refresh_token"
+)
+
+// 令牌提前刷新的时间, 避免请求途中过期
+const oauth2ExpiryDelta = 30 * time.Second
+
+// secretValue 认证配置中的取值, 可以是普通字符串, 也可以是 {"secret": "名称"} 形式的密钥引用
+type secretValue struct {
+	Value  string
+	Secret string
+}
+
+func (v *secretValue) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		v.Value = str
+		return nil
+	}
+	var ref struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return errors.New(`必须是字符串或 {"secret": "名称"}`)
+	}
+	v.Secret = ref.Secret
+	return nil
+}
+
+func (v secretValue) empty() bool {
+	return v.Value == "" && v.Secret == ""
+}
+
+// resolve 获取实际值, 密钥引用从密钥管理中读取
+func (v secretValue) resolve() (string, error) {
+	if v.Secret != "" {
+		return secrets.Lookup(v.Secret)
+	}
+	return v.Value, nil
+}
+
+// apiAuth API 节点的 auth 配置
+type apiAuth struct {
+	Type string `json:"type"`
+
+	// basic
+	Username secretValue `json:"username"`
+	Password secretValue `json:"password"`
+
+	// bearer
+	Token secretValue `json:"token"`
+
+	// apiKey
+	Name  string      `json:"name"`
+	In    string      `json:"in"`
+	Value secretValue `json:"value"`
+
+	// oauth2
+	GrantType    string      `json:"grantType"`
+	TokenURL     string      `json:"tokenUrl"`
+	ClientID     secretValue `json:"clientId"`
+	ClientSecret secretValue `json:"clientSecret"`
+	RefreshToken secretValue `json:"refreshToken"`
+	Scopes       []string    `json:"scopes"`
+	// ClientAuth 客户端凭证的传递方式: basic(默认, 使用 Authorization 头) 或 body(放在表单中)
+	ClientAuth string `json:"clientAuth"`
+}
+
+// parseAPIAuth 解析节点配置中的 auth, 未配置时返回 nil
+func parseAPIAuth(config map[string]interface{}) (*apiAuth, error) {
+	raw, ok := config["auth"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("auth配置无效: %v", err)
+	}
+	var auth apiAuth
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return nil, fmt.Errorf("auth配置无效: %v", err)
+	}
+	if err := auth.validate(); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// validate 校验认证配置, 凭证类字段只允许引用密钥, 不允许明文填写
+func (a *apiAuth) validate() error {
+	requireSecret := func(field string, value secretValue) error {
+		if value.Secret != "" {
+			return nil
+		}
+		if value.Value != "" {
+			return fmt.Errorf(`auth.%s 不允许明文填写, 请使用 {"secret": "名称"} 引用密钥`, field)
+		}
+		return fmt.Errorf("auth.%s 不能为空", field)
+	}
+
+	switch a.Type {
+	case AuthTypeBasic:
+		if a.Username.empty() {
+			return errors.New("auth.username 不能为空")
+		}
+		return requireSecret("password", a.Password)
+	case AuthTypeBearer:
+		return requireSecret("token", a.Token)
+	case AuthTypeAPIKey:
+		if a.Name == "" {
+			return errors.New("auth.name 不能为空")
+		}
+		if a.In != "" && a.In != "header" && a.In != "query" {
+			return errors.New("auth.in 必须是 header 或 query")
+		}
+		return requireSecret("value", a.Value)
+	case AuthTypeOAuth2:
+		if a.TokenURL == "" {
+			return errors.New("auth.tokenUrl 不能为空")
+		}
+		if a.ClientAuth != "" && a.ClientAuth != "basic" && a.ClientAuth != "body" {
+			return errors.New("auth.clientAuth 必须是 basic 或 body")
+		}
+		switch a.GrantType {
+		case OAuth2ClientCredentials:
+			if a.ClientID.empty() {
+				return errors.New("auth.clientId 不能为空")
+			}
+			return requireSecret("clientSecret", a.ClientSecret)
+		case OAuth2RefreshToken:
+			if !a.ClientSecret.empty() {
+				if err := requireSecret("clientSecret", a.ClientSecret); err != nil {
+					return err
+				}
+			}
+			return requireSecret("refreshToken", a.RefreshToken)
+		default:
+			return fmt.Errorf("不支持的OAuth2授权方式: %s", a.GrantType)
+		}
+	default:
+		return fmt.Errorf("不支持的认证方式: %s", a.Type)
+	}
+}
+
+// apply 为请求添加认证信息
+func (a *apiAuth) apply(client *http.Client, req *http.Request) error {
+	switch a.Type {
+	case AuthTypeBasic:
+		username, err := a.Username.resolve()
+		if err != nil {
+			return err
+		}
+		password, err := a.Password.resolve()
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(username, password)
+	case AuthTypeBearer:
+		token, err := a.Token.resolve()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case AuthTypeAPIKey:
+		value, err := a.Value.resolve()
+		if err != nil {
+			return err
+		}
+		if a.In == "query" {
+			query := req.URL.Query()
+			query.Set(a.Name, value)
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set(a.Name, value)
+		}
+	case AuthTypeOAuth2:
+		token, err := defaultOAuth2Cache.token(req.Context(), client, a)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// invalidate 使缓存的令牌失效, 用于服务端返回 401 时重新获取
+func (a *apiAuth) invalidate() bool {
+	if a.Type != AuthTypeOAuth2 {
+		return false
+	}
+	return defaultOAuth2Cache.invalidate(a)
+}
+
+// oauth2Token 缓存的访问令牌
+type oauth2Token struct {
+	accessToken string
+	expiry      time.Time
+}
+
+func (t *oauth2Token) valid() bool {
+	return t != nil && t.accessToken != "" && (t.expiry.IsZero() || time.Now().Add(oauth2ExpiryDelta).Before(t.expiry))
+}
+
+type oauth2Entry struct {
+	mu    sync.Mutex
+	token *oauth2Token
+	// 服务端轮换后的刷新令牌, 为空时使用配置中的刷新令牌
+	refreshToken string
+}
+
+// oauth2TokenCache 进程内共享的令牌缓存, 同一配置的多个节点、多次执行复用同一个令牌
+type oauth2TokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*oauth2Entry
+}
+
+var defaultOAuth2Cache = &oauth2TokenCache{entries: make(map[string]*oauth2Entry)}
+
+// oauth2Credentials 解析后的 OAuth2 凭证
+type oauth2Credentials struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+}
+
+func (a *apiAuth) oauth2Credentials() (*oauth2Credentials, error) {
+	var creds oauth2Credentials
+	var err error
+	if creds.clientID, err = a.ClientID.resolve(); err != nil {
+		return nil, err
+	}
+	if creds.clientSecret, err = a.ClientSecret.resolve(); err != nil {
+		return nil, err
+	}
+	if a.GrantType == OAuth2RefreshToken {
+		if creds.refreshToken, err = a.RefreshToken.resolve(); err != nil {
+			return nil, err
+		}
+	}
+	return &creds, nil
+}
+
+// cacheKey 缓存键包含凭证摘要, 密钥变更后会重新获取令牌
+func (a *apiAuth) cacheKey(creds *oauth2Credentials) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		a.GrantType, a.TokenURL, creds.clientID, creds.clientSecret, creds.refreshToken, strings.Join(a.Scopes, " "),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *oauth2TokenCache) entry(key string) *oauth2Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &oauth2Entry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// token 获取有效的访问令牌, 缓存过期时重新获取
+func (c *oauth2TokenCache) token(ctx context.Context, client *http.Client, auth *apiAuth) (string, error) {
+	creds, err := auth.oauth2Credentials()
+	if err != nil {
+		return "", err
+	}
+	entry := c.entry(auth.cacheKey(creds))
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.token.valid() {
+		return entry.token.accessToken, nil
+	}
+
+	refreshToken := creds.refreshToken
+	if entry.refreshToken != "" {
+		refreshToken = entry.refreshToken
+	}
+	token, newRefreshToken, err := fetchOAuth2Token(ctx, client, auth, creds, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	entry.token = token
+	if newRefreshToken != "" {
+		entry.refreshToken = newRefreshToken
+	}
+	return token.accessToken, nil
+}
+
+func (c *oauth2TokenCache) invalidate(auth *apiAuth) bool {
+	creds, err := auth.oauth2Credentials()
+	if err != nil {
+		return false
+	}
+	entry := c.entry(auth.cacheKey(creds))
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.token = nil
+	return true
+}
+
+// fetchOAuth2Token 请求令牌端点, 返回访问令牌与服务端轮换的刷新令牌
+func fetchOAuth2Token(ctx context.Context, client *http.Client, auth *apiAuth, creds *oauth2Credentials, refreshToken string) (*oauth2Token, string, error) {
+	form := url.Values{}
+	form.Set("grant_type", auth.GrantType)
+	if auth.GrantType == OAuth2RefreshToken {
+		form.Set("refresh_token", refreshToken)
+	}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+	useBasic := auth.ClientAuth != "body" && creds.clientID != ""
+	if !useBasic && creds.clientID != "" {
+		form.Set("client_id", creds.clientID)
+		if creds.clientSecret != "" {
+			form.Set("client_secret", creds.clientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", fmt.Errorf("创建令牌请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(creds.clientID), url.QueryEscape(creds.clientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("获取OAuth2令牌失败: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", fmt.Errorf("读取OAuth2令牌响应失败: %v", err)
+	}
+
+	var result struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		RefreshToken     string      `json:"refresh_token"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		// 部分老旧服务以表单格式返回
+		values, formErr := url.ParseQuery(string(body))
+		if formErr != nil || values.Get("access_token") == "" && values.Get("error") == "" {
+			return nil, "", fmt.Errorf("获取OAuth2令牌失败: HTTP %d", resp.StatusCode)
+		}
+		result.AccessToken = values.Get("access_token")
+		result.ExpiresIn = json.Number(values.Get("expires_in"))
+		result.RefreshToken = values.Get("refresh_token")
+		result.Error = values.Get("error")
+		result.ErrorDescription = values.Get("error_description")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || result.Error != "" || result.AccessToken == "" {
+		message := result.Error
+		if result.ErrorDescription != "" {
+			message += ": " + result.ErrorDescription
+		}
+		if message == "" {
+			message = "响应中缺少 access_token"
+		}
+		return nil, "", fmt.Errorf("获取OAuth2令牌失败: HTTP %d, %s", resp.StatusCode, message)
+	}
+
+	token := &oauth2Token{accessToken: result.AccessToken}
+	if seconds, err := strconv.ParseInt(result.ExpiresIn.String(), 10, 64); err == nil && seconds > 0 {
+		token.expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, result.RefreshToken, nil
+}
@@ -0,0 +1,330 @@
+package engine_nodes
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// API 节点请求体类型
+const (
+	BodyTypeNone      = "none"
+	BodyTypeRaw       = "raw"
+	BodyTypeJSON      = "json"
+	BodyTypeForm      = "form"
+	BodyTypeMultipart = "multipart"
+)
+
+// BodyTypes API 节点支持的请求体类型
+var BodyTypes = []interface{}{BodyTypeNone, BodyTypeRaw, BodyTypeJSON, BodyTypeForm, BodyTypeMultipart}
+
+// requestBodyType 获取请求体类型, 未配置时按 body 推断: 字符串为 raw, 对象为 json
+func requestBodyType(config map[string]interface{}) string {
+	if bodyType, ok := config["bodyType"].(string); ok && bodyType != "" {
+		return bodyType
+	}
+	switch body := config["body"].(type) {
+	case nil:
+		return BodyTypeNone
+	case string:
+		if body == "" {
+			return BodyTypeNone
+		}
+		return BodyTypeRaw
+	default:
+		return BodyTypeJSON
+	}
+}
+
+func validateBodyType(bodyType string) error {
+	for _, t := range BodyTypes {
+		if t == bodyType {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的请求体类型: %s", bodyType)
+}
+
+// buildRequestBody 按请求体类型生成请求体, 同时返回自动选择的 Content-Type
+func buildRequestBody(config map[string]interface{}, inputs map[string]interface{}) ([]byte, string, error) {
+	body := config["body"]
+	switch requestBodyType(config) {
+	case BodyTypeNone:
+		return nil, "", nil
+	case BodyTypeRaw:
+		content, err := renderRawBody(body, inputs)
+		if err != nil || content == "" {
+			return nil, "", err
+		}
+		contentType := "text/plain; charset=utf-8"
+		if json.Valid([]byte(content)) {
+			contentType = "application/json"
+		}
+		return []byte(content), contentType, nil
+	case BodyTypeJSON:
+		if str, ok := body.(string); ok {
+			// 字符串按模板渲染后原样发送
+			content, err := renderTemplate(str, inputs)
+			if err != nil {
+				return nil, "", fmt.Errorf("渲染请求体模板失败: %v", err)
+			}
+			return []byte(content), "application/json", nil
+		}
+		value, err := renderValue(body, inputs)
+		if err != nil {
+			return nil, "", fmt.Errorf("渲染请求体模板失败: %v", err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, "", fmt.Errorf("序列化请求体失败: %v", err)
+		}
+		return data, "application/json", nil
+	case BodyTypeForm:
+		if str, ok := body.(string); ok && !isJSONObject(str) {
+			// 已编码的表单字符串
+			content, err := renderTemplate(str, inputs)
+			if err != nil {
+				return nil, "", fmt.Errorf("渲染请求体模板失败: %v", err)
+			}
+			return []byte(content), "application/x-www-form-urlencoded", nil
+		}
+		fields, err := bodyFields(body, inputs)
+		if err != nil {
+			return nil, "", err
+		}
+		values := url.Values{}
+		for _, name := range sortedKeys(fields) {
+			for _, value := range fieldValues(fields[name]) {
+				values.Add(name, stringify(value))
+			}
+		}
+		return []byte(values.Encode()), "application/x-www-form-urlencoded", nil
+	case BodyTypeMultipart:
+		fields, err := bodyFields(body, inputs)
+		if err != nil {
+			return nil, "", err
+		}
+		return buildMultipart(fields)
+	default:
+		return nil, "", fmt.Errorf("不支持的请求体类型: %v", config["bodyType"])
+	}
+}
+
+// renderRawBody 渲染原始请求体, 非字符串的 body 序列化为 JSON
+func renderRawBody(body interface{}, inputs map[string]interface{}) (string, error) {
+	if str, ok := body.(string); ok {
+		content, err := renderTemplate(str, inputs)
+		if err != nil {
+			return "", fmt.Errorf("渲染请求体模板失败: %v", err)
+		}
+		return content, nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// bodyFields 获取表单字段, body 可以是对象或对象的 JSON 字符串
+func bodyFields(body interface{}, inputs map[string]interface{}) (map[string]interface{}, error) {
+	if body == nil {
+		return map[string]interface{}{}, nil
+	}
+	if str, ok := body.(string); ok {
+		if strings.TrimSpace(str) == "" {
+			return map[string]interface{}{}, nil
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(str), &parsed); err != nil {
+			// 模板表达式不在 JSON 字符串内时(如 {"age": {{.age}}}), 先渲染再解析
+			rendered, renderErr := renderTemplate(str, inputs)
+			if renderErr != nil {
+				return nil, fmt.Errorf("渲染请求体模板失败: %v", renderErr)
+			}
+			if err := json.Unmarshal([]byte(rendered), &parsed); err != nil {
+				return nil, errors.New("表单请求体必须是JSON对象")
+			}
+			inputs = nil
+		}
+		body = parsed
+	}
+	if inputs != nil {
+		var err error
+		if body, err = renderValue(body, inputs); err != nil {
+			return nil, fmt.Errorf("渲染请求体模板失败: %v", err)
+		}
+	}
+	fields, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("表单请求体必须是JSON对象")
+	}
+	return fields, nil
+}
+
+// buildMultipart 生成 multipart/form-data 请求体
+//
+// 文件字段的值为对象: {"filename": "a.png", "content": "...", "encoding": "base64", "contentType": "image/png"},
+// encoding 为 base64 时 content 按 base64 解码, 否则按文本发送。
+func buildMultipart(fields map[string]interface{}) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range sortedKeys(fields) {
+		for _, value := range fieldValues(fields[name]) {
+			file, ok := value.(map[string]interface{})
+			if !ok || file["filename"] == nil {
+				if err := writer.WriteField(name, stringify(value)); err != nil {
+					return nil, "", err
+				}
+				continue
+			}
+			if err := writeFilePart(writer, name, file); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeFilePart(writer *multipart.Writer, name string, file map[string]interface{}) error {
+	filename := stringify(file["filename"])
+	content := []byte(stringify(file["content"]))
+	if encoding, _ := file["encoding"].(string); encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(string(content))
+		if err != nil {
+			return fmt.Errorf("文件字段 %s 的内容不是有效的base64: %v", name, err)
+		}
+		content = decoded
+	}
+	contentType, _ := file["contentType"].(string)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(content)
+	return err
+}
+
+// applyQueryParams 将 params 编码为查询参数追加到 URL, 与 URL 中已有的同名参数冲突时以 params 为准
+func applyQueryParams(rawURL string, params interface{}, inputs map[string]interface{}) (string, error) {
+	paramMap, ok := params.(map[string]interface{})
+	if !ok || len(paramMap) == 0 {
+		return rawURL, nil
+	}
+	rendered, err := renderValue(paramMap, inputs)
+	if err != nil {
+		return "", fmt.Errorf("渲染请求参数失败: %v", err)
+	}
+	paramMap = rendered.(map[string]interface{})
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无效的URL: %v", err)
+	}
+	query := u.Query()
+	for _, name := range sortedKeys(paramMap) {
+		values := fieldValues(paramMap[name])
+		if len(values) == 0 {
+			continue
+		}
+		query.Del(name)
+		for _, value := range values {
+			query.Add(name, stringify(value))
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// renderValue 递归渲染值中的字符串模板
+func renderValue(value interface{}, inputs map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return renderTemplate(v, inputs)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			rendered, err := renderValue(item, inputs)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = rendered
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, inputs)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rendered
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// fieldValues 数组展开为多个同名字段, nil 表示不发送该字段
+func fieldValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+// stringify 将字段值转换为字符串, 对象与数组序列化为 JSON
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isJSONObject(str string) bool {
+	return strings.HasPrefix(strings.TrimSpace(str), "{")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,29 +2,44 @@ package engine_nodes
 
 import (
 	"api-flow/engine/core"
+	"api-flow/engine/outbound"
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"regexp"
 	"strings"
 	"text/template"
-	"time"
 )
 
 var apiNodeOutputFormat = core.ParamFormat{
 	core.NewParamDefination("response", core.DataTypeObject, "API响应数据"),
+	core.NewParamDefination("status", core.DataTypeNumber, "HTTP状态码"),
+	core.NewParamDefination("statusText", core.DataTypeString, "HTTP状态描述"),
+	core.NewParamDefination("headers", core.DataTypeObject, "响应头, 多个值以逗号分隔"),
+	core.NewParamDefination("cookies", core.DataTypeObject, "响应设置的Cookie, 名称到值的映射"),
+	core.NewParamDefination("url", core.DataTypeString, "重定向后的最终URL"),
+	core.NewParamDefination("contentType", core.DataTypeString, "响应内容类型"),
+	core.NewParamDefination("size", core.DataTypeNumber, "响应体大小（字节）"),
+	core.NewParamDefination("timing", core.DataTypeObject, "请求耗时（毫秒）: dns、connect、tls、ttfb、total"),
+	core.NewParamDefination("assertions", core.DataTypeArray, "断言结果（配置了断言时输出）"),
+	core.NewParamDefination("items", core.DataTypeArray, "分页模式下合并的全部数据"),
+	core.NewParamDefination("pages", core.DataTypeNumber, "分页模式下请求的页数"),
 }
 var apiNodeInputFormat = core.ParamFormat{
 	core.NewParamString("url", "API 请求URL", ""),
 	core.NewParamOptions("method", "API 请求方法", "GET", []interface{}{"GET", "POST", "PUT", "DELETE", "PATCH"}),
 	core.NewParamObject("headers", "请求头", map[string]interface{}{}),
+	core.NewParamOptions("bodyType", "请求体类型", BodyTypeRaw, BodyTypes),
 	core.NewParamString("body", "请求体", ""),
-	core.NewParamObject("params", "请求参数", map[string]interface{}{}),
+	core.NewParamObject("params", "查询参数", map[string]interface{}{}),
 	core.NewParamNumber("timeout", "请求超时时间（秒）", 30),
 	core.NewParamNumber("retry", "重试次数", 0),
 	core.NewParamNumber("retryInterval", "重试间隔时间（秒）", 5),
+	core.NewParamObject("auth", "认证配置", map[string]interface{}{}),
+	core.NewParamObject("assertions", "响应断言", map[string]interface{}{}),
+	core.NewParamObject("pagination", "自动分页", map[string]interface{}{}),
 }
 var ApiNodeType = &NodeType{
 	Code:        "api",
@@ -43,9 +58,8 @@ type APINodeExecutor struct {
 // NewAPINodeExecutor 创建API节点执行器实例
 func NewAPINodeExecutor() *APINodeExecutor {
 	return &APINodeExecutor{
-		client: &http.Client{
-			Timeout: time.Second * 30,
-		},
+		// 所有API节点共用限流、熔断状态, 超时由节点的 timeout 配置控制
+		client: outbound.Client(),
 	}
 }
 
@@ -75,6 +89,22 @@ func (e *APINodeExecutor) ValidateConfig(config core.ItemConfig) error {
 		return errors.New("不支持的HTTP方法")
 	}
 
+	if err := validateBodyType(requestBodyType(config)); err != nil {
+		return err
+	}
+
+	if _, err := parseAPIAuth(config); err != nil {
+		return err
+	}
+
+	if _, err := parseAPIAssertions(config); err != nil {
+		return err
+	}
+
+	if _, err := parseAPIPagination(config); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -87,91 +117,76 @@ func (e *APINodeExecutor) newFailExecuteResult(msg string) *core.ExecuteResult {
 }
 
 // Execute 执行API请求
-func (e *APINodeExecutor) Execute(node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+func (e *APINodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
 	config := node.Config
 
-	// 获取URL和方法
-	urlTpl, _ := config["url"].(string)
-	method, _ := config["method"].(string)
-	method = strings.ToUpper(method)
-
-	// 处理URL模板
-	url, err := renderTemplate(urlTpl, inputs)
+	request, err := newAPIRequest(config, inputs)
 	if err != nil {
-		return e.newFailExecuteResult(fmt.Sprintf("渲染URL模板失败: %v", err))
-	}
-
-	// 准备请求体
-	var reqBody []byte
-	if body, ok := config["body"]; ok && body != nil {
-		if bodyStr, ok := body.(string); ok {
-			// 渲染请求体模板
-			renderedBody, err := renderTemplate(bodyStr, inputs)
-			if err != nil {
-				return e.newFailExecuteResult(fmt.Sprintf("渲染请求体模板失败: %v", err))
-			}
-			reqBody = []byte(renderedBody)
-		} else {
-			// 非字符串类型的请求体，直接序列化为JSON
-			reqBody, err = json.Marshal(body)
-			if err != nil {
-				return e.newFailExecuteResult(fmt.Sprintf("序列化请求体失败: %v", err))
-			}
-		}
+		return e.newFailExecuteResult(err.Error())
 	}
-
-	// 创建HTTP请求
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	request.ctx = ctx
+	assertions, err := parseAPIAssertions(config)
 	if err != nil {
-		return e.newFailExecuteResult(fmt.Sprintf("创建HTTP请求失败: %v", err))
+		return e.newFailExecuteResult(err.Error())
 	}
-
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-	if headers, ok := config["headers"].(map[string]interface{}); ok {
-		for key, value := range headers {
-			if strValue, ok := value.(string); ok {
-				req.Header.Set(key, strValue)
-			}
-		}
+	pagination, err := parseAPIPagination(config)
+	if err != nil {
+		return e.newFailExecuteResult(err.Error())
+	}
+	if pagination != nil {
+		return e.executePages(node, request, pagination, assertions)
 	}
 
 	// 执行请求
-	resp, err := e.client.Do(req)
+	result, err := e.send(request, request.url)
 	if err != nil {
-		return e.newFailExecuteResult(fmt.Sprintf("执行HTTP请求失败: %v", err))
+		return e.newFailExecuteResult(err.Error())
 	}
-	defer resp.Body.Close()
 
-	// 读取响应
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return e.newFailExecuteResult(fmt.Sprintf("读取响应失败: %v", err))
+	executeResult := &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data:    result.Output(),
 	}
 
-	// 解析JSON响应
-	var responseData interface{}
-	if err := json.Unmarshal(respBody, &responseData); err != nil {
-		// 如果不是JSON，直接返回字符串
-		responseData = string(respBody)
+	// 配置了断言时按断言结果判断成功与否, 否则 2xx 视为成功
+	if assertions != nil {
+		results := assertions.evaluate(result)
+		executeResult.Data["assertions"] = results
+		if failures := assertionFailures(results); failures != "" {
+			executeResult.Status = core.ExecuteStatusError
+			executeResult.Error = failures
+		}
+	} else if !result.Success() {
+		executeResult.Status = core.ExecuteStatusError
 	}
 
-	var status core.ExecuteStatus
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		status = core.ExecuteStatusSuccess
-	} else {
-		status = core.ExecuteStatusError
-	}
+	return executeResult
+}
 
-	// 返回执行结果
-	return &core.ExecuteResult{
-		NodeID: node.ID,
-		NodeKey: node.NodeKey,
-		Status:  status,
-		Data:  map[string]interface{}{
-			"response": responseData,
-		},
+// NewAPINode 创建预配置的API节点(未保存), 节点ID与节点键保持一致
+func NewAPINode(name, description string, config core.ItemConfig) Node {
+	node := Node{
+		NodeKey:     GenerateNodeKey(),
+		NodeType:    ApiNodeType.Code,
+		Name:        name,
+		Description: description,
+		Config:      config,
+		Status:      "active",
+	}
+	node.ID = node.NodeKey
+	return node
+}
+
+var templateIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TemplateRef 生成引用输入字段的模板表达式, 字段名不是合法标识符时使用 index
+func TemplateRef(field string) string {
+	if templateIdentifier.MatchString(field) {
+		return "{{." + field + "}}"
 	}
+	return fmt.Sprintf("{{index . %q}}", field)
 }
 
 // renderTemplate 使用输入数据渲染模板字符串
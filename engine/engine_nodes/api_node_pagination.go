@@ -0,0 +1,292 @@
+package engine_nodes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"api-flow/engine/core"
+	"api-flow/engine/jsonpath"
+)
+
+// API 节点分页方式
+const (
+	PaginationPage   = "page"
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+	PaginationLink   = "link"
+)
+
+const (
+	defaultMaxPages = 10
+	// 防止配置错误导致无限请求
+	maxPagesLimit = 1000
+)
+
+// apiPagination API 节点的 pagination 配置
+type apiPagination struct {
+	Type string `json:"type"`
+	// ItemsPath 每页数据在响应体中的 JSONPath, 为空时响应体本身应为数组
+	ItemsPath string `json:"itemsPath"`
+	// MaxPages 最多请求的页数
+	MaxPages int `json:"maxPages"`
+
+	// page: 页码参数与起始页码
+	PageParam string `json:"pageParam"`
+	StartPage *int   `json:"startPage"`
+	// page/offset: 每页数量参数, 返回数量小于 size 时视为最后一页
+	SizeParam string `json:"sizeParam"`
+	Size      int    `json:"size"`
+
+	// offset: 偏移量参数
+	OffsetParam string `json:"offsetParam"`
+
+	// cursor: 游标参数与下一页游标在响应体中的 JSONPath
+	CursorParam string `json:"cursorParam"`
+	CursorPath  string `json:"cursorPath"`
+
+	itemsPath  *jsonpath.Path
+	cursorPath *jsonpath.Path
+}
+
+// parseAPIPagination 解析节点配置中的 pagination, 未配置时返回 nil
+func parseAPIPagination(config map[string]interface{}) (*apiPagination, error) {
+	raw, ok := config["pagination"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("pagination配置无效: %v", err)
+	}
+	var p apiPagination
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("pagination配置无效: %v", err)
+	}
+
+	if p.MaxPages <= 0 {
+		p.MaxPages = defaultMaxPages
+	}
+	if p.MaxPages > maxPagesLimit {
+		return nil, fmt.Errorf("pagination.maxPages 不能超过 %d", maxPagesLimit)
+	}
+	if p.ItemsPath != "" {
+		if p.itemsPath, err = jsonpath.Compile(p.ItemsPath); err != nil {
+			return nil, fmt.Errorf("pagination.itemsPath: %v", err)
+		}
+	}
+
+	switch p.Type {
+	case PaginationPage:
+		if p.PageParam == "" {
+			p.PageParam = "page"
+		}
+		if p.StartPage == nil {
+			start := 1
+			p.StartPage = &start
+		}
+	case PaginationOffset:
+		if p.OffsetParam == "" {
+			p.OffsetParam = "offset"
+		}
+		if p.SizeParam == "" {
+			p.SizeParam = "limit"
+		}
+	case PaginationCursor:
+		if p.CursorParam == "" {
+			p.CursorParam = "cursor"
+		}
+		if p.CursorPath == "" {
+			return nil, errors.New("pagination.cursorPath 不能为空")
+		}
+		if p.cursorPath, err = jsonpath.Compile(p.CursorPath); err != nil {
+			return nil, fmt.Errorf("pagination.cursorPath: %v", err)
+		}
+	case PaginationLink:
+	default:
+		return nil, fmt.Errorf("不支持的分页方式: %s", p.Type)
+	}
+	return &p, nil
+}
+
+// items 提取一页的数据
+func (p *apiPagination) items(result *apiResponse) ([]interface{}, error) {
+	data := result.Data()
+	if p.itemsPath != nil {
+		value, ok := p.itemsPath.Lookup(data)
+		if !ok {
+			return nil, fmt.Errorf("响应中未找到 %s", p.ItemsPath)
+		}
+		data = value
+	}
+	if data == nil {
+		return []interface{}{}, nil
+	}
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, errors.New("分页数据不是数组, 请检查 pagination.itemsPath")
+	}
+	return items, nil
+}
+
+// executePages 逐页请求直到没有更多数据或达到最大页数, 合并每页数据输出到 items
+func (e *APINodeExecutor) executePages(node *Node, request *apiRequest, pagination *apiPagination, assertions *apiAssertions) *core.ExecuteResult {
+	var (
+		allItems = make([]interface{}, 0)
+		pageURL  = request.url
+		page     int
+		offset   int
+		cursor   string
+		last     *apiResponse
+		err      error
+	)
+	if pagination.Type == PaginationPage {
+		page = *pagination.StartPage
+	}
+
+	pages := 0
+	for pages < pagination.MaxPages {
+		switch pagination.Type {
+		case PaginationPage:
+			pageURL, err = setQueryParams(request.url, map[string]string{
+				pagination.PageParam: strconv.Itoa(page),
+				pagination.SizeParam: sizeValue(pagination.Size),
+			})
+		case PaginationOffset:
+			pageURL, err = setQueryParams(request.url, map[string]string{
+				pagination.OffsetParam: strconv.Itoa(offset),
+				pagination.SizeParam:   sizeValue(pagination.Size),
+			})
+		case PaginationCursor:
+			if pages > 0 {
+				pageURL, err = setQueryParams(request.url, map[string]string{pagination.CursorParam: cursor})
+			}
+		}
+		if err != nil {
+			return e.newFailExecuteResult(err.Error())
+		}
+
+		last, err = e.send(request, pageURL)
+		if err != nil {
+			return e.newFailExecuteResult(fmt.Sprintf("第%d页: %v", pages+1, err))
+		}
+		pages++
+
+		if failure := checkResponse(last, assertions); failure != "" {
+			result := e.newFailExecuteResult(fmt.Sprintf("第%d页%s", pages, failure))
+			result.NodeID, result.NodeKey = node.ID, node.NodeKey
+			result.Data = last.Output()
+			return result
+		}
+
+		items, err := pagination.items(last)
+		if err != nil {
+			return e.newFailExecuteResult(fmt.Sprintf("第%d页: %v", pages, err))
+		}
+		allItems = append(allItems, items...)
+
+		// 判断是否还有下一页
+		hasNext := true
+		switch pagination.Type {
+		case PaginationPage:
+			hasNext = len(items) > 0 && (pagination.Size <= 0 || len(items) >= pagination.Size)
+			page++
+		case PaginationOffset:
+			hasNext = len(items) > 0 && (pagination.Size <= 0 || len(items) >= pagination.Size)
+			offset += len(items)
+		case PaginationCursor:
+			value, ok := pagination.cursorPath.Lookup(last.Data())
+			next := stringify(value)
+			hasNext = ok && value != nil && next != "" && next != cursor
+			cursor = next
+		case PaginationLink:
+			next := nextLink(last.resp.Header.Values("Link"))
+			if next == "" {
+				hasNext = false
+				break
+			}
+			resolved, err := last.resp.Request.URL.Parse(next)
+			if err != nil {
+				return e.newFailExecuteResult(fmt.Sprintf("第%d页: 无效的 Link 地址 %s", pages, next))
+			}
+			pageURL = resolved.String()
+		}
+		if !hasNext {
+			break
+		}
+	}
+
+	data := last.Output()
+	data["items"] = allItems
+	data["pages"] = pages
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data:    data,
+	}
+}
+
+// checkResponse 检查单次响应是否成功, 失败时返回原因
+func checkResponse(result *apiResponse, assertions *apiAssertions) string {
+	if assertions != nil {
+		return assertionFailures(assertions.evaluate(result))
+	}
+	if !result.Success() {
+		return fmt.Sprintf("请求失败: HTTP %d", result.resp.StatusCode)
+	}
+	return ""
+}
+
+func sizeValue(size int) string {
+	if size <= 0 {
+		return ""
+	}
+	return strconv.Itoa(size)
+}
+
+// setQueryParams 设置查询参数, 参数名或值为空时跳过
+func setQueryParams(rawURL string, params map[string]string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无效的URL: %v", err)
+	}
+	query := u.Query()
+	for name, value := range params {
+		if name != "" && value != "" {
+			query.Set(name, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+var linkPattern = regexp.MustCompile(`<([^>]*)>\s*((?:;\s*[^;,]+)*)`)
+
+// nextLink 解析 RFC 5988 Link 响应头中 rel="next" 的地址
+func nextLink(headers []string) string {
+	for _, header := range headers {
+		for _, match := range linkPattern.FindAllStringSubmatch(header, -1) {
+			for _, param := range strings.Split(match[2], ";") {
+				parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(parts) != 2 || !strings.EqualFold(parts[0], "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(parts[1], `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return match[1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
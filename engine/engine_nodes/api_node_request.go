@@ -0,0 +1,203 @@
+package engine_nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiRequest 渲染完成的请求, 分页时同一个请求会以不同的 URL 多次发送
+type apiRequest struct {
+	ctx    context.Context
+	method string
+	url    string
+	body   []byte
+	header http.Header
+	auth   *apiAuth
+
+	timeout       time.Duration
+	retry         int
+	retryInterval time.Duration
+}
+
+// newAPIRequest 根据节点配置与输入渲染请求
+func newAPIRequest(config map[string]interface{}, inputs map[string]interface{}) (*apiRequest, error) {
+	urlTpl, _ := config["url"].(string)
+	method, _ := config["method"].(string)
+
+	// 处理URL模板
+	url, err := renderTemplate(urlTpl, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染URL模板失败: %v", err)
+	}
+
+	// 追加查询参数
+	url, err = applyQueryParams(url, config["params"], inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 准备请求体, Content-Type 根据请求体类型自动选择
+	body, contentType, err := buildRequestBody(config, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置请求头, 配置的 Content-Type 优先(multipart 需要使用生成的 boundary)
+	header := make(http.Header)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for key, value := range headers {
+			if strValue, ok := value.(string); ok {
+				if strings.EqualFold(key, "Content-Type") && requestBodyType(config) == BodyTypeMultipart {
+					continue
+				}
+				renderedValue, err := renderTemplate(strValue, inputs)
+				if err != nil {
+					return nil, fmt.Errorf("渲染请求头 %s 失败: %v", key, err)
+				}
+				header.Set(key, renderedValue)
+			}
+		}
+	}
+
+	auth, err := parseAPIAuth(config)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := int(configNumber(config, "retry", 0))
+	if retry < 0 {
+		retry = 0
+	}
+
+	return &apiRequest{
+		method:        strings.ToUpper(method),
+		url:           url,
+		body:          body,
+		header:        header,
+		auth:          auth,
+		timeout:       time.Duration(configNumber(config, "timeout", 30) * float64(time.Second)),
+		retry:         retry,
+		retryInterval: time.Duration(configNumber(config, "retryInterval", 5) * float64(time.Second)),
+	}, nil
+}
+
+// newHTTPRequest 创建 HTTP 请求并设置认证信息
+func (e *APINodeExecutor) newHTTPRequest(ctx context.Context, r *apiRequest, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, r.method, url, bytes.NewReader(r.body))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	req.Header = r.header.Clone()
+	if r.auth != nil {
+		if err := r.auth.apply(e.client, req); err != nil {
+			return nil, fmt.Errorf("设置认证信息失败: %v", err)
+		}
+	}
+	return req, nil
+}
+
+// context 请求所属执行的上下文, 未设置时为 context.Background()
+func (r *apiRequest) context() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
+// send 发送请求, 网络错误、5xx 与 429 按 retry 配置重试, 每次请求单独计算超时;
+// 执行被取消时立即停止重试
+func (e *APINodeExecutor) send(r *apiRequest, url string) (*apiResponse, error) {
+	var (
+		result *apiResponse
+		err    error
+	)
+	ctx := r.context()
+	for attempt := 0; attempt <= r.retry; attempt++ {
+		if attempt > 0 {
+			notifyRetry(ctx, attempt+1, retryReason(result, err))
+			if r.retryInterval > 0 {
+				select {
+				case <-time.After(r.retryInterval):
+				case <-ctx.Done():
+					return result, ctx.Err()
+				}
+			}
+		}
+		result, err = e.attempt(r, url)
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err == nil && !retryableStatus(result.resp.StatusCode) {
+			return result, nil
+		}
+	}
+	return result, err
+}
+
+// attempt 发送一次请求, OAuth2 令牌可能已被服务端提前吊销, 返回 401 时重新获取令牌并重发一次
+func (e *APINodeExecutor) attempt(r *apiRequest, url string) (*apiResponse, error) {
+	ctx := r.context()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	req, err := e.newHTTPRequest(ctx, r, url)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if result.resp.StatusCode == http.StatusUnauthorized && r.auth != nil && r.auth.invalidate() {
+		if req, err = e.newHTTPRequest(ctx, r, url); err != nil {
+			return nil, err
+		}
+		return e.do(req)
+	}
+	return result, nil
+}
+
+// retryReason 需要重试的原因: 网络错误或响应状态码
+func retryReason(result *apiResponse, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("HTTP %d", result.resp.StatusCode)
+}
+
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// configNumber 读取数值配置, 兼容 JSON 解码的 float64 与字符串
+func configNumber(config map[string]interface{}, key string, defaultValue float64) float64 {
+	switch v := config[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,152 @@
+package engine_nodes
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiResponse 一次 HTTP 请求的完整响应
+type apiResponse struct {
+	resp   *http.Response
+	body   []byte
+	timing *requestTiming
+}
+
+// requestTiming 请求各阶段耗时, 发生重定向时 DNS、连接、TLS 为各跳之和
+type requestTiming struct {
+	mu        sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	dns       time.Duration
+	connStart time.Time
+	connect   time.Duration
+	tlsStart  time.Time
+	tls       time.Duration
+	ttfb      time.Duration
+	total     time.Duration
+}
+
+func (t *requestTiming) trace() *httptrace.ClientTrace {
+	record := func(f func()) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		f()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(func() { t.dnsStart = time.Now() }) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() {
+				if !t.dnsStart.IsZero() {
+					t.dns += time.Since(t.dnsStart)
+				}
+			})
+		},
+		ConnectStart: func(string, string) { record(func() { t.connStart = time.Now() }) },
+		ConnectDone: func(string, string, error) {
+			record(func() {
+				if !t.connStart.IsZero() {
+					t.connect += time.Since(t.connStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() { record(func() { t.tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() {
+				if !t.tlsStart.IsZero() {
+					t.tls += time.Since(t.tlsStart)
+				}
+			})
+		},
+		GotFirstResponseByte: func() { record(func() { t.ttfb = time.Since(t.start) }) },
+	}
+}
+
+// Output 各阶段耗时, 单位毫秒
+func (t *requestTiming) Output() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return map[string]interface{}{
+		"dns":     durationMillis(t.dns),
+		"connect": durationMillis(t.connect),
+		"tls":     durationMillis(t.tls),
+		"ttfb":    durationMillis(t.ttfb),
+		"total":   durationMillis(t.total),
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// do 执行请求并读取响应体, 记录各阶段耗时
+func (e *APINodeExecutor) do(req *http.Request) (*apiResponse, error) {
+	timing := &requestTiming{start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.trace()))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("执行HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	timing.mu.Lock()
+	timing.total = time.Since(timing.start)
+	timing.mu.Unlock()
+
+	return &apiResponse{resp: resp, body: body, timing: timing}, nil
+}
+
+// Success 2xx 状态码视为成功
+func (r *apiResponse) Success() bool {
+	return r.resp.StatusCode >= 200 && r.resp.StatusCode < 300
+}
+
+// Data 解析响应体, 不是 JSON 时返回字符串
+func (r *apiResponse) Data() interface{} {
+	var data interface{}
+	if err := json.Unmarshal(r.body, &data); err != nil {
+		return string(r.body)
+	}
+	return data
+}
+
+// Output 节点输出, 字段与 apiNodeOutputFormat 一致
+func (r *apiResponse) Output() map[string]interface{} {
+	headers := make(map[string]interface{}, len(r.resp.Header))
+	for key, values := range r.resp.Header {
+		// Set-Cookie 不能用逗号合并, 按行合并, 解析后的值见 cookies
+		if key == "Set-Cookie" {
+			headers[key] = strings.Join(values, "\n")
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+
+	cookies := make(map[string]interface{})
+	for _, cookie := range r.resp.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+
+	return map[string]interface{}{
+		"response":    r.Data(),
+		"status":      r.resp.StatusCode,
+		"statusText":  http.StatusText(r.resp.StatusCode),
+		"headers":     headers,
+		"cookies":     cookies,
+		"url":         r.resp.Request.URL.String(),
+		"contentType": r.resp.Header.Get("Content-Type"),
+		"size":        len(r.body),
+		"timing":      r.timing.Output(),
+	}
+}
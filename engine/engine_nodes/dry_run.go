@@ -0,0 +1,242 @@
+package engine_nodes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"api-flow/engine/core"
+)
+
+// DryRunner 会发出网络请求的执行器实现该接口, 试运行时只渲染请求(方法、URL、请求头、请求体等)而不发送
+type DryRunner interface {
+	DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error)
+}
+
+// dryRunResult 试运行结果: 按输出格式生成的占位输出, request 为渲染后的请求
+func dryRunResult(ctx context.Context, node *Node, runner DryRunner, inputs map[string]interface{}) *core.ExecuteResult {
+	request, err := runner.DryRun(ctx, node, inputs)
+	if err != nil {
+		return &core.ExecuteResult{
+			NodeID:  node.ID,
+			NodeKey: node.NodeKey,
+			Status:  core.ExecuteStatusError,
+			Error:   err.Error(),
+		}
+	}
+	data := PlaceholderOutput(node.NodeKey, NodeOutputFormat(node))
+	data["request"] = request
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data:    data,
+	}
+}
+
+// PlaceholderOutput 按输出格式生成占位输出: 字符串为 <节点键.字段>, 数字为 0, 布尔为 false, 对象与数组为空,
+// 声明了默认值或选项时使用默认值或第一个选项。
+// 带 "." 的字段(如导入节点的 response.id)生成嵌套对象, 与执行时的输出结构一致, 下游节点可以用 ${节点键.response.id} 引用
+func PlaceholderOutput(nodeKey string, format core.ParamFormat) core.ExecuteOutput {
+	output := map[string]interface{}{}
+	for _, param := range format {
+		if param == nil || param.Field == "" {
+			continue
+		}
+		setPlaceholder(output, strings.Split(param.Field, "."), placeholderValue(nodeKey, param))
+	}
+	return output
+}
+
+// setPlaceholder 按字段路径写入占位值, 路径中间的对象不存在时创建, 已有的对象复制后写入, 避免修改声明中的默认值
+func setPlaceholder(output map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		// 已经由子字段生成的对象不被父字段的占位值覆盖
+		if _, ok := output[path[0]].(map[string]interface{}); ok {
+			return
+		}
+		output[path[0]] = value
+		return
+	}
+	child := map[string]interface{}{}
+	if existing, ok := output[path[0]].(map[string]interface{}); ok {
+		for key, v := range existing {
+			child[key] = v
+		}
+	}
+	setPlaceholder(child, path[1:], value)
+	output[path[0]] = child
+}
+
+func placeholderValue(nodeKey string, param *core.ParamDefination) interface{} {
+	if param.Default != nil {
+		return param.Default
+	}
+	switch param.Datatype {
+	case core.DataTypeString:
+		return fmt.Sprintf("<%s.%s>", nodeKey, param.Field)
+	case core.DataTypeNumber:
+		return 0
+	case core.DataTypeBoolean:
+		return false
+	case core.DataTypeArray:
+		return []interface{}{}
+	case core.DataTypeObject:
+		return map[string]interface{}{}
+	case core.DataTypeOptions:
+		if len(param.Options) > 0 {
+			return param.Options[0]
+		}
+		return ""
+	default:
+		return nil
+	}
+}
+
+// DryRun 渲染 API 请求, 认证信息只标记位置, 不解析密钥也不获取 OAuth2 令牌
+func (e *APINodeExecutor) DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	request, err := newAPIRequest(node.Config, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return request.dryRun()
+}
+
+// DryRun 渲染 GraphQL 请求
+func (e *GraphQLNodeExecutor) DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	request, err := newGraphQLRequest(node.Config, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return request.dryRun()
+}
+
+// DryRun 渲染 gRPC 调用, 不反射服务端, message 为渲染后的 JSON 对象(未按描述符校验)
+func (e *GrpcNodeExecutor) DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	config := node.Config
+	targetTpl, _ := config["target"].(string)
+	target, err := renderTemplate(targetTpl, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染target模板失败: %v", err)
+	}
+	fields, err := graphqlVariables(config["message"])
+	if err != nil {
+		return nil, fmt.Errorf("message必须是对象")
+	}
+	message, err := resolveVariable(fields, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染message失败: %v", err)
+	}
+	metadata, err := grpcMetadata(config["metadata"], inputs)
+	if err != nil {
+		return nil, err
+	}
+	service, _ := config["service"].(string)
+	method, _ := config["method"].(string)
+	return map[string]interface{}{
+		"target":   target,
+		"tls":      config["tls"] == true,
+		"method":   fmt.Sprintf("%s/%s", service, method),
+		"metadata": dryRunHeader(metadata),
+		"message":  message,
+	}, nil
+}
+
+// DryRun 渲染 WebSocket 握手地址、请求头与连接后发送的消息
+func (e *WebSocketNodeExecutor) DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	config := node.Config
+	urlTpl, _ := config["url"].(string)
+	url, err := renderTemplate(urlTpl, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染URL模板失败: %v", err)
+	}
+	header, err := renderHeaders(config["headers"], inputs)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := websocketMessages(config["messages"], inputs)
+	if err != nil {
+		return nil, err
+	}
+	rendered := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		rendered = append(rendered, string(message))
+	}
+	return map[string]interface{}{
+		"url":      url,
+		"headers":  dryRunHeader(header),
+		"messages": rendered,
+	}, nil
+}
+
+// DryRun 渲染 SSE 订阅请求
+func (e *SSENodeExecutor) DryRun(ctx context.Context, node *Node, inputs map[string]interface{}) (map[string]interface{}, error) {
+	req, err := newSSERequest(ctx, node.Config, inputs)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return dryRunRequest(req.Method, req.URL.String(), req.Header, body), nil
+}
+
+// dryRun 渲染后的请求, 认证信息的值以 *** 代替
+func (r *apiRequest) dryRun() (map[string]interface{}, error) {
+	req, err := http.NewRequest(r.method, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	req.Header = r.header.Clone()
+	if r.auth != nil {
+		r.auth.describe(req)
+	}
+	return dryRunRequest(req.Method, req.URL.String(), req.Header, r.body), nil
+}
+
+// describe 试运行时标记认证信息的位置, 不解析密钥
+func (a *apiAuth) describe(req *http.Request) {
+	switch a.Type {
+	case AuthTypeBasic:
+		req.Header.Set("Authorization", "Basic ***")
+	case AuthTypeBearer, AuthTypeOAuth2:
+		req.Header.Set("Authorization", "Bearer ***")
+	case AuthTypeAPIKey:
+		if a.In == "query" {
+			query := req.URL.Query()
+			query.Set(a.Name, "***")
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set(a.Name, "***")
+		}
+	}
+}
+
+func dryRunRequest(method, url string, header http.Header, body []byte) map[string]interface{} {
+	request := map[string]interface{}{
+		"method":  method,
+		"url":     url,
+		"headers": dryRunHeader(header),
+	}
+	if len(body) > 0 {
+		if utf8.Valid(body) {
+			request["body"] = string(body)
+		} else {
+			request["body"] = fmt.Sprintf("<%d 字节二进制内容>", len(body))
+		}
+	}
+	return request
+}
+
+// dryRunHeader 请求头, 多个值以逗号分隔
+func dryRunHeader(header http.Header) map[string]interface{} {
+	result := make(map[string]interface{}, len(header))
+	for key, values := range header {
+		result[key] = strings.Join(values, ", ")
+	}
+	return result
+}
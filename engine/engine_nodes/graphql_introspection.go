@@ -0,0 +1,251 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// introspectionQuery 获取根操作类型及各类型字段的内省查询
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: false) {
+        name
+        description
+        args { name description defaultValue type { ...TypeRef } }
+        type { ...TypeRef }
+      }
+    }
+  }
+}
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } }
+}`
+
+// GraphQLOperation 通过内省获取的可调用操作(Query/Mutation 的根字段)
+type GraphQLOperation struct {
+	Type        string            `json:"type"` // query 或 mutation
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Args        []GraphQLArgument `json:"args"`
+	ReturnType  string            `json:"returnType"`
+	// Query 生成的查询文档, 选择返回类型的标量字段
+	Query string `json:"query"`
+	// Variables 变量模板, 每个参数引用同名输入字段
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// GraphQLArgument 操作参数
+type GraphQLArgument struct {
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	Required     bool    `json:"required"`
+	Description  string  `json:"description,omitempty"`
+	DefaultValue *string `json:"defaultValue,omitempty"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+// String 类型的 GraphQL 写法, 如 [ID!]!
+func (t *introspectionTypeRef) String() string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Kind == "NON_NULL":
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST":
+		return "[" + t.OfType.String() + "]"
+	default:
+		return t.Name
+	}
+}
+
+// named 去掉 NON_NULL 与 LIST 包装后的具名类型
+func (t *introspectionTypeRef) named() *introspectionTypeRef {
+	for t != nil && t.OfType != nil && (t.Kind == "NON_NULL" || t.Kind == "LIST") {
+		t = t.OfType
+	}
+	return t
+}
+
+func (t *introspectionTypeRef) leaf() bool {
+	named := t.named()
+	return named != nil && (named.Kind == "SCALAR" || named.Kind == "ENUM")
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	DefaultValue *string              `json:"defaultValue"`
+	Type         introspectionTypeRef `json:"type"`
+}
+
+type introspectionField struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Args        []introspectionInputValue `json:"args"`
+	Type        introspectionTypeRef      `json:"type"`
+}
+
+type introspectionType struct {
+	Kind   string               `json:"kind"`
+	Name   string               `json:"name"`
+	Fields []introspectionField `json:"fields"`
+}
+
+type introspectionSchema struct {
+	QueryType    *struct{ Name string } `json:"queryType"`
+	MutationType *struct{ Name string } `json:"mutationType"`
+	Types        []introspectionType    `json:"types"`
+}
+
+// Introspect 内省 GraphQL 接口, 列出可调用的 Query 与 Mutation 操作(Subscription 无法通过 HTTP 执行, 不列出)
+// config 与节点配置相同, 只使用 endpoint、headers、auth 与超时重试配置
+func (e *GraphQLNodeExecutor) Introspect(config core.ItemConfig) ([]*GraphQLOperation, error) {
+	if endpoint, ok := config["endpoint"].(string); !ok || endpoint == "" {
+		return nil, errors.New("endpoint必须是非空字符串")
+	}
+	introspectConfig := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		introspectConfig[key] = value
+	}
+	introspectConfig["query"] = introspectionQuery
+	delete(introspectConfig, "variables")
+	delete(introspectConfig, "operationName")
+
+	request, err := newGraphQLRequest(introspectConfig, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.api.send(request, request.url)
+	if err != nil {
+		return nil, err
+	}
+	response, err := parseGraphQLResponse(result)
+	if err != nil {
+		return nil, err
+	}
+	if failure := response.failure(GraphQLErrorsFail); failure != "" {
+		return nil, fmt.Errorf("内省查询失败, 服务端可能禁用了内省: %s", failure)
+	}
+
+	data, err := json.Marshal(response.Data)
+	if err != nil {
+		return nil, err
+	}
+	var introspection struct {
+		Schema introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &introspection); err != nil {
+		return nil, fmt.Errorf("解析内省结果失败: %v", err)
+	}
+	return introspection.Schema.operations(), nil
+}
+
+func (s *introspectionSchema) operations() []*GraphQLOperation {
+	types := make(map[string]*introspectionType, len(s.Types))
+	for i := range s.Types {
+		types[s.Types[i].Name] = &s.Types[i]
+	}
+
+	operations := make([]*GraphQLOperation, 0)
+	roots := []struct {
+		operationType string
+		root          *struct{ Name string }
+	}{
+		{"query", s.QueryType},
+		{"mutation", s.MutationType},
+	}
+	for _, r := range roots {
+		if r.root == nil || types[r.root.Name] == nil {
+			continue
+		}
+		for _, field := range types[r.root.Name].Fields {
+			operations = append(operations, newGraphQLOperation(r.operationType, field, types))
+		}
+	}
+	return operations
+}
+
+func newGraphQLOperation(operationType string, field introspectionField, types map[string]*introspectionType) *GraphQLOperation {
+	operation := &GraphQLOperation{
+		Type:        operationType,
+		Name:        field.Name,
+		Description: field.Description,
+		Args:        make([]GraphQLArgument, 0, len(field.Args)),
+		ReturnType:  field.Type.String(),
+		Variables:   make(map[string]interface{}, len(field.Args)),
+	}
+
+	definitions := make([]string, 0, len(field.Args))
+	arguments := make([]string, 0, len(field.Args))
+	for _, arg := range field.Args {
+		operation.Args = append(operation.Args, GraphQLArgument{
+			Name:         arg.Name,
+			Type:         arg.Type.String(),
+			Required:     arg.Type.Kind == "NON_NULL" && arg.DefaultValue == nil,
+			Description:  arg.Description,
+			DefaultValue: arg.DefaultValue,
+		})
+		operation.Variables[arg.Name] = TemplateRef(arg.Name)
+		definitions = append(definitions, fmt.Sprintf("$%s: %s", arg.Name, arg.Type.String()))
+		arguments = append(arguments, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+	}
+
+	var query strings.Builder
+	query.WriteString(operationType + " " + field.Name)
+	if len(definitions) > 0 {
+		query.WriteString("(" + strings.Join(definitions, ", ") + ")")
+	}
+	query.WriteString(" {\n  " + field.Name)
+	if len(arguments) > 0 {
+		query.WriteString("(" + strings.Join(arguments, ", ") + ")")
+	}
+	if selection := leafSelection(&field.Type, types); len(selection) > 0 {
+		query.WriteString(" {\n    " + strings.Join(selection, "\n    ") + "\n  }")
+	}
+	query.WriteString("\n}")
+	operation.Query = query.String()
+
+	return operation
+}
+
+// leafSelection 返回类型的选择集: 对象类型选择不需要必填参数的标量字段, 没有时只选择 __typename
+func leafSelection(typeRef *introspectionTypeRef, types map[string]*introspectionType) []string {
+	if typeRef.leaf() {
+		return nil
+	}
+	selection := make([]string, 0)
+	if named := types[typeRef.named().Name]; named != nil {
+		for _, field := range named.Fields {
+			if field.Type.leaf() && !hasRequiredArgs(field.Args) {
+				selection = append(selection, field.Name)
+			}
+		}
+	}
+	if len(selection) == 0 {
+		selection = append(selection, "__typename")
+	}
+	return selection
+}
+
+func hasRequiredArgs(args []introspectionInputValue) bool {
+	for _, arg := range args {
+		if arg.Type.Kind == "NON_NULL" && arg.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,389 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphQL 节点对响应中 errors 的处理方式
+const (
+	// GraphQLErrorsFail 有任何错误即视为失败
+	GraphQLErrorsFail = "fail"
+	// GraphQLErrorsPartial 有错误但返回了 data 时视为成功(部分成功)
+	GraphQLErrorsPartial = "partial"
+	// GraphQLErrorsIgnore 忽略错误, 只要是有效的 GraphQL 响应即视为成功
+	GraphQLErrorsIgnore = "ignore"
+)
+
+// GraphQLErrorPolicies GraphQL 节点支持的错误处理方式
+var GraphQLErrorPolicies = []interface{}{GraphQLErrorsFail, GraphQLErrorsPartial, GraphQLErrorsIgnore}
+
+var graphqlNodeOutputFormat = core.ParamFormat{
+	core.NewParamDefination("data", core.DataTypeObject, "GraphQL响应中的data"),
+	core.NewParamDefination("errors", core.DataTypeArray, "GraphQL响应中的errors, 没有错误时为空数组"),
+	core.NewParamDefination("extensions", core.DataTypeObject, "GraphQL响应中的extensions"),
+	core.NewParamDefination("status", core.DataTypeNumber, "HTTP状态码"),
+	core.NewParamDefination("headers", core.DataTypeObject, "响应头, 多个值以逗号分隔"),
+	core.NewParamDefination("timing", core.DataTypeObject, "请求耗时（毫秒）: dns、connect、tls、ttfb、total"),
+}
+var graphqlNodeInputFormat = core.ParamFormat{
+	core.NewParamString("endpoint", "GraphQL 接口地址", ""),
+	core.NewParamString("query", "查询文档", ""),
+	core.NewParamObject("variables", "变量, 值支持模板, 单独引用输入字段时保留原始类型", map[string]interface{}{}),
+	core.NewParamString("operationName", "操作名称, 文档包含多个操作时必填", ""),
+	core.NewParamObject("headers", "请求头", map[string]interface{}{}),
+	core.NewParamOptions("errorPolicy", "GraphQL errors 的处理方式", GraphQLErrorsFail, GraphQLErrorPolicies),
+	core.NewParamNumber("timeout", "请求超时时间（秒）", 30),
+	core.NewParamNumber("retry", "重试次数", 0),
+	core.NewParamNumber("retryInterval", "重试间隔时间（秒）", 5),
+	core.NewParamObject("auth", "认证配置", map[string]interface{}{}),
+}
+var GraphQLNodeType = &NodeType{
+	Code:        "graphql",
+	Name:        "GraphQL",
+	Description: "调用GraphQL接口并分别输出data与errors的节点",
+	Category:    "Task",
+	Input:       graphqlNodeInputFormat,
+	Output:      graphqlNodeOutputFormat,
+}
+
+// GraphQLNodeExecutor GraphQL 节点执行器, 请求的发送(超时、重试、认证、出站治理)与 API 节点一致
+type GraphQLNodeExecutor struct {
+	api *APINodeExecutor
+}
+
+// NewGraphQLNodeExecutor 创建 GraphQL 节点执行器实例
+func NewGraphQLNodeExecutor() *GraphQLNodeExecutor {
+	return &GraphQLNodeExecutor{
+		api: NewAPINodeExecutor(),
+	}
+}
+
+func (e *GraphQLNodeExecutor) GetOutputFormat() core.ParamFormat {
+	return graphqlNodeOutputFormat
+}
+
+// ValidateConfig 验证 GraphQL 节点配置
+func (e *GraphQLNodeExecutor) ValidateConfig(config core.ItemConfig) error {
+	if config == nil {
+		return errors.New("配置不能为空")
+	}
+
+	endpoint, ok := config["endpoint"].(string)
+	if !ok || endpoint == "" {
+		return errors.New("endpoint必须是非空字符串")
+	}
+
+	query, ok := config["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return errors.New("query必须是非空字符串")
+	}
+
+	if _, err := graphqlVariables(config["variables"]); err != nil {
+		return err
+	}
+
+	if err := validateErrorPolicy(graphqlErrorPolicy(config)); err != nil {
+		return err
+	}
+
+	if _, err := parseAPIAuth(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *GraphQLNodeExecutor) newFailExecuteResult(node *Node, msg string) *core.ExecuteResult {
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusError,
+		Data:    nil,
+		Error:   msg,
+	}
+}
+
+// Execute 执行 GraphQL 请求
+func (e *GraphQLNodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+	config := node.Config
+
+	request, err := newGraphQLRequest(config, inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	request.ctx = ctx
+
+	result, err := e.api.send(request, request.url)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+
+	response, err := parseGraphQLResponse(result)
+	executeResult := &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data:    response.output(result),
+	}
+	if err != nil {
+		executeResult.Status = core.ExecuteStatusError
+		executeResult.Error = err.Error()
+		return executeResult
+	}
+
+	if failure := response.failure(graphqlErrorPolicy(config)); failure != "" {
+		executeResult.Status = core.ExecuteStatusError
+		executeResult.Error = failure
+	}
+	return executeResult
+}
+
+// graphqlErrorPolicy 获取 errors 的处理方式, 默认有错误即失败
+func graphqlErrorPolicy(config map[string]interface{}) string {
+	if policy, ok := config["errorPolicy"].(string); ok && policy != "" {
+		return policy
+	}
+	return GraphQLErrorsFail
+}
+
+func validateErrorPolicy(policy string) error {
+	for _, p := range GraphQLErrorPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的errorPolicy: %s", policy)
+}
+
+// graphqlVariables 读取 variables 配置, 可以是对象或对象的 JSON 字符串
+func graphqlVariables(raw interface{}) (map[string]interface{}, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		var variables map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &variables); err != nil {
+			return nil, fmt.Errorf("variables必须是对象: %v", err)
+		}
+		return variables, nil
+	default:
+		return nil, errors.New("variables必须是对象")
+	}
+}
+
+// 只引用一个输入字段的模板, 如 {{.id}}、{{index . "user-id"}}
+var (
+	fieldRefPattern = regexp.MustCompile(`^\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+	indexRefPattern = regexp.MustCompile(`^\{\{\s*index\s+\.\s+("(?:[^"\\]|\\.)*")\s*\}\}$`)
+)
+
+// resolveVariable 渲染变量值中的模板, 单独引用一个输入字段时直接使用输入值,
+// 以保留数字、布尔、对象等类型(字段不存在时为 null)
+func resolveVariable(value interface{}, inputs map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if m := fieldRefPattern.FindStringSubmatch(v); m != nil {
+			return inputs[m[1]], nil
+		}
+		if m := indexRefPattern.FindStringSubmatch(v); m != nil {
+			if field, err := strconv.Unquote(m[1]); err == nil {
+				return inputs[field], nil
+			}
+		}
+		return renderTemplate(v, inputs)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved, err := resolveVariable(item, inputs)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := resolveVariable(item, inputs)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// newGraphQLRequest 根据节点配置与输入渲染 GraphQL 请求(POST application/json)
+func newGraphQLRequest(config map[string]interface{}, inputs map[string]interface{}) (*apiRequest, error) {
+	endpointTpl, _ := config["endpoint"].(string)
+	query, _ := config["query"].(string)
+	operationName, _ := config["operationName"].(string)
+
+	endpoint, err := renderTemplate(endpointTpl, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染endpoint模板失败: %v", err)
+	}
+
+	payload := map[string]interface{}{"query": query}
+	if operationName != "" {
+		payload["operationName"] = operationName
+	}
+	variables, err := graphqlVariables(config["variables"])
+	if err != nil {
+		return nil, err
+	}
+	if len(variables) > 0 {
+		resolved, err := resolveVariable(variables, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("渲染variables失败: %v", err)
+		}
+		payload["variables"] = resolved
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化GraphQL请求失败: %v", err)
+	}
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept", "application/graphql-response+json, application/json")
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for key, value := range headers {
+			if strValue, ok := value.(string); ok {
+				renderedValue, err := renderTemplate(strValue, inputs)
+				if err != nil {
+					return nil, fmt.Errorf("渲染请求头 %s 失败: %v", key, err)
+				}
+				header.Set(key, renderedValue)
+			}
+		}
+	}
+
+	auth, err := parseAPIAuth(config)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := int(configNumber(config, "retry", 0))
+	if retry < 0 {
+		retry = 0
+	}
+
+	return &apiRequest{
+		method:        http.MethodPost,
+		url:           endpoint,
+		body:          body,
+		header:        header,
+		auth:          auth,
+		timeout:       time.Duration(configNumber(config, "timeout", 30) * float64(time.Second)),
+		retry:         retry,
+		retryInterval: time.Duration(configNumber(config, "retryInterval", 5) * float64(time.Second)),
+	}, nil
+}
+
+// graphqlResponse GraphQL 响应体
+type graphqlResponse struct {
+	Data       interface{}            `json:"data"`
+	Errors     []interface{}          `json:"errors"`
+	Extensions map[string]interface{} `json:"extensions"`
+
+	hasData bool
+}
+
+// parseGraphQLResponse 解析响应体, 既没有 data 也没有 errors 时不是有效的 GraphQL 响应
+func parseGraphQLResponse(result *apiResponse) (*graphqlResponse, error) {
+	response := &graphqlResponse{}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(result.body, &fields); err == nil {
+		if err := json.Unmarshal(result.body, response); err == nil {
+			_, response.hasData = fields["data"]
+		}
+	}
+	if !response.hasData && len(response.Errors) == 0 {
+		return response, fmt.Errorf("不是有效的GraphQL响应(HTTP %d): %s", result.resp.StatusCode, truncate(string(result.body), 200))
+	}
+	if !result.Success() && response.Data == nil {
+		return response, fmt.Errorf("GraphQL请求失败(HTTP %d): %s", result.resp.StatusCode, response.errorMessages())
+	}
+	return response, nil
+}
+
+// failure 按错误处理方式判断是否失败, 失败时返回原因
+func (r *graphqlResponse) failure(policy string) string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+	switch policy {
+	case GraphQLErrorsIgnore:
+		return ""
+	case GraphQLErrorsPartial:
+		if r.Data != nil {
+			return ""
+		}
+	}
+	return "GraphQL错误: " + r.errorMessages()
+}
+
+// errorMessages 合并 errors 中的 message, 附带出错的字段路径
+func (r *graphqlResponse) errorMessages() string {
+	messages := make([]string, 0, len(r.Errors))
+	for _, item := range r.Errors {
+		graphqlErr, ok := item.(map[string]interface{})
+		if !ok {
+			messages = append(messages, stringify(item))
+			continue
+		}
+		message := stringify(graphqlErr["message"])
+		if path, ok := graphqlErr["path"].([]interface{}); ok && len(path) > 0 {
+			parts := make([]string, len(path))
+			for i, p := range path {
+				parts[i] = stringify(p)
+			}
+			message = fmt.Sprintf("%s (%s)", message, strings.Join(parts, "."))
+		}
+		messages = append(messages, message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// output 节点输出, 字段与 graphqlNodeOutputFormat 一致
+func (r *graphqlResponse) output(result *apiResponse) map[string]interface{} {
+	errs := r.Errors
+	if errs == nil {
+		errs = []interface{}{}
+	}
+	output := result.Output()
+	return map[string]interface{}{
+		"data":       r.Data,
+		"errors":     errs,
+		"extensions": r.Extensions,
+		"status":     output["status"],
+		"headers":    output["headers"],
+		"timing":     output["timing"],
+	}
+}
+
+func truncate(str string, max int) string {
+	runes := []rune(str)
+	if len(runes) <= max {
+		return str
+	}
+	return string(runes[:max]) + "..."
+}
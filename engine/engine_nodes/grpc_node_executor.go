@@ -0,0 +1,346 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"api-flow/engine/grpcclient"
+	"api-flow/engine/outbound"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var grpcNodeOutputFormat = core.ParamFormat{
+	core.NewParamDefination("response", core.DataTypeObject, "响应消息(JSON)"),
+	core.NewParamDefination("status", core.DataTypeNumber, "gRPC状态码, 0 表示成功"),
+	core.NewParamDefination("statusName", core.DataTypeString, "gRPC状态名称, 如 OK、NOT_FOUND"),
+	core.NewParamDefination("statusMessage", core.DataTypeString, "gRPC状态描述(grpc-message)"),
+	core.NewParamDefination("headers", core.DataTypeObject, "响应头部元数据"),
+	core.NewParamDefination("trailers", core.DataTypeObject, "响应尾部元数据"),
+}
+var grpcNodeInputFormat = core.ParamFormat{
+	core.NewParamString("target", "服务地址 host:port", ""),
+	core.NewParamBoolean("tls", "是否使用TLS", false),
+	core.NewParamString("service", "服务全名, 如 helloworld.Greeter", ""),
+	core.NewParamString("method", "方法名, 如 SayHello", ""),
+	core.NewParamObject("message", "请求消息(JSON), 值支持模板, 单独引用输入字段时保留原始类型", map[string]interface{}{}),
+	core.NewParamObject("metadata", "请求元数据", map[string]interface{}{}),
+	core.NewParamString("descriptorSet", "FileDescriptorSet(base64), 为空时使用服务端反射", ""),
+	core.NewParamNumber("timeout", "请求超时时间（秒）", 30),
+}
+var GrpcNodeType = &NodeType{
+	Code:        "grpc",
+	Name:        "gRPC",
+	Description: "调用gRPC一元方法的节点, 通过服务端反射或上传的描述符解析服务",
+	Category:    "Task",
+	Input:       grpcNodeInputFormat,
+	Output:      grpcNodeOutputFormat,
+}
+
+// GrpcNodeExecutor gRPC 节点执行器
+type GrpcNodeExecutor struct {
+	client *grpcclient.Client
+}
+
+// NewGrpcNodeExecutor 创建 gRPC 节点执行器实例
+func NewGrpcNodeExecutor() *GrpcNodeExecutor {
+	return &GrpcNodeExecutor{
+		// 与API节点共用出站策略、限流与熔断状态
+		client: &grpcclient.Client{HTTP: outbound.HTTP2Client()},
+	}
+}
+
+func (e *GrpcNodeExecutor) GetOutputFormat() core.ParamFormat {
+	return grpcNodeOutputFormat
+}
+
+// ValidateConfig 验证 gRPC 节点配置
+func (e *GrpcNodeExecutor) ValidateConfig(config core.ItemConfig) error {
+	if config == nil {
+		return errors.New("配置不能为空")
+	}
+
+	for _, key := range []string{"target", "service", "method"} {
+		if value, ok := config[key].(string); !ok || value == "" {
+			return fmt.Errorf("%s必须是非空字符串", key)
+		}
+	}
+
+	if _, err := graphqlVariables(config["message"]); err != nil {
+		return errors.New("message必须是对象")
+	}
+
+	if descriptorSet, _ := config["descriptorSet"].(string); descriptorSet != "" {
+		if _, err := grpcclient.ParseDescriptorSet([]byte(descriptorSet)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *GrpcNodeExecutor) newFailExecuteResult(node *Node, msg string) *core.ExecuteResult {
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusError,
+		Data:    nil,
+		Error:   msg,
+	}
+}
+
+// Execute 调用 gRPC 一元方法
+func (e *GrpcNodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+	config := node.Config
+
+	targetTpl, _ := config["target"].(string)
+	address, err := renderTemplate(targetTpl, inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, fmt.Sprintf("渲染target模板失败: %v", err))
+	}
+	target := grpcclient.Target{Address: address, TLS: config["tls"] == true}
+	service, _ := config["service"].(string)
+	methodName, _ := config["method"].(string)
+
+	if timeout := time.Duration(configNumber(config, "timeout", 30) * float64(time.Second)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	method, err := e.resolveMethod(ctx, config, target, service, methodName)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return e.newFailExecuteResult(node, fmt.Sprintf("%s/%s 是流式方法, 只支持一元调用", service, methodName))
+	}
+
+	request, err := buildGrpcMessage(method.Input(), config["message"], inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	metadata, err := grpcMetadata(config["metadata"], inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+
+	resp, err := e.client.Invoke(ctx, target, fmt.Sprintf("%s/%s", service, methodName), request, metadata)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+
+	data := map[string]interface{}{
+		"response":      nil,
+		"status":        int(resp.Status.Code),
+		"statusName":    resp.Status.Code.String(),
+		"statusMessage": resp.Status.Message,
+		"headers":       flattenHeader(resp.Header),
+		"trailers":      flattenHeader(resp.Trailer),
+	}
+	if resp.Message != nil {
+		response, err := decodeGrpcMessage(method.Output(), resp.Message)
+		if err != nil {
+			return e.newFailExecuteResult(node, err.Error())
+		}
+		data["response"] = response
+	}
+
+	executeResult := &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data:    data,
+	}
+	if resp.Status.Code != grpcclient.OK {
+		executeResult.Status = core.ExecuteStatusError
+		executeResult.Error = resp.Status.Error()
+	}
+	return executeResult
+}
+
+// 反射结果的缓存时间, 过期后重新反射以获取服务端更新的消息定义
+const grpcReflectionTTL = 5 * time.Minute
+
+type grpcCacheEntry struct {
+	descriptors *grpcclient.Descriptors
+	expiresAt   time.Time // 为零时不过期
+}
+
+// grpcDescriptorCache 进程内缓存解析好的描述符, 反射结果按目标地址缓存, 上传的描述符按内容缓存
+type grpcDescriptorCache struct {
+	mu      sync.Mutex
+	entries map[string]grpcCacheEntry
+}
+
+var defaultGrpcDescriptorCache = &grpcDescriptorCache{entries: make(map[string]grpcCacheEntry)}
+
+func (c *grpcDescriptorCache) get(key string) *grpcclient.Descriptors {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || (!entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)) {
+		return nil
+	}
+	return entry.descriptors
+}
+
+func (c *grpcDescriptorCache) set(key string, descriptors *grpcclient.Descriptors, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := grpcCacheEntry{descriptors: descriptors}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+}
+
+// resolveMethod 查找方法描述符, 配置了 descriptorSet 时使用上传的描述符, 否则通过服务端反射获取;
+// 缓存的反射结果中找不到方法时(服务端可能已更新)重新反射一次
+func (e *GrpcNodeExecutor) resolveMethod(ctx context.Context, config map[string]interface{}, target grpcclient.Target, service, method string) (protoreflect.MethodDescriptor, error) {
+	if descriptorSet, _ := config["descriptorSet"].(string); descriptorSet != "" {
+		sum := sha256.Sum256([]byte(descriptorSet))
+		key := "set:" + hex.EncodeToString(sum[:])
+		descriptors := defaultGrpcDescriptorCache.get(key)
+		if descriptors == nil {
+			var err error
+			if descriptors, err = grpcclient.ParseDescriptorSet([]byte(descriptorSet)); err != nil {
+				return nil, err
+			}
+			defaultGrpcDescriptorCache.set(key, descriptors, 0)
+		}
+		return descriptors.Method(service, method)
+	}
+
+	key := fmt.Sprintf("reflect:%t:%s:%s", target.TLS, target.Address, service)
+	if descriptors := defaultGrpcDescriptorCache.get(key); descriptors != nil {
+		if methodDesc, err := descriptors.Method(service, method); err == nil {
+			return methodDesc, nil
+		}
+	}
+	descriptors, err := e.client.Reflect(ctx, target, service)
+	if err != nil {
+		return nil, err
+	}
+	defaultGrpcDescriptorCache.set(key, descriptors, grpcReflectionTTL)
+	return descriptors.Method(service, method)
+}
+
+// Services 列出可调用的服务与方法, descriptorSet 为空时通过服务端反射获取
+func (e *GrpcNodeExecutor) Services(target grpcclient.Target, descriptorSet []byte, timeout time.Duration) ([]grpcclient.ServiceInfo, error) {
+	if len(descriptorSet) > 0 {
+		descriptors, err := grpcclient.ParseDescriptorSet(descriptorSet)
+		if err != nil {
+			return nil, err
+		}
+		return descriptors.Services(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	services, err := e.client.ListServices(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]string, 0, len(services))
+	for _, service := range services {
+		if !strings.HasPrefix(service, "grpc.reflection.") {
+			symbols = append(symbols, service)
+		}
+	}
+	if len(symbols) == 0 {
+		return []grpcclient.ServiceInfo{}, nil
+	}
+	descriptors, err := e.client.Reflect(ctx, target, symbols...)
+	if err != nil {
+		return nil, err
+	}
+	return descriptors.Services(), nil
+}
+
+// buildGrpcMessage 渲染请求消息中的模板并按描述符编码
+func buildGrpcMessage(desc protoreflect.MessageDescriptor, raw interface{}, inputs map[string]interface{}) ([]byte, error) {
+	fields, err := graphqlVariables(raw)
+	if err != nil {
+		return nil, errors.New("message必须是对象")
+	}
+	resolved, err := resolveVariable(fields, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染message失败: %v", err)
+	}
+	if resolved == nil {
+		resolved = map[string]interface{}{}
+	}
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("序列化message失败: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(desc)
+	if err := protojson.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("message与 %s 不匹配: %v", desc.FullName(), err)
+	}
+	encoded, err := proto.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("编码message失败: %v", err)
+	}
+	return encoded, nil
+}
+
+// decodeGrpcMessage 按描述符解码响应消息并转换为 JSON 对象, 未设置的字段输出零值
+func decodeGrpcMessage(desc protoreflect.MessageDescriptor, data []byte) (interface{}, error) {
+	message := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("解析响应消息失败: %v", err)
+	}
+	bytes, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("转换响应消息失败: %v", err)
+	}
+	var result interface{}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// grpcMetadata 渲染请求元数据, 键统一为小写
+func grpcMetadata(raw interface{}, inputs map[string]interface{}) (http.Header, error) {
+	metadata := make(http.Header)
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return metadata, nil
+	}
+	for key, value := range values {
+		strValue, ok := value.(string)
+		if !ok {
+			continue
+		}
+		rendered, err := renderTemplate(strValue, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("渲染元数据 %s 失败: %v", key, err)
+		}
+		metadata[strings.ToLower(key)] = []string{rendered}
+	}
+	return metadata, nil
+}
+
+// flattenHeader 多个值以逗号分隔
+func flattenHeader(header http.Header) map[string]interface{} {
+	result := make(map[string]interface{}, len(header))
+	for key, values := range header {
+		result[strings.ToLower(key)] = strings.Join(values, ", ")
+	}
+	return result
+}
@@ -4,7 +4,10 @@
 
 package engine_nodes
 
-import "api-flow/engine/core"
+import (
+	"api-flow/engine/core"
+	"context"
+)
 
 // 输入格式定义为空， 前端根据节点类型， 做特殊处理
 var inputNodeInputFormat = core.ParamFormat{}
@@ -39,7 +42,7 @@ func (e *InputNodeExecutor) ValidateConfig(config core.ItemConfig) error {
 }
 
 // Execute 执行文本节点逻辑
-func (e *InputNodeExecutor) Execute(node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+func (e *InputNodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
 
 	var execParams core.ExecuteOutput
 
@@ -57,3 +60,9 @@ func (e *InputNodeExecutor) Execute(node *Node, inputs map[string]interface{}) *
 		Data: execParams,
 	}
 }
+
+// InputNodeParams 读取输入节点配置中定义的入参格式(config.params)
+func InputNodeParams(node *Node) core.ParamFormat {
+	params, _ := configParamFormat(node, "params")
+	return params
+}
@@ -6,6 +6,10 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
 
 	"github.com/jinzhu/gorm"
 )
@@ -56,6 +60,46 @@ func (n *Node) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, n)
 }
 
+// nodeKeySeq 节点键序号, 保证同一进程内批量生成的节点键不重复
+var nodeKeySeq = uint32(rand.Intn(10000))
+
+// GenerateNodeKey 生成节点键, 格式与编辑器保持一致
+func GenerateNodeKey() string {
+	seq := atomic.AddUint32(&nodeKeySeq, 1) % 10000
+	return fmt.Sprintf("node-%d-%d", time.Now().UnixNano()/int64(time.Millisecond), seq)
+}
+
+// configParamFormat 读取节点配置中以 ParamFormat 格式保存的字段
+func configParamFormat(node *Node, key string) (core.ParamFormat, bool) {
+	params := core.ParamFormat{}
+	if node == nil || node.Config == nil {
+		return params, false
+	}
+	raw, ok := node.Config[key]
+	if !ok || raw == nil {
+		return params, false
+	}
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return params, false
+	}
+	if err := json.Unmarshal(bytes, &params); err != nil {
+		return core.ParamFormat{}, false
+	}
+	return params, true
+}
+
+// NodeOutputFormat 节点的输出格式, 优先使用节点配置中声明的 outputFormat, 否则使用节点类型的输出格式
+func NodeOutputFormat(node *Node) core.ParamFormat {
+	if format, ok := configParamFormat(node, "outputFormat"); ok {
+		return format
+	}
+	if nodeType := GetBuiltinNodeType(node.NodeType); nodeType != nil {
+		return nodeType.Output
+	}
+	return core.ParamFormat{}
+}
+
 // MigrateNode 创建节点表
 func MigrateNode(db *gorm.DB) error {
 	return db.AutoMigrate(&Node{}).Error
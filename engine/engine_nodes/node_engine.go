@@ -2,13 +2,15 @@ package engine_nodes
 
 import (
 	"api-flow/engine/core"
+	"api-flow/engine/outbound"
+	"context"
 	"errors"
 	"fmt"
 )
 
-// NodeExecutor 节点执行器接口
+// NodeExecutor 节点执行器接口, ctx 为本次执行的上下文, 携带模拟等执行选项
 type NodeExecutor interface {
-	Execute(node *Node, inputs map[string]interface{}) *core.ExecuteResult
+	Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult
 	ValidateConfig(config core.ItemConfig) error
 	GetOutputFormat() core.ParamFormat
 }
@@ -25,8 +27,13 @@ func NewNodeEngine() *NodeEngine {
 	}
 
 	// 注册默认执行器
+	engine.RegisterExecutor(InputNodeType.Code, NewInputNodeExecutor())
 	engine.RegisterExecutor(ApiNodeType.Code,  NewAPINodeExecutor())
 	engine.RegisterExecutor(TextNodeType.Code, NewTextNodeExecutor())
+	engine.RegisterExecutor(GraphQLNodeType.Code, NewGraphQLNodeExecutor())
+	engine.RegisterExecutor(GrpcNodeType.Code, NewGrpcNodeExecutor())
+	engine.RegisterExecutor(WebSocketNodeType.Code, NewWebSocketNodeExecutor())
+	engine.RegisterExecutor(SSENodeType.Code, NewSSENodeExecutor())
 
 	return engine
 }
@@ -46,7 +53,7 @@ func (e *NodeEngine) GetExecutor(nodeTypeCode string) (NodeExecutor, error) {
 }
 
 // ExecuteNode 执行节点
-func (e *NodeEngine) ExecuteNode(node *Node, inputs map[string]interface{}) (*core.ExecuteResult, error) {
+func (e *NodeEngine) ExecuteNode(ctx context.Context, node *Node, inputs map[string]interface{}) (*core.ExecuteResult, error) {
 	if node == nil {
 		return nil, errors.New("节点不能为空")
 	}
@@ -65,6 +72,11 @@ func (e *NodeEngine) ExecuteNode(node *Node, inputs map[string]interface{}) (*co
 		return nil, fmt.Errorf("节点配置无效: %v", err)
 	}
 
+	// 试运行时会发出网络请求的节点只渲染请求
+	if runner, ok := executor.(DryRunner); ok && outbound.IsDryRun(ctx) {
+		return dryRunResult(ctx, node, runner, inputs), nil
+	}
+
 	// 执行节点
-	return executor.Execute(node, inputs), nil
+	return executor.Execute(ctx, node, inputs), nil
 }
@@ -54,6 +54,31 @@ func (n *NodeType) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, n)
 }
 
+// BuiltinNodeTypes 系统内置的节点类型
+func BuiltinNodeTypes() []*NodeType {
+	return []*NodeType{
+		// 系统节点类型
+		InputNodeType,
+		// 系统自带的任务节点类型
+		ApiNodeType,
+		TextNodeType,
+		GraphQLNodeType,
+		GrpcNodeType,
+		WebSocketNodeType,
+		SSENodeType,
+	}
+}
+
+// GetBuiltinNodeType 根据代码获取内置节点类型
+func GetBuiltinNodeType(code string) *NodeType {
+	for _, nt := range BuiltinNodeTypes() {
+		if nt.Code == code {
+			return nt
+		}
+	}
+	return nil
+}
+
 // MigrateNodeType 创建节点类型表
 func MigrateNodeType(db *gorm.DB) error {
 	err := db.AutoMigrate(&NodeType{}).Error
@@ -61,21 +86,21 @@ func MigrateNodeType(db *gorm.DB) error {
 		return err
 	}
 
-	// 初始化基础节点类型
-	var count int
-	db.Model(&NodeType{}).Count(&count)
-
-	if count == 0 {
-		nodeTypes := []NodeType{
-			// 系统节点类型
-			*InputNodeType,
-			// 系统自带的任务节点类型
-			*ApiNodeType,
-			*TextNodeType,
+	// 初始化内置节点类型: 缺少的类型补充创建, 已存在的同步最新的输入输出格式
+	for _, nt := range BuiltinNodeTypes() {
+		var existing NodeType
+		if db.Unscoped().Where("code = ?", nt.Code).First(&existing).RecordNotFound() {
+			nodeType := *nt
+			if err := db.Create(&nodeType).Error; err != nil {
+				return err
+			}
+			continue
 		}
-
-		for _, nt := range nodeTypes {
-			db.Create(&nt)
+		if err := db.Model(&existing).Updates(map[string]interface{}{
+			"input":  nt.Input,
+			"output": nt.Output,
+		}).Error; err != nil {
+			return err
 		}
 	}
 
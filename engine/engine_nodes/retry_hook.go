@@ -0,0 +1,21 @@
+package engine_nodes
+
+import (
+	"context"
+)
+
+type retryHookKey struct{}
+
+// WithRetryHook 返回的上下文中执行节点时, 每次重试请求前调用 hook, attempt 为即将进行的第几次请求, reason 为上一次请求失败的原因
+func WithRetryHook(ctx context.Context, hook func(attempt int, reason string)) context.Context {
+	return context.WithValue(ctx, retryHookKey{}, hook)
+}
+
+func notifyRetry(ctx context.Context, attempt int, reason string) {
+	if ctx == nil {
+		return
+	}
+	if hook, ok := ctx.Value(retryHookKey{}).(func(int, string)); ok {
+		hook(attempt, reason)
+	}
+}
@@ -0,0 +1,245 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"api-flow/engine/outbound"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var sseNodeInputFormat = append(core.ParamFormat{
+	core.NewParamString("url", "事件流地址", ""),
+	core.NewParamOptions("method", "请求方法", "GET", []interface{}{"GET", "POST"}),
+	core.NewParamObject("headers", "请求头", map[string]interface{}{}),
+	core.NewParamString("body", "请求体(POST 时发送), 对象序列化为JSON", ""),
+	core.NewParamString("lastEventId", "Last-Event-ID, 从指定事件之后继续接收", ""),
+}, streamNodeInputFormat...)
+
+var SSENodeType = &NodeType{
+	Code:        "sse",
+	Name:        "SSE",
+	Description: "订阅Server-Sent Events事件流并收集收到的事件",
+	Category:    "Task",
+	Input:       sseNodeInputFormat,
+	Output:      streamNodeOutputFormat,
+}
+
+// SSENodeExecutor SSE 节点执行器
+type SSENodeExecutor struct {
+	client *http.Client
+}
+
+// NewSSENodeExecutor 创建 SSE 节点执行器实例
+func NewSSENodeExecutor() *SSENodeExecutor {
+	return &SSENodeExecutor{
+		// 与API节点共用出站策略、限流与熔断, 订阅期间占用一个并发配额
+		client: outbound.Client(),
+	}
+}
+
+func (e *SSENodeExecutor) GetOutputFormat() core.ParamFormat {
+	return streamNodeOutputFormat
+}
+
+// ValidateConfig 验证 SSE 节点配置
+func (e *SSENodeExecutor) ValidateConfig(config core.ItemConfig) error {
+	if config == nil {
+		return errors.New("配置不能为空")
+	}
+
+	url, ok := config["url"].(string)
+	if !ok || url == "" {
+		return errors.New("url必须是非空字符串")
+	}
+
+	if method, ok := config["method"].(string); ok && method != "" {
+		method = strings.ToUpper(method)
+		if method != http.MethodGet && method != http.MethodPost {
+			return errors.New("SSE只支持GET与POST方法")
+		}
+	}
+
+	if _, err := parseStreamOptions(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *SSENodeExecutor) newFailExecuteResult(node *Node, msg string) *core.ExecuteResult {
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusError,
+		Data:    nil,
+		Error:   msg,
+	}
+}
+
+// Execute 订阅事件流并收集事件, 直到满足结束条件
+func (e *SSENodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+	config := node.Config
+
+	options, err := parseStreamOptions(config)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	req, err := newSSERequest(ctx, config, inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return e.newFailExecuteResult(node, fmt.Sprintf("执行HTTP请求失败: %v", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		result := e.newFailExecuteResult(node, fmt.Sprintf("订阅失败: HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body))))
+		result.Data = core.ExecuteOutput{"status": resp.StatusCode}
+		return result
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		result := e.newFailExecuteResult(node, fmt.Sprintf("响应不是事件流: Content-Type 为 %s", resp.Header.Get("Content-Type")))
+		result.Data = core.ExecuteOutput{"status": resp.StatusCode}
+		return result
+	}
+
+	collector := newStreamCollector(options)
+	reader := &sseReader{reader: bufio.NewReader(resp.Body)}
+	for {
+		event, err := reader.next()
+		if err != nil {
+			if ctx.Err() != nil {
+				collector.reason = StreamStopTimeout
+				break
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				collector.reason = StreamStopClosed
+				break
+			}
+			return e.newFailExecuteResult(node, fmt.Sprintf("读取事件失败: %v", err))
+		}
+		if collector.add(event) {
+			break
+		}
+	}
+
+	return collector.result(node, resp.StatusCode)
+}
+
+// newSSERequest 根据节点配置与输入渲染订阅请求
+func newSSERequest(ctx context.Context, config map[string]interface{}, inputs map[string]interface{}) (*http.Request, error) {
+	urlTpl, _ := config["url"].(string)
+	url, err := renderTemplate(urlTpl, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("渲染URL模板失败: %v", err)
+	}
+	method, _ := config["method"].(string)
+	if method == "" {
+		method = http.MethodGet
+	}
+	method = strings.ToUpper(method)
+
+	var body []byte
+	contentType := ""
+	if method == http.MethodPost && config["body"] != nil {
+		// 复用 API 节点的请求体处理: 字符串原样发送, 对象序列化为 JSON
+		if body, contentType, err = buildRequestBody(map[string]interface{}{"body": config["body"]}, inputs); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	header, err := renderHeaders(config["headers"], inputs)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID, _ := config["lastEventId"].(string); lastEventID != "" {
+		rendered, err := renderTemplate(lastEventID, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("渲染lastEventId失败: %v", err)
+		}
+		req.Header.Set("Last-Event-ID", rendered)
+	}
+	return req, nil
+}
+
+// sseReader 按 text/event-stream 格式解析事件
+type sseReader struct {
+	reader *bufio.Reader
+	lastID string
+}
+
+// next 读取下一个事件, 输出 {"event", "id", "data"}, data 是 JSON 时解析为对象
+func (r *sseReader) next() (map[string]interface{}, error) {
+	var (
+		eventType string
+		data      []string
+		hasData   bool
+	)
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// 空行结束一个事件, 没有 data 的事件不分发
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return map[string]interface{}{
+				"event": eventType,
+				"id":    r.lastID,
+				"data":  parseStreamMessage([]byte(strings.Join(data, "\n"))),
+			}, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		}
+	}
+}
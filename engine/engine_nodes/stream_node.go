@@ -0,0 +1,141 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api-flow/engine/jsonpath"
+)
+
+// 流式节点(WebSocket、SSE)停止收集消息的原因
+const (
+	StreamStopCount   = "count"   // 达到 maxMessages
+	StreamStopMatch   = "match"   // 收到满足 until 的消息
+	StreamStopTimeout = "timeout" // 达到 timeout
+	StreamStopClosed  = "closed"  // 服务端关闭了连接
+)
+
+// 流式节点的公共输出
+var streamNodeOutputFormat = core.ParamFormat{
+	core.NewParamDefination("messages", core.DataTypeArray, "收到的消息列表, JSON 消息会被解析为对象"),
+	core.NewParamDefination("count", core.DataTypeNumber, "收到的消息数"),
+	core.NewParamDefination("reason", core.DataTypeString, "停止收集的原因: count、match、timeout、closed"),
+	core.NewParamDefination("status", core.DataTypeNumber, "建立连接时的HTTP状态码"),
+}
+
+// 流式节点的公共输入: 结束条件
+var streamNodeInputFormat = core.ParamFormat{
+	core.NewParamNumber("maxMessages", "收到多少条消息后停止, 0 表示不限制", 0),
+	core.NewParamNumber("timeout", "最长收集时间（秒）", 30),
+	core.NewParamObject("until", "收到满足条件的消息后停止: {\"path\": \"$.status\", \"equals\": \"done\"}", map[string]interface{}{}),
+}
+
+// streamOptions 流式节点收集消息的结束条件, 任一条件满足即停止
+type streamOptions struct {
+	maxMessages int
+	timeout     time.Duration
+	until       *bodyAssertion
+}
+
+// parseStreamOptions 解析 maxMessages、timeout 与 until 配置
+func parseStreamOptions(config map[string]interface{}) (*streamOptions, error) {
+	options := &streamOptions{
+		maxMessages: int(configNumber(config, "maxMessages", 0)),
+		timeout:     time.Duration(configNumber(config, "timeout", 30) * float64(time.Second)),
+	}
+	if options.maxMessages < 0 {
+		return nil, fmt.Errorf("maxMessages 不能小于 0")
+	}
+	if options.timeout <= 0 {
+		return nil, fmt.Errorf("timeout 必须大于 0")
+	}
+
+	raw, ok := config["until"]
+	if !ok || raw == nil {
+		return options, nil
+	}
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 0 {
+		return options, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("until配置无效: %v", err)
+	}
+	var until bodyAssertion
+	if err := json.Unmarshal(data, &until); err != nil {
+		return nil, fmt.Errorf("until配置无效: %v", err)
+	}
+	if until.Path == "" {
+		until.Path = "$"
+	}
+	if until.path, err = jsonpath.Compile(until.Path); err != nil {
+		return nil, fmt.Errorf("until: %v", err)
+	}
+	if err := until.Compile(); err != nil {
+		return nil, fmt.Errorf("until: %v", err)
+	}
+	options.until = &until
+	return options, nil
+}
+
+// streamCollector 收集消息并判断是否满足结束条件
+type streamCollector struct {
+	options  *streamOptions
+	messages []interface{}
+	reason   string
+}
+
+func newStreamCollector(options *streamOptions) *streamCollector {
+	return &streamCollector{options: options, messages: make([]interface{}, 0)}
+}
+
+// add 收集一条消息, 满足结束条件时返回 true
+func (c *streamCollector) add(message interface{}) bool {
+	c.messages = append(c.messages, message)
+	if until := c.options.until; until != nil {
+		value, found := until.path.Lookup(message)
+		if until.Match(value, found) == "" {
+			c.reason = StreamStopMatch
+			return true
+		}
+	}
+	if c.options.maxMessages > 0 && len(c.messages) >= c.options.maxMessages {
+		c.reason = StreamStopCount
+		return true
+	}
+	return false
+}
+
+// result 生成执行结果, 配置了 until 但直到超时或连接关闭都没有匹配的消息时节点失败
+func (c *streamCollector) result(node *Node, status int) *core.ExecuteResult {
+	result := &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusSuccess,
+		Data: core.ExecuteOutput{
+			"messages": c.messages,
+			"count":    len(c.messages),
+			"reason":   c.reason,
+			"status":   status,
+		},
+	}
+	if c.options.until != nil && c.reason != StreamStopMatch && c.reason != StreamStopCount {
+		result.Status = core.ExecuteStatusError
+		result.Error = fmt.Sprintf("直到%s都没有收到满足 until 条件的消息", map[string]string{
+			StreamStopTimeout: "超时",
+			StreamStopClosed:  "连接关闭",
+		}[c.reason])
+	}
+	return result
+}
+
+// parseStreamMessage 消息内容是 JSON 时解析为对象, 否则为字符串
+func parseStreamMessage(data []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(data)
+	}
+	return value
+}
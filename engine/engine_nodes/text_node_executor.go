@@ -1,6 +1,9 @@
 package engine_nodes
 
-import "api-flow/engine/core"
+import (
+	"api-flow/engine/core"
+	"context"
+)
 
 var textNodeOutputFormat = core.ParamFormat{
 	core.NewParamDefination("output", core.DataTypeString, "input text content"),
@@ -56,7 +59,7 @@ func (e *TextNodeExecutor) newfailExecuteResult(node *Node, msg string) *core.Ex
 }
 
 // Execute 执行文本节点逻辑
-func (e *TextNodeExecutor) Execute(node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+func (e *TextNodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
 	config := node.Config
 
 	// 获取文本内容
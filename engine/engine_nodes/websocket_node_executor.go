@@ -0,0 +1,203 @@
+package engine_nodes
+
+import (
+	"api-flow/engine/core"
+	"api-flow/engine/outbound"
+	"api-flow/engine/wsclient"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var websocketNodeInputFormat = append(core.ParamFormat{
+	core.NewParamString("url", "WebSocket地址(ws:// 或 wss://)", ""),
+	core.NewParamObject("headers", "握手请求头", map[string]interface{}{}),
+	core.NewParamArray("messages", "连接后依次发送的消息, 字符串原样发送, 对象序列化为JSON", []interface{}{}),
+}, streamNodeInputFormat...)
+
+var WebSocketNodeType = &NodeType{
+	Code:        "websocket",
+	Name:        "WebSocket",
+	Description: "建立WebSocket连接, 发送初始消息并收集收到的消息",
+	Category:    "Task",
+	Input:       websocketNodeInputFormat,
+	Output:      streamNodeOutputFormat,
+}
+
+// WebSocketNodeExecutor WebSocket 节点执行器
+type WebSocketNodeExecutor struct {
+	client *http.Client
+}
+
+// NewWebSocketNodeExecutor 创建 WebSocket 节点执行器实例
+func NewWebSocketNodeExecutor() *WebSocketNodeExecutor {
+	return &WebSocketNodeExecutor{
+		// 握手与API节点共用出站策略、限流与熔断, 连接期间占用一个并发配额
+		client: outbound.Client(),
+	}
+}
+
+func (e *WebSocketNodeExecutor) GetOutputFormat() core.ParamFormat {
+	return streamNodeOutputFormat
+}
+
+// ValidateConfig 验证 WebSocket 节点配置
+func (e *WebSocketNodeExecutor) ValidateConfig(config core.ItemConfig) error {
+	if config == nil {
+		return errors.New("配置不能为空")
+	}
+
+	url, ok := config["url"].(string)
+	if !ok || url == "" {
+		return errors.New("url必须是非空字符串")
+	}
+
+	if messages, ok := config["messages"]; ok && messages != nil {
+		if _, ok := messages.([]interface{}); !ok {
+			return errors.New("messages必须是数组")
+		}
+	}
+
+	if _, err := parseStreamOptions(config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *WebSocketNodeExecutor) newFailExecuteResult(node *Node, msg string) *core.ExecuteResult {
+	return &core.ExecuteResult{
+		NodeID:  node.ID,
+		NodeKey: node.NodeKey,
+		Status:  core.ExecuteStatusError,
+		Data:    nil,
+		Error:   msg,
+	}
+}
+
+// Execute 建立连接并收集消息, 直到满足结束条件
+func (e *WebSocketNodeExecutor) Execute(ctx context.Context, node *Node, inputs map[string]interface{}) *core.ExecuteResult {
+	config := node.Config
+
+	options, err := parseStreamOptions(config)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	urlTpl, _ := config["url"].(string)
+	url, err := renderTemplate(urlTpl, inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, fmt.Sprintf("渲染URL模板失败: %v", err))
+	}
+	header, err := renderHeaders(config["headers"], inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+	messages, err := websocketMessages(config["messages"], inputs)
+	if err != nil {
+		return e.newFailExecuteResult(node, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	conn, resp, err := wsclient.Dial(ctx, e.client, url, header)
+	if err != nil {
+		result := e.newFailExecuteResult(node, err.Error())
+		if resp != nil {
+			result.Data = core.ExecuteOutput{"status": resp.StatusCode}
+		}
+		return result
+	}
+	defer conn.Close()
+
+	// 超时后关闭连接, 使阻塞中的读取返回
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for i, message := range messages {
+		if err := conn.WriteMessage(wsclient.OpText, message); err != nil {
+			return e.newFailExecuteResult(node, fmt.Sprintf("发送第%d条消息失败: %v", i+1, err))
+		}
+	}
+
+	collector := newStreamCollector(options)
+	for {
+		opcode, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				collector.reason = StreamStopTimeout
+				break
+			}
+			var closeErr *wsclient.CloseError
+			if errors.As(err, &closeErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				collector.reason = StreamStopClosed
+				break
+			}
+			return e.newFailExecuteResult(node, fmt.Sprintf("读取消息失败: %v", err))
+		}
+
+		var message interface{}
+		if opcode == wsclient.OpBinary {
+			message = base64.StdEncoding.EncodeToString(data)
+		} else {
+			message = parseStreamMessage(data)
+		}
+		if collector.add(message) {
+			break
+		}
+	}
+
+	return collector.result(node, resp.StatusCode)
+}
+
+// websocketMessages 渲染初始消息, 字符串按模板渲染后原样发送, 对象与数组序列化为 JSON
+func websocketMessages(raw interface{}, inputs map[string]interface{}) ([][]byte, error) {
+	items, _ := raw.([]interface{})
+	messages := make([][]byte, 0, len(items))
+	for i, item := range items {
+		resolved, err := resolveVariable(item, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("渲染第%d条消息失败: %v", i+1, err)
+		}
+		if str, ok := resolved.(string); ok {
+			messages = append(messages, []byte(str))
+			continue
+		}
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("序列化第%d条消息失败: %v", i+1, err)
+		}
+		messages = append(messages, data)
+	}
+	return messages, nil
+}
+
+// renderHeaders 渲染请求头配置中的模板
+func renderHeaders(raw interface{}, inputs map[string]interface{}) (http.Header, error) {
+	header := make(http.Header)
+	headers, ok := raw.(map[string]interface{})
+	if !ok {
+		return header, nil
+	}
+	for key, value := range headers {
+		if strValue, ok := value.(string); ok {
+			renderedValue, err := renderTemplate(strValue, inputs)
+			if err != nil {
+				return nil, fmt.Errorf("渲染请求头 %s 失败: %v", key, err)
+			}
+			header.Set(key, renderedValue)
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine/core"
+)
+
+// 执行事件类型
+const (
+	// EventNodeStarted 节点开始执行
+	EventNodeStarted = "node.started"
+	// EventNodeFinished 节点执行结束, result 为执行结果; 使用原实例结果或调试时跳过的节点只有该事件
+	EventNodeFinished = "node.finished"
+	// EventNodeRetry 节点的请求失败, 即将重试
+	EventNodeRetry = "node.retry"
+	// EventLog 执行过程中的日志, 如输入表达式解析失败
+	EventLog = "log"
+	// EventInstanceFinished 流程实例执行结束, 是事件流的最后一个事件
+	EventInstanceFinished = "instance.finished"
+)
+
+// ExecutionEvent 执行事件
+type ExecutionEvent struct {
+	// Seq 事件序号, 从 1 开始
+	Seq      int       `json:"seq"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	NodeKey  string    `json:"nodeKey,omitempty"`
+	NodeType string    `json:"nodeType,omitempty"`
+	// Attempt 重试时为即将进行的第几次请求, 节点结束时为请求次数
+	Attempt  int                 `json:"attempt,omitempty"`
+	Reused   bool                `json:"reused,omitempty"`
+	Duration int64               `json:"duration,omitempty"` // 毫秒
+	Result   *core.ExecuteResult `json:"result,omitempty"`
+	// Status 流程实例的状态, 仅 instance.finished 事件
+	Status  *core.ExecuteStatus `json:"status,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+type eventStreamKey struct{}
+
+// EventStream 执行事件流: 按发生顺序保存事件, 订阅者可以从任意序号开始读取
+type EventStream struct {
+	mu      sync.Mutex
+	events  []ExecutionEvent
+	closed  bool
+	changed chan struct{}
+}
+
+// NewEventStream 创建事件流
+func NewEventStream() *EventStream {
+	return &EventStream{changed: make(chan struct{})}
+}
+
+// ClosedEventStream 使用已结束实例保存的事件创建事件流, 用于回放
+func ClosedEventStream(events []ExecutionEvent) *EventStream {
+	return &EventStream{events: events, closed: true, changed: make(chan struct{})}
+}
+
+// WithEventStream 返回的上下文中执行工作流时, 执行事件都会写入 stream
+func WithEventStream(ctx context.Context, stream *EventStream) context.Context {
+	return context.WithValue(ctx, eventStreamKey{}, stream)
+}
+
+func eventStreamFrom(ctx context.Context) *EventStream {
+	stream, _ := ctx.Value(eventStreamKey{}).(*EventStream)
+	return stream
+}
+
+// Emit 追加事件, 设置序号与时间; stream 为 nil 或已关闭时忽略
+func (s *EventStream) Emit(event ExecutionEvent) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	event.Seq = len(s.events) + 1
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	s.events = append(s.events, event)
+	s.notify()
+}
+
+// Close 关闭事件流, 之后的事件被忽略
+func (s *EventStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.notify()
+	}
+}
+
+// Events 已发生的全部事件
+func (s *EventStream) Events() []ExecutionEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ExecutionEvent{}, s.events...)
+}
+
+// Wait 返回序号大于 after 的事件, 没有新事件时等待; 事件流关闭且事件已读完或 ctx 结束时 more 为 false
+func (s *EventStream) Wait(ctx context.Context, after int) (events []ExecutionEvent, more bool) {
+	if after < 0 {
+		after = 0
+	}
+	for {
+		s.mu.Lock()
+		if after < len(s.events) {
+			events = append([]ExecutionEvent{}, s.events[after:]...)
+			s.mu.Unlock()
+			return events, true
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return nil, false
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// notify 通知等待新事件的订阅者, 调用时需持有锁
+func (s *EventStream) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// log 写入日志事件
+func (s *EventStream) log(nodeKey, message string) {
+	s.Emit(ExecutionEvent{Type: EventLog, NodeKey: nodeKey, Message: message})
+}
+
+// InstanceEvents 流程实例执行时的事件, 用于回放已结束实例的事件流
+type InstanceEvents struct {
+	core.BasicModel
+	InstanceID uint   `json:"instanceId" gorm:"index"`
+	Events     Events `json:"events" gorm:"type:longtext"`
+}
+
+// TableName 指定表名
+func (InstanceEvents) TableName() string {
+	return "workflow_instance_events"
+}
+
+// Events 以 JSON 保存的事件列表
+type Events []ExecutionEvent
+
+// Value 实现 sql.Valuer 接口
+func (e Events) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(e)
+	return string(bytes), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (e *Events) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return json.Unmarshal(bytes, e)
+}
+
+func MigrateInstanceEvents(db *gorm.DB) error {
+	return db.AutoMigrate(&InstanceEvents{}).Error
+}
@@ -0,0 +1,193 @@
+// Package grpcclient 基于 HTTP/2 的最小 gRPC 客户端, 只支持一元调用, 消息使用动态描述符编解码
+//
+// 请求通过 outbound.HTTP2Client 发送, 与 API 节点共用出站策略、限流与熔断。
+package grpcclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Code gRPC 状态码
+type Code int
+
+// gRPC 状态码, 与 google.golang.org/grpc/codes 一致
+const (
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	InvalidArgument    Code = 3
+	DeadlineExceeded   Code = 4
+	NotFound           Code = 5
+	AlreadyExists      Code = 6
+	PermissionDenied   Code = 7
+	ResourceExhausted  Code = 8
+	FailedPrecondition Code = 9
+	Aborted            Code = 10
+	OutOfRange         Code = 11
+	Unimplemented      Code = 12
+	Internal           Code = 13
+	Unavailable        Code = 14
+	DataLoss           Code = 15
+	Unauthenticated    Code = 16
+)
+
+var codeNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS",
+	"PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE",
+	"UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+func (c Code) String() string {
+	if c >= 0 && int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return "CODE(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Status 调用结果状态, 取自 grpc-status 与 grpc-message
+type Status struct {
+	Code    Code
+	Message string
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("gRPC调用失败: %s %s", s.Code, s.Message)
+}
+
+// Response 一元调用的响应
+type Response struct {
+	Status   *Status
+	Message  []byte // 响应消息, 状态不是 OK 时可能为空
+	Header   http.Header
+	Trailer  http.Header
+	Duration time.Duration
+}
+
+// Client gRPC 客户端
+type Client struct {
+	HTTP *http.Client
+}
+
+// Target 调用目标, Address 为 host:port, TLS 为 false 时使用明文 HTTP/2
+type Target struct {
+	Address string
+	TLS     bool
+}
+
+func (t Target) baseURL() string {
+	scheme := "http"
+	if t.TLS {
+		scheme = "https"
+	}
+	return scheme + "://" + t.Address
+}
+
+// Invoke 调用一元方法, fullMethod 形如 package.Service/Method, message 为编码后的请求消息
+// 只有网络或协议错误时返回 error, 服务端返回的非 OK 状态在 Response.Status 中
+func (c *Client) Invoke(ctx context.Context, target Target, fullMethod string, message []byte, metadata http.Header) (*Response, error) {
+	frame := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(message)))
+	copy(frame[5:], message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.baseURL()+"/"+strings.TrimPrefix(fullMethod, "/"), bytes.NewReader(frame))
+	if err != nil {
+		return nil, fmt.Errorf("创建gRPC请求失败: %v", err)
+	}
+	for key, values := range metadata {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("Grpc-Accept-Encoding", "gzip")
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout := time.Until(deadline); timeout > 0 {
+			req.Header.Set("Grpc-Timeout", strconv.FormatInt(timeout.Milliseconds(), 10)+"m")
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("执行gRPC请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取gRPC响应失败: %v", err)
+	}
+	response := &Response{Header: resp.Header, Trailer: resp.Trailer, Duration: time.Since(start)}
+
+	// 没有响应消息时服务端可能只返回头部(Trailers-Only)
+	response.Status = parseStatus(resp.Trailer)
+	if response.Status == nil {
+		response.Status = parseStatus(resp.Header)
+	}
+	if response.Status == nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("gRPC请求失败: HTTP %d", resp.StatusCode)
+		}
+		return nil, errors.New("gRPC响应缺少 grpc-status")
+	}
+
+	if len(body) > 0 {
+		response.Message, err = readMessage(body, resp.Header.Get("Grpc-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func parseStatus(header http.Header) *Status {
+	value := header.Get("Grpc-Status")
+	if value == "" {
+		return nil
+	}
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		code = int(Unknown)
+	}
+	message := header.Get("Grpc-Message")
+	if unescaped, err := url.PathUnescape(message); err == nil {
+		message = unescaped
+	}
+	return &Status{Code: Code(code), Message: message}
+}
+
+// readMessage 读取响应体中的第一条长度前缀消息
+func readMessage(body []byte, encoding string) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, errors.New("gRPC响应消息不完整")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint64(len(body)-5) < uint64(length) {
+		return nil, errors.New("gRPC响应消息不完整")
+	}
+	message := body[5 : 5+length]
+	if body[0] == 0 {
+		return message, nil
+	}
+	if encoding != "gzip" {
+		return nil, fmt.Errorf("不支持的gRPC压缩方式: %s", encoding)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, fmt.Errorf("解压gRPC响应失败: %v", err)
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
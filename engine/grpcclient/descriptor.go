@@ -0,0 +1,177 @@
+package grpcclient
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Descriptors 解析完成的 proto 描述符, 来自服务端反射或上传的 FileDescriptorSet
+type Descriptors struct {
+	files *protoregistry.Files
+}
+
+// ParseDescriptorSet 解析 FileDescriptorSet(protoc --descriptor_set_out --include_imports 的输出),
+// data 可以是二进制内容或其 base64 编码
+func ParseDescriptorSet(data []byte) (*Descriptors, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil || len(set.File) == 0 {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if decodeErr != nil {
+			return nil, fmt.Errorf("无效的FileDescriptorSet: %v", err)
+		}
+		set = &descriptorpb.FileDescriptorSet{}
+		if err := proto.Unmarshal(decoded, set); err != nil {
+			return nil, fmt.Errorf("无效的FileDescriptorSet: %v", err)
+		}
+	}
+	if len(set.File) == 0 {
+		return nil, fmt.Errorf("FileDescriptorSet中没有文件")
+	}
+	return newDescriptors(set)
+}
+
+// newDescriptors 构建描述符, 缺少的标准类型依赖使用本地描述符补全
+func newDescriptors(set *descriptorpb.FileDescriptorSet) (*Descriptors, error) {
+	names := make(map[string]bool, len(set.File))
+	for _, file := range set.File {
+		names[file.GetName()] = true
+	}
+	for i := 0; i < len(set.File); i++ {
+		for _, dep := range set.File[i].GetDependency() {
+			if names[dep] {
+				continue
+			}
+			local, err := protoregistry.GlobalFiles.FindFileByPath(dep)
+			if err != nil {
+				return nil, fmt.Errorf("缺少依赖 %s, 生成时请使用 --include_imports", dep)
+			}
+			names[dep] = true
+			set.File = append(set.File, protodesc.ToFileDescriptorProto(local))
+		}
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("构建描述符失败: %v", err)
+	}
+	return &Descriptors{files: files}, nil
+}
+
+// Method 查找方法, service 为包含包名的服务全名
+func (d *Descriptors) Method(service, method string) (protoreflect.MethodDescriptor, error) {
+	desc, err := d.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("未找到服务 %s", service)
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s 不是服务", service)
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("服务 %s 中未找到方法 %s", service, method)
+	}
+	return methodDesc, nil
+}
+
+// ServiceInfo 服务及其方法
+type ServiceInfo struct {
+	Name    string       `json:"name"`
+	Methods []MethodInfo `json:"methods"`
+}
+
+// MethodInfo 方法信息, Example 为请求消息的 JSON 示例
+type MethodInfo struct {
+	Name            string      `json:"name"`
+	FullMethod      string      `json:"fullMethod"`
+	Input           string      `json:"input"`
+	Output          string      `json:"output"`
+	ClientStreaming bool        `json:"clientStreaming"`
+	ServerStreaming bool        `json:"serverStreaming"`
+	Example         interface{} `json:"example"`
+}
+
+// Services 列出描述符中的全部服务, 按名称排序, 不包含反射服务本身
+func (d *Descriptors) Services() []ServiceInfo {
+	services := make([]ServiceInfo, 0)
+	d.files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+			if strings.HasPrefix(string(service.FullName()), "grpc.reflection.") {
+				continue
+			}
+			info := ServiceInfo{Name: string(service.FullName()), Methods: make([]MethodInfo, 0, service.Methods().Len())}
+			for j := 0; j < service.Methods().Len(); j++ {
+				method := service.Methods().Get(j)
+				info.Methods = append(info.Methods, MethodInfo{
+					Name:            string(method.Name()),
+					FullMethod:      fmt.Sprintf("%s/%s", service.FullName(), method.Name()),
+					Input:           string(method.Input().FullName()),
+					Output:          string(method.Output().FullName()),
+					ClientStreaming: method.IsStreamingClient(),
+					ServerStreaming: method.IsStreamingServer(),
+					Example:         MessageExample(method.Input(), 0),
+				})
+			}
+			services = append(services, info)
+		}
+		return true
+	})
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// 示例中嵌套消息的最大深度, 防止递归消息无限展开
+const maxExampleDepth = 3
+
+// MessageExample 生成消息的 JSON 示例, 字段名使用 protojson 的 lowerCamelCase 写法
+func MessageExample(message protoreflect.MessageDescriptor, depth int) interface{} {
+	example := make(map[string]interface{})
+	if depth >= maxExampleDepth {
+		return example
+	}
+	for i := 0; i < message.Fields().Len(); i++ {
+		field := message.Fields().Get(i)
+		switch {
+		case field.IsMap():
+			example[field.JSONName()] = map[string]interface{}{}
+		case field.IsList():
+			example[field.JSONName()] = []interface{}{fieldExample(field, depth)}
+		default:
+			example[field.JSONName()] = fieldExample(field, depth)
+		}
+	}
+	return example
+}
+
+func fieldExample(field protoreflect.FieldDescriptor, depth int) interface{} {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return false
+	case protoreflect.StringKind:
+		return ""
+	case protoreflect.BytesKind:
+		return ""
+	case protoreflect.EnumKind:
+		if values := field.Enum().Values(); values.Len() > 0 {
+			return string(values.Get(0).Name())
+		}
+		return ""
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson 中 64 位整数为字符串
+		return "0"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return MessageExample(field.Message(), depth+1)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,209 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// 服务端反射服务, 优先使用 v1, 服务端未实现时回退到 v1alpha
+var reflectionMethods = []string{
+	"grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// ServerReflectionRequest 与 ServerReflectionResponse 的字段编号
+const (
+	reqFileByFilename       = 3
+	reqFileContainingSymbol = 4
+	reqListServices         = 7
+
+	respFileDescriptor = 4
+	respListServices   = 6
+	respError          = 7
+)
+
+// reflectionResponse 解码后的 ServerReflectionResponse
+type reflectionResponse struct {
+	files    [][]byte
+	services []string
+	err      *Status
+}
+
+// reflect 发送一条反射请求, 每条请求使用独立的流
+func (c *Client) reflect(ctx context.Context, target Target, field protowire.Number, value string) (*reflectionResponse, error) {
+	var request []byte
+	request = protowire.AppendTag(request, field, protowire.BytesType)
+	request = protowire.AppendString(request, value)
+
+	var lastErr error
+	for _, method := range reflectionMethods {
+		resp, err := c.Invoke(ctx, target, method, request, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Status.Code == Unimplemented {
+			lastErr = errors.New("服务端未启用反射(grpc.reflection)")
+			continue
+		}
+		if resp.Status.Code != OK {
+			return nil, resp.Status
+		}
+		result, err := decodeReflectionResponse(resp.Message)
+		if err != nil {
+			return nil, err
+		}
+		if result.err != nil {
+			return nil, fmt.Errorf("反射请求失败: %s", result.err.Message)
+		}
+		return result, nil
+	}
+	return nil, lastErr
+}
+
+func decodeReflectionResponse(data []byte) (*reflectionResponse, error) {
+	result := &reflectionResponse{}
+	err := eachField(data, func(num protowire.Number, value []byte) error {
+		switch num {
+		case respFileDescriptor:
+			return eachField(value, func(num protowire.Number, file []byte) error {
+				if num == 1 {
+					result.files = append(result.files, file)
+				}
+				return nil
+			})
+		case respListServices:
+			return eachField(value, func(num protowire.Number, service []byte) error {
+				if num != 1 {
+					return nil
+				}
+				return eachField(service, func(num protowire.Number, name []byte) error {
+					if num == 1 {
+						result.services = append(result.services, string(name))
+					}
+					return nil
+				})
+			})
+		case respError:
+			result.err = &Status{Code: Unknown}
+			return eachField(value, func(num protowire.Number, message []byte) error {
+				if num == 2 {
+					result.err.Message = string(message)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析反射响应失败: %v", err)
+	}
+	return result, nil
+}
+
+// eachField 遍历消息中长度分隔类型的字段(字符串、字节、子消息), 跳过其他字段
+func eachField(data []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := fn(num, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// ListServices 通过反射列出服务端的全部服务
+func (c *Client) ListServices(ctx context.Context, target Target) ([]string, error) {
+	result, err := c.reflect(ctx, target, reqListServices, "*")
+	if err != nil {
+		return nil, err
+	}
+	return result.services, nil
+}
+
+// Reflect 通过反射获取包含指定符号(服务名)的描述符文件及其全部依赖
+func (c *Client) Reflect(ctx context.Context, target Target, symbols ...string) (*Descriptors, error) {
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	add := func(result *reflectionResponse) error {
+		for _, data := range result.files {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return fmt.Errorf("解析描述符失败: %v", err)
+			}
+			files[file.GetName()] = file
+		}
+		return nil
+	}
+
+	for _, symbol := range symbols {
+		result, err := c.reflect(ctx, target, reqFileContainingSymbol, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 的描述符失败: %v", symbol, err)
+		}
+		if err := add(result); err != nil {
+			return nil, err
+		}
+	}
+
+	// 补全服务端没有一并返回的依赖, 内置的标准类型(google/protobuf/*.proto)直接使用本地描述符
+	for {
+		missing := ""
+		for _, file := range files {
+			for _, dep := range file.GetDependency() {
+				if _, ok := files[dep]; !ok {
+					missing = dep
+					break
+				}
+			}
+			if missing != "" {
+				break
+			}
+		}
+		if missing == "" {
+			break
+		}
+		if local, err := protoregistry.GlobalFiles.FindFileByPath(missing); err == nil {
+			files[missing] = protodesc.ToFileDescriptorProto(local)
+			continue
+		}
+		result, err := c.reflect(ctx, target, reqFileByFilename, missing)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 失败: %v", missing, err)
+		}
+		if err := add(result); err != nil {
+			return nil, err
+		}
+		if _, ok := files[missing]; !ok {
+			return nil, fmt.Errorf("服务端没有返回 %s", missing)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	return newDescriptors(set)
+}
@@ -0,0 +1,224 @@
+// Package jsonpath 实现常用的 JSONPath 子集, 用于从节点输出(JSON 解码后的数据)中取值
+//
+// 支持: $ 根节点、.name 与 ['name'] 属性、[0] 与 [-1] 下标、[*] 与 .* 通配、..name 递归查找。
+// 省略开头的 $ 时按 $. 处理, 如 data.items[0].id。
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentField segmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentRecursive
+)
+
+type segment struct {
+	kind  segmentKind
+	field string
+	index int
+}
+
+// Path 编译后的 JSONPath
+type Path struct {
+	raw      string
+	segments []segment
+	definite bool
+}
+
+// Compile 编译 JSONPath 表达式
+func Compile(expr string) (*Path, error) {
+	raw := expr
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("JSONPath不能为空")
+	}
+	switch {
+	case expr == "$":
+		expr = ""
+	case strings.HasPrefix(expr, "$"):
+		expr = expr[1:]
+	case strings.HasPrefix(expr, "["):
+	default:
+		expr = "." + expr
+	}
+
+	path := &Path{raw: raw, definite: true}
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			if strings.HasPrefix(expr[i:], "..") {
+				i += 2
+				name, next := readName(expr, i)
+				if name == "" {
+					return nil, fmt.Errorf("JSONPath %s 无效: .. 后缺少属性名", raw)
+				}
+				path.segments = append(path.segments, segment{kind: segmentRecursive, field: name})
+				path.definite = false
+				i = next
+				continue
+			}
+			i++
+			name, next := readName(expr, i)
+			if name == "" {
+				return nil, fmt.Errorf("JSONPath %s 无效: . 后缺少属性名", raw)
+			}
+			if name == "*" {
+				path.segments = append(path.segments, segment{kind: segmentWildcard})
+				path.definite = false
+			} else {
+				path.segments = append(path.segments, segment{kind: segmentField, field: name})
+			}
+			i = next
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %s 无效: [ 未闭合", raw)
+			}
+			content := strings.TrimSpace(expr[i+1 : i+end])
+			i += end + 1
+			switch {
+			case content == "*":
+				path.segments = append(path.segments, segment{kind: segmentWildcard})
+				path.definite = false
+			case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+				path.segments = append(path.segments, segment{kind: segmentField, field: content[1 : len(content)-1]})
+			default:
+				index, err := strconv.Atoi(content)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %s 无效: 不支持的下标 [%s]", raw, content)
+				}
+				path.segments = append(path.segments, segment{kind: segmentIndex, index: index})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %s 无效: 位置 %d 处的字符 %q", raw, i, expr[i])
+		}
+	}
+	return path, nil
+}
+
+func readName(expr string, start int) (string, int) {
+	end := start
+	for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+		end++
+	}
+	return expr[start:end], end
+}
+
+// String 原始表达式
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Definite 表达式是否最多只匹配一个值(不含通配与递归查找)
+func (p *Path) Definite() bool {
+	return p.definite
+}
+
+// Query 返回所有匹配的值
+func (p *Path) Query(data interface{}) []interface{} {
+	current := []interface{}{data}
+	for _, seg := range p.segments {
+		next := make([]interface{}, 0)
+		for _, value := range current {
+			next = append(next, seg.apply(value)...)
+		}
+		current = next
+		if len(current) == 0 {
+			break
+		}
+	}
+	return current
+}
+
+// Lookup 取值: 确定路径返回唯一匹配的值, 否则返回匹配值组成的数组; 没有匹配时 ok 为 false
+func (p *Path) Lookup(data interface{}) (value interface{}, ok bool) {
+	values := p.Query(data)
+	if p.definite {
+		if len(values) == 0 {
+			return nil, false
+		}
+		return values[0], true
+	}
+	return values, len(values) > 0
+}
+
+func (s segment) apply(value interface{}) []interface{} {
+	switch s.kind {
+	case segmentField:
+		if m, ok := value.(map[string]interface{}); ok {
+			if v, ok := m[s.field]; ok {
+				return []interface{}{v}
+			}
+		}
+	case segmentIndex:
+		if arr, ok := value.([]interface{}); ok {
+			index := s.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}
+			}
+		}
+	case segmentWildcard:
+		switch v := value.(type) {
+		case map[string]interface{}:
+			result := make([]interface{}, 0, len(v))
+			for _, key := range sortedKeys(v) {
+				result = append(result, v[key])
+			}
+			return result
+		case []interface{}:
+			return v
+		}
+	case segmentRecursive:
+		result := make([]interface{}, 0)
+		collect(value, s.field, &result)
+		return result
+	}
+	return nil
+}
+
+// collect 递归查找所有名为 field 的属性
+func collect(value interface{}, field string, result *[]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if found, ok := v[field]; ok {
+			*result = append(*result, found)
+		}
+		for _, key := range sortedKeys(v) {
+			collect(v[key], field, result)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collect(item, field, result)
+		}
+	}
+}
+
+// Lookup 编译并取值, 见 Path.Lookup
+func Lookup(data interface{}, expr string) (interface{}, bool, error) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := path.Lookup(data)
+	return value, ok, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,374 @@
+// Package jsonschema 按 JSON Schema(draft-07 常用关键字)校验 JSON 解码后的数据
+//
+// 支持: type、enum、const、properties、required、additionalProperties、items、
+// minItems、maxItems、uniqueItems、minLength、maxLength、pattern、minimum、maximum、
+// exclusiveMinimum、exclusiveMaximum、multipleOf、allOf、anyOf、oneOf、not,
+// 以及指向 #/definitions 或 #/$defs 的本地 $ref。不认识的关键字会被忽略。
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error 单条校验错误, Path 为出错位置的 JSONPath
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Validate 校验数据, 返回全部校验错误
+func Validate(schema interface{}, data interface{}) []Error {
+	v := &validator{root: schema}
+	v.validate(schema, normalize(data), "$")
+	return v.errors
+}
+
+type validator struct {
+	root   interface{}
+	errors []Error
+	depth  int
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check 在独立的校验器中校验, 用于 anyOf/oneOf/not 等组合关键字
+func (v *validator) check(schema, data interface{}, path string) []Error {
+	sub := &validator{root: v.root, depth: v.depth}
+	sub.validate(schema, data, path)
+	return sub.errors
+}
+
+func (v *validator) validate(schema interface{}, data interface{}, path string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "不允许出现该值")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObject(s, data, path)
+	}
+}
+
+func (v *validator) validateObject(schema map[string]interface{}, data interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		v.depth++
+		defer func() { v.depth-- }()
+		if v.depth > 64 {
+			v.fail(path, "$ref 嵌套过深")
+			return
+		}
+		v.validate(target, data, path)
+		return
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, data) {
+		v.fail(path, "类型应为 %s, 实际为 %s", typeNames(t), typeOf(data))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if reflect.DeepEqual(normalize(item), data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "值 %s 不在允许的枚举值中", display(data))
+		}
+	}
+	if constValue, ok := schema["const"]; ok && !reflect.DeepEqual(normalize(constValue), data) {
+		v.fail(path, "值应为 %s, 实际为 %s", display(constValue), display(data))
+	}
+
+	switch value := data.(type) {
+	case map[string]interface{}:
+		v.validateProperties(schema, value, path)
+	case []interface{}:
+		v.validateItems(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case float64:
+		v.validateNumber(schema, value, path)
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			v.validate(sub, data, path)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if len(v.check(sub, data, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "不满足 anyOf 中的任何一个 schema")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if len(v.check(sub, data, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "应恰好满足 oneOf 中的一个 schema, 实际满足 %d 个", matched)
+		}
+	}
+	if not, ok := schema["not"]; ok && len(v.check(not, data, path)) == 0 {
+		v.fail(path, "不应满足 not 中的 schema")
+	}
+}
+
+func (v *validator) validateProperties(schema map[string]interface{}, value map[string]interface{}, path string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, exists := value[key]; !exists {
+					v.fail(path, "缺少必填属性 %s", key)
+				}
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, key := range sortedKeys(value) {
+		childPath := propertyPath(path, key)
+		if sub, ok := properties[key]; ok {
+			v.validate(sub, value[key], childPath)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(path, "不允许的属性 %s", key)
+			}
+		case map[string]interface{}:
+			v.validate(additional, value[key], childPath)
+		}
+	}
+}
+
+func (v *validator) validateItems(schema map[string]interface{}, value []interface{}, path string) {
+	if min, ok := number(schema["minItems"]); ok && float64(len(value)) < min {
+		v.fail(path, "元素数量 %d 小于 %v", len(value), min)
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(value)) > max {
+		v.fail(path, "元素数量 %d 大于 %v", len(value), max)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 0; i < len(value); i++ {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.fail(path, "元素 %d 与 %d 重复", i, j)
+				}
+			}
+		}
+	}
+	switch items := schema["items"].(type) {
+	case map[string]interface{}, bool:
+		for i, item := range value {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case []interface{}:
+		// 元组形式
+		for i, item := range value {
+			if i < len(items) {
+				v.validate(items[i], item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(schema map[string]interface{}, value string, path string) {
+	length := utf8.RuneCountInString(value)
+	if min, ok := number(schema["minLength"]); ok && float64(length) < min {
+		v.fail(path, "长度 %d 小于 %v", length, min)
+	}
+	if max, ok := number(schema["maxLength"]); ok && float64(length) > max {
+		v.fail(path, "长度 %d 大于 %v", length, max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "无效的 pattern %s: %v", pattern, err)
+		} else if !re.MatchString(value) {
+			v.fail(path, "值 %q 不匹配 %s", value, pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(schema map[string]interface{}, value float64, path string) {
+	if min, ok := number(schema["minimum"]); ok && value < min {
+		v.fail(path, "值 %v 小于最小值 %v", value, min)
+	}
+	if max, ok := number(schema["maximum"]); ok && value > max {
+		v.fail(path, "值 %v 大于最大值 %v", value, max)
+	}
+	if min, ok := number(schema["exclusiveMinimum"]); ok && value <= min {
+		v.fail(path, "值 %v 应大于 %v", value, min)
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && value >= max {
+		v.fail(path, "值 %v 应小于 %v", value, max)
+	}
+	if multiple, ok := number(schema["multipleOf"]); ok && multiple > 0 {
+		if quotient := value / multiple; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "值 %v 不是 %v 的倍数", value, multiple)
+		}
+	}
+}
+
+// resolve 解析本地 $ref, 如 #/definitions/User
+func (v *validator) resolve(ref string) (interface{}, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("不支持的 $ref: %s", ref)
+	}
+	current := v.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %s 不存在", ref)
+		}
+		if current, ok = m[part]; !ok {
+			return nil, fmt.Errorf("$ref %s 不存在", ref)
+		}
+	}
+	return current, nil
+}
+
+func matchesType(t interface{}, data interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return matchesTypeName(t, data)
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && matchesTypeName(s, data) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesTypeName(name string, data interface{}) bool {
+	actual := typeOf(data)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func typeOf(data interface{}) string {
+	switch value := data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+func typeNames(t interface{}) string {
+	if names, ok := t.([]interface{}); ok {
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprint(name))
+		}
+		return strings.Join(parts, "|")
+	}
+	return fmt.Sprint(t)
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// normalize 通过 JSON 编解码统一数值等类型, 便于比较
+func normalize(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, bool, string, float64:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+func display(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyPath(path, key string) string {
+	if identifier.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuites JUnit XML 格式, 供 CI 展示测试结果
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSeconds(millis int64) string {
+	return fmt.Sprintf("%.3f", float64(millis)/1000)
+}
+
+// JUnitXML 将测试报告转换为 JUnit XML, 每个用例对应一个 testcase, 失败的断言写入 failure
+func (r *TestReport) JUnitXML() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:     r.WorkflowName,
+		Tests:    r.Total,
+		Failures: r.Failed,
+		Time:     junitSeconds(r.Duration),
+	}
+	for _, result := range r.Cases {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: r.WorkflowName,
+			Time:      junitSeconds(result.Duration),
+		}
+		if !result.Passed {
+			lines := make([]string, 0)
+			if result.Error != "" {
+				lines = append(lines, result.Error)
+			}
+			for _, assertion := range result.Assertions {
+				if !assertion.Passed {
+					lines = append(lines, assertion.Message)
+				}
+			}
+			message := "测试用例未通过"
+			if len(lines) > 0 {
+				message = strings.SplitN(lines[0], "\n", 2)[0]
+			}
+			testCase.Failure = &junitFailure{Message: message, Text: strings.Join(lines, "\n")}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{
+		Tests:    r.Total,
+		Failures: r.Failed,
+		Time:     junitSeconds(r.Duration),
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/models"
+)
+
+// NodeExecution 流程实例中单个节点的执行记录
+type NodeExecution struct {
+	core.BasicModel
+	InstanceID uint               `json:"instanceId" gorm:"index"`
+	WorkflowID uint               `json:"workflowId" gorm:"index"`
+	NodeID     string             `json:"nodeId"`
+	NodeKey    string             `json:"nodeKey" gorm:"index"`
+	NodeType   string             `json:"nodeType"`
+	Status     core.ExecuteStatus `json:"status"`
+	Attempt    int                `json:"attempt" gorm:"comment:'请求次数(含重试), 未执行的节点为0'"`
+	Reused     bool               `json:"reused" gorm:"comment:'是否使用了原实例的结果'"`
+	StartTime  time.Time          `json:"startTime"`
+	EndTime    time.Time          `json:"endTime"`
+	Duration   int64              `json:"duration" gorm:"comment:'执行时间(ms)'"`
+	Inputs     models.Record      `json:"inputs" gorm:"type:longtext"` // 解析表达式后的输入参数
+	Output     models.Record      `json:"output" gorm:"type:longtext"`
+	Error      string             `json:"error" gorm:"type:text"`
+}
+
+// Result 转换为节点执行结果
+func (e *NodeExecution) Result() core.ExecuteResult {
+	return core.ExecuteResult{
+		NodeID:  e.NodeID,
+		NodeKey: e.NodeKey,
+		Status:  e.Status,
+		Data:    core.ExecuteOutput(e.Output),
+		Error:   e.Error,
+	}
+}
+
+func MigrateNodeExecution(db *gorm.DB) error {
+	return db.AutoMigrate(&NodeExecution{}).Error
+}
+
+type executionLogKey struct{}
+
+// ExecutionLog 按执行顺序收集执行工作流时每个节点的执行记录
+type ExecutionLog struct {
+	mu         sync.Mutex
+	executions []NodeExecution
+}
+
+// NewExecutionLog 创建执行记录
+func NewExecutionLog() *ExecutionLog {
+	return &ExecutionLog{}
+}
+
+// WithExecutionLog 返回的上下文中执行工作流时, 每个节点的执行记录都会写入 executionLog
+func WithExecutionLog(ctx context.Context, executionLog *ExecutionLog) context.Context {
+	return context.WithValue(ctx, executionLogKey{}, executionLog)
+}
+
+func executionLogFrom(ctx context.Context) *ExecutionLog {
+	executionLog, _ := ctx.Value(executionLogKey{}).(*ExecutionLog)
+	return executionLog
+}
+
+// Executions 已记录的节点执行记录
+func (l *ExecutionLog) Executions() []NodeExecution {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]NodeExecution{}, l.executions...)
+}
+
+func (l *ExecutionLog) add(execution NodeExecution) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.executions = append(l.executions, execution)
+}
+
+// skipped 记录未执行的节点(使用原实例结果或调试时跳过)
+func (l *ExecutionLog) skipped(node *engine_nodes.Node, result *core.ExecuteResult, reused bool) {
+	now := time.Now()
+	l.add(NodeExecution{
+		NodeID:    node.ID,
+		NodeKey:   node.NodeKey,
+		NodeType:  node.NodeType,
+		Status:    result.Status,
+		Reused:    reused,
+		StartTime: now,
+		EndTime:   now,
+		Output:    models.Record(result.Data),
+		Error:     result.Error,
+	})
+}
@@ -0,0 +1,133 @@
+package outbound
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// breaker 熔断器: 连续失败达到阈值后打开, 冷却时间后进入半开状态放行一个探测请求,
+// 探测成功则关闭, 失败则重新打开
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	trips     int
+	lastError string
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, state: BreakerClosed}
+}
+
+// allow 判断是否放行请求, 拒绝时返回剩余的冷却时间
+func (b *breaker) allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.cooldown - time.Since(b.openedAt)
+		if remaining > 0 {
+			return false, remaining
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true, 0
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, 0
+		}
+		b.probing = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// record 记录请求结果
+func (b *breaker) record(success bool, errMessage string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	b.lastError = errMessage
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		if b.state != BreakerOpen {
+			b.trips++
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// reset 手动关闭熔断器
+func (b *breaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// BreakerStatus 熔断器状态, 用于接口展示
+type BreakerStatus struct {
+	Host      string       `json:"host"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`
+	Trips     int          `json:"trips"`
+	OpenedAt  *time.Time   `json:"openedAt,omitempty"`
+	RetryAt   *time.Time   `json:"retryAt,omitempty"`
+	LastError string       `json:"lastError,omitempty"`
+}
+
+func (b *breaker) status(host string) BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Host:      host,
+		State:     b.state,
+		Failures:  b.failures,
+		Trips:     b.trips,
+		LastError: b.lastError,
+	}
+	// 冷却结束但还没有请求时, 展示为半开
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		status.State = BreakerHalfOpen
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.cooldown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// cancel 放行的请求未实际发出(如等待限流时超时), 不计入结果
+func (b *breaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,107 @@
+// Package outbound 节点发出的 HTTP 请求的公共治理: 出站网络策略, 按目标主机限流、限制并发与熔断
+//
+// 所有 API 类节点共用同一个 Transport, 限流器与熔断器状态在全部并发执行的流程实例间共享。
+package outbound
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// Config 出站请求配置, 对应配置文件中的 outbound 段
+type Config struct {
+	// Defaults 未匹配到 Hosts 时使用的默认配置
+	Defaults HostConfig `yaml:"defaults" json:"defaults"`
+	// Hosts 按主机名配置, 支持通配符, 如 *.example.com
+	Hosts map[string]HostConfig `yaml:"hosts" json:"hosts"`
+	// Policy 出站网络策略
+	Policy Policy `yaml:"policy" json:"policy"`
+}
+
+// HostConfig 单个主机的限流与熔断配置, 未配置(nil)的字段使用默认配置,
+// 主机配置中显式设置为 0 可以关闭默认配置启用的限制
+type HostConfig struct {
+	// RateLimit 每秒允许的请求数, 0 表示不限制
+	RateLimit *float64 `yaml:"rateLimit" json:"rateLimit"`
+	// Burst 令牌桶容量, 默认与 RateLimit 相同
+	Burst *int `yaml:"burst" json:"burst"`
+	// MaxConcurrent 最大并发请求数, 0 表示不限制
+	MaxConcurrent *int `yaml:"maxConcurrent" json:"maxConcurrent"`
+	// FailureThreshold 连续失败多少次后熔断, 0 表示不熔断
+	FailureThreshold *int `yaml:"failureThreshold" json:"failureThreshold"`
+	// Cooldown 熔断后多少秒进入半开状态
+	Cooldown *int `yaml:"cooldown" json:"cooldown"`
+}
+
+func (c HostConfig) merge(override HostConfig) HostConfig {
+	if override.RateLimit != nil {
+		c.RateLimit = override.RateLimit
+	}
+	if override.Burst != nil {
+		c.Burst = override.Burst
+	}
+	if override.MaxConcurrent != nil {
+		c.MaxConcurrent = override.MaxConcurrent
+	}
+	if override.FailureThreshold != nil {
+		c.FailureThreshold = override.FailureThreshold
+	}
+	if override.Cooldown != nil {
+		c.Cooldown = override.Cooldown
+	}
+	return c
+}
+
+func (c HostConfig) rateLimit() float64 {
+	if c.RateLimit == nil {
+		return 0
+	}
+	return *c.RateLimit
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func (c HostConfig) cooldown() time.Duration {
+	if intValue(c.Cooldown) <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(*c.Cooldown) * time.Second
+}
+
+// hostConfig 获取主机的配置, 精确匹配优先, 其次是最长的通配符模式
+func (c Config) hostConfig(host string) HostConfig {
+	host = strings.ToLower(host)
+	if override, ok := c.Hosts[host]; ok {
+		return c.Defaults.merge(override)
+	}
+	best := ""
+	for pattern := range c.Hosts {
+		if MatchHost(pattern, host) && len(pattern) > len(best) {
+			best = pattern
+		}
+	}
+	if best != "" {
+		return c.Defaults.merge(c.Hosts[best])
+	}
+	return c.Defaults
+}
+
+// MatchHost 判断主机名是否匹配模式, 模式支持 * 通配, *.example.com 同时匹配 example.com
+func MatchHost(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	host = strings.ToLower(host)
+	if pattern == host {
+		return true
+	}
+	if strings.HasPrefix(pattern, "*.") && host == pattern[2:] {
+		return true
+	}
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
@@ -0,0 +1,22 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDryRun 试运行时不发送请求
+var ErrDryRun = errors.New("试运行时不发送请求")
+
+type dryRunKey struct{}
+
+// WithDryRun 返回的上下文中执行节点时只渲染请求, 发出的请求都会失败
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun 上下文是否处于试运行模式
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,80 @@
+package outbound
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// http2Transport 只使用 HTTP/2 的 Transport, http 目标使用明文 HTTP/2(h2c), 供 gRPC 调用使用
+type http2Transport struct {
+	plain  *http2.Transport
+	secure *http2.Transport
+}
+
+func (t *http2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		return t.secure.RoundTrip(req)
+	}
+	return t.plain.RoundTrip(req)
+}
+
+// http2BaseTransport 与 baseTransport 一样在连接建立前按出站策略检查解析出的 IP
+func (m *Manager) http2BaseTransport() http.RoundTripper {
+	m.http2Once.Do(func() {
+		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+			d.Control = m.currentPolicy().dialControl(host)
+			return d.DialContext(ctx, network, addr)
+		}
+		m.http2Base = &http2Transport{
+			plain: &http2.Transport{
+				AllowHTTP: true,
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return dial(ctx, network, addr)
+				},
+			},
+			secure: &http2.Transport{
+				DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+					conn, err := dial(ctx, network, addr)
+					if err != nil {
+						return nil, err
+					}
+					tlsConn := tls.Client(conn, cfg)
+					if err := tlsConn.HandshakeContext(ctx); err != nil {
+						conn.Close()
+						return nil, err
+					}
+					return tlsConn, nil
+				},
+			},
+		}
+	})
+	return m.http2Base
+}
+
+var (
+	http2ClientOnce sync.Once
+	http2Client     *http.Client
+)
+
+// HTTP2Client 节点发出 gRPC 请求使用的共享 http.Client, 与 Client 共用出站策略、限流与熔断状态
+func HTTP2Client() *http.Client {
+	http2ClientOnce.Do(func() {
+		http2Client = &http.Client{
+			Transport: &Transport{Manager: defaultManager, Base: defaultManager.http2BaseTransport()},
+			// gRPC 不使用重定向
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	})
+	return http2Client
+}
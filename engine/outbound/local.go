@@ -0,0 +1,43 @@
+package outbound
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+)
+
+type localHandlerKey struct{}
+
+// WithHandler 返回的上下文中发出的请求不经过网络, 直接交给 handler 处理(如模拟接口),
+// 这些请求不受出站策略、限流与熔断的限制
+func WithHandler(ctx context.Context, handler http.Handler) context.Context {
+	return context.WithValue(ctx, localHandlerKey{}, handler)
+}
+
+func localHandler(ctx context.Context) http.Handler {
+	handler, _ := ctx.Value(localHandlerKey{}).(http.Handler)
+	return handler
+}
+
+// serveLocal 在进程内由 handler 处理请求, 请求路径与查询参数保持不变
+func serveLocal(handler http.Handler, req *http.Request) (*http.Response, error) {
+	serverReq := req.Clone(req.Context())
+	serverReq.RequestURI = req.URL.RequestURI()
+	serverReq.RemoteAddr = "127.0.0.1:0"
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+	if serverReq.Host == "" {
+		serverReq.Host = req.URL.Host
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, serverReq)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
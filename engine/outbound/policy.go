@@ -0,0 +1,273 @@
+package outbound
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// 默认最大重定向次数, 与 net/http 一致
+const defaultMaxRedirects = 10
+
+// Policy 出站网络策略, 防止通过节点访问内网地址(SSRF)
+type Policy struct {
+	// AllowHosts 允许访问的主机名模式或 IP/CIDR, 为空时允许所有未被拒绝的主机;
+	// 内网地址只能通过 IP/CIDR 规则放行, 主机名规则不会绕过 BlockPrivate
+	AllowHosts []string `yaml:"allowHosts" json:"allowHosts"`
+	// DenyHosts 拒绝访问的主机名模式或 IP/CIDR, 优先于 AllowHosts
+	DenyHosts []string `yaml:"denyHosts" json:"denyHosts"`
+	// BlockPrivate 是否拒绝私有、回环、链路本地等地址(在 DNS 解析后按实际连接的 IP 检查)
+	BlockPrivate bool `yaml:"blockPrivate" json:"blockPrivate"`
+	// AllowSchemes 允许的协议, 默认 http 与 https
+	AllowSchemes []string `yaml:"allowSchemes" json:"allowSchemes"`
+	// AllowPorts 允许的端口, 为空时不限制
+	AllowPorts []int `yaml:"allowPorts" json:"allowPorts"`
+	// MaxRedirects 最大重定向次数, 默认 10, 每次重定向都会重新检查策略
+	MaxRedirects *int `yaml:"maxRedirects" json:"maxRedirects"`
+}
+
+// PolicyError 请求被出站策略拒绝
+type PolicyError struct {
+	Target string
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("出站请求被安全策略拒绝: %s %s", e.Target, e.Reason)
+}
+
+// 私有、回环、链路本地等不应从服务端访问的地址段
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // 本网络
+	"10.0.0.0/8",     // 私有网络
+	"100.64.0.0/10",  // 运营商级 NAT
+	"127.0.0.0/8",    // 回环
+	"169.254.0.0/16", // 链路本地(含云厂商元数据服务)
+	"172.16.0.0/12",  // 私有网络
+	"192.0.0.0/24",   // IETF 协议分配
+	"192.168.0.0/16", // 私有网络
+	"198.18.0.0/15",  // 基准测试
+	"224.0.0.0/4",    // 组播
+	"240.0.0.0/4",    // 保留
+	"::/128",         // 未指定
+	"::1/128",        // 回环
+	"fc00::/7",       // 唯一本地地址
+	"fe80::/10",      // 链路本地
+	"ff00::/8",       // 组播
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsPrivateIP 判断是否为私有、回环、链路本地等内部地址
+func IsPrivateIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostRule 主机名模式或 IP/CIDR 规则
+type hostRule struct {
+	pattern string
+	network *net.IPNet
+}
+
+func parseHostRules(patterns []string) []hostRule {
+	rules := make([]hostRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(pattern); err == nil {
+			rules = append(rules, hostRule{network: network})
+			continue
+		}
+		if ip := net.ParseIP(pattern); ip != nil {
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			rules = append(rules, hostRule{network: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}})
+			continue
+		}
+		rules = append(rules, hostRule{pattern: pattern})
+	}
+	return rules
+}
+
+func (r hostRule) matchHost(host string) bool {
+	if r.network != nil {
+		ip := net.ParseIP(host)
+		return ip != nil && r.network.Contains(ip)
+	}
+	return MatchHost(r.pattern, host)
+}
+
+func (r hostRule) matchIP(ip net.IP) bool {
+	return r.network != nil && r.network.Contains(ip)
+}
+
+// compiledPolicy 预处理后的策略
+type compiledPolicy struct {
+	allow        []hostRule
+	deny         []hostRule
+	blockPrivate bool
+	schemes      map[string]bool
+	ports        map[int]bool
+	maxRedirects int
+}
+
+func compilePolicy(p Policy) *compiledPolicy {
+	c := &compiledPolicy{
+		allow:        parseHostRules(p.AllowHosts),
+		deny:         parseHostRules(p.DenyHosts),
+		blockPrivate: p.BlockPrivate,
+		schemes:      map[string]bool{"http": true, "https": true},
+		ports:        make(map[int]bool),
+		maxRedirects: defaultMaxRedirects,
+	}
+	if len(p.AllowSchemes) > 0 {
+		c.schemes = make(map[string]bool)
+		for _, scheme := range p.AllowSchemes {
+			c.schemes[strings.ToLower(scheme)] = true
+		}
+	}
+	for _, port := range p.AllowPorts {
+		c.ports[port] = true
+	}
+	if p.MaxRedirects != nil {
+		c.maxRedirects = *p.MaxRedirects
+	}
+	return c
+}
+
+// checkURL 发起请求前检查协议、端口与主机名
+func (c *compiledPolicy) checkURL(u *url.URL) error {
+	target := u.Scheme + "://" + u.Host
+	if !c.schemes[strings.ToLower(u.Scheme)] {
+		return &PolicyError{Target: target, Reason: "不允许的协议 " + u.Scheme}
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if len(c.ports) > 0 {
+		port, err := strconv.Atoi(portOf(u))
+		if err != nil || !c.ports[port] {
+			return &PolicyError{Target: target, Reason: "不允许的端口 " + portOf(u)}
+		}
+	}
+	for _, rule := range c.deny {
+		if rule.matchHost(host) {
+			return &PolicyError{Target: target, Reason: "主机在拒绝列表中"}
+		}
+	}
+	if len(c.allow) > 0 {
+		allowed := false
+		for _, rule := range c.allow {
+			if rule.matchHost(host) {
+				allowed = true
+				break
+			}
+		}
+		// IP/CIDR 形式的允许规则在连接时按解析结果检查
+		if !allowed && !c.hasIPAllowRules() {
+			return &PolicyError{Target: target, Reason: "主机不在允许列表中"}
+		}
+	}
+	return nil
+}
+
+func (c *compiledPolicy) hasIPAllowRules() bool {
+	for _, rule := range c.allow {
+		if rule.network != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIP 建立连接前按实际连接的 IP 检查, 防止通过 DNS 解析到内网地址
+func (c *compiledPolicy) checkIP(host string, ip net.IP) error {
+	for _, rule := range c.deny {
+		if rule.matchIP(ip) {
+			return &PolicyError{Target: host, Reason: fmt.Sprintf("解析地址 %s 在拒绝列表中", ip)}
+		}
+	}
+	ipAllowed := false
+	for _, rule := range c.allow {
+		if rule.matchIP(ip) {
+			ipAllowed = true
+			break
+		}
+	}
+	if len(c.allow) > 0 && !ipAllowed && !c.matchesAllowHost(host) {
+		return &PolicyError{Target: host, Reason: fmt.Sprintf("解析地址 %s 不在允许列表中", ip)}
+	}
+	if c.blockPrivate && !ipAllowed && IsPrivateIP(ip) {
+		return &PolicyError{Target: host, Reason: fmt.Sprintf("解析地址 %s 是内网地址", ip)}
+	}
+	return nil
+}
+
+func (c *compiledPolicy) matchesAllowHost(host string) bool {
+	for _, rule := range c.allow {
+		if rule.matchHost(host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirect 限制重定向次数, 每一跳的地址检查由 Transport 完成
+func (c *compiledPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > c.maxRedirects {
+		return &PolicyError{Target: req.URL.String(), Reason: fmt.Sprintf("重定向次数超过 %d", c.maxRedirects)}
+	}
+	return c.checkURL(req.URL)
+}
+
+// dialControl 返回 net.Dialer.Control, 在连接建立前检查目标 IP
+func (c *compiledPolicy) dialControl(host string) func(network, address string, conn syscall.RawConn) error {
+	return func(network, address string, conn syscall.RawConn) error {
+		ipStr, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(ipStr)
+		if ip == nil {
+			return &PolicyError{Target: host, Reason: "无法识别的地址 " + address}
+		}
+		return c.checkIP(host, ip)
+	}
+}
+
+func portOf(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https", "wss":
+		return "443"
+	default:
+		return "80"
+	}
+}
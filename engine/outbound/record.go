@@ -0,0 +1,283 @@
+package outbound
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 单个请求体或响应体最多录制的字节数, 超出部分不录制
+const maxRecordBody = 1 << 20
+
+// 录制时隐藏值的请求头
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Exchange 录制的一次出站请求与响应
+type Exchange struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeaders http.Header `json:"requestHeaders,omitempty"`
+	RequestBody    string      `json:"requestBody,omitempty"`
+	// RequestEncoding 请求体不是 UTF-8 文本时为 base64
+	RequestEncoding string `json:"requestEncoding,omitempty"`
+
+	Status           int         `json:"status,omitempty"`
+	ResponseHeaders  http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody     string      `json:"responseBody,omitempty"`
+	ResponseEncoding string      `json:"responseEncoding,omitempty"`
+	ResponseTrailers http.Header `json:"responseTrailers,omitempty"`
+	// Truncated 请求体或响应体超过录制上限, 只录制了前 1MB
+	Truncated bool `json:"truncated,omitempty"`
+	// Error 请求失败时的错误信息
+	Error string `json:"error,omitempty"`
+
+	StartTime time.Time `json:"startTime"`
+	Duration  int64     `json:"duration"` // 毫秒
+}
+
+type recorderKey struct{}
+
+// Recorder 按发送顺序录制出站请求与响应
+type Recorder struct {
+	mu        sync.Mutex
+	exchanges []*Exchange
+}
+
+// NewRecorder 创建录制器
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// WithRecorder 返回的上下文中发出的请求与响应都会被录制
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+func recorderFrom(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
+
+// Exchanges 已录制的请求与响应
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exchanges := make([]Exchange, 0, len(r.exchanges))
+	for _, exchange := range r.exchanges {
+		exchanges = append(exchanges, *exchange)
+	}
+	return exchanges
+}
+
+// roundTrip 录制请求, 响应体在读取时同步录制, 关闭时完成录制(流式响应只录制已读取的部分)
+func (r *Recorder) roundTrip(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	exchange := &Exchange{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeaders: redactHeader(req.Header),
+		StartTime:      time.Now(),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody, exchange.RequestEncoding, exchange.Truncated = encodeBody(body)
+	}
+
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, exchange)
+	r.mu.Unlock()
+
+	resp, err := next(req)
+	if err != nil {
+		r.mu.Lock()
+		exchange.Error = err.Error()
+		exchange.Duration = time.Since(exchange.StartTime).Milliseconds()
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = resp.Header.Clone()
+	exchange.Duration = time.Since(exchange.StartTime).Milliseconds()
+	r.mu.Unlock()
+	// 升级协议(如 WebSocket)后的消息不录制
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: r, exchange: exchange, resp: resp}
+	}
+	return resp, nil
+}
+
+// recordingBody 读取响应体时同步录制
+type recordingBody struct {
+	io.ReadCloser
+	recorder *Recorder
+	exchange *Exchange
+	resp     *http.Response
+	buf      bytes.Buffer
+	once     sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() {
+		body, encoding, truncated := encodeBody(b.buf.Bytes())
+		b.recorder.mu.Lock()
+		defer b.recorder.mu.Unlock()
+		b.exchange.ResponseBody = body
+		b.exchange.ResponseEncoding = encoding
+		b.exchange.Truncated = b.exchange.Truncated || truncated
+		if len(b.resp.Trailer) > 0 {
+			b.exchange.ResponseTrailers = b.resp.Trailer.Clone()
+		}
+		b.exchange.Duration = time.Since(b.exchange.StartTime).Milliseconds()
+	})
+}
+
+// encodeBody UTF-8 文本原样保存, 否则使用 base64
+func encodeBody(body []byte) (string, string, bool) {
+	truncated := len(body) > maxRecordBody
+	if truncated {
+		body = body[:maxRecordBody]
+	}
+	if utf8.Valid(body) {
+		return string(body), "", truncated
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64", truncated
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if values := redacted.Values(name); len(values) > 0 {
+			redacted.Set(name, "***")
+		}
+	}
+	return redacted
+}
+
+type playerKey struct{}
+
+// Player 回放录制的响应, 请求不经过网络
+type Player struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	used      []bool
+}
+
+// NewPlayer 使用录制的请求与响应创建回放器
+func NewPlayer(exchanges []Exchange) *Player {
+	return &Player{exchanges: exchanges, used: make([]bool, len(exchanges))}
+}
+
+// WithPlayer 返回的上下文中发出的请求都由录制的响应回放
+func WithPlayer(ctx context.Context, player *Player) context.Context {
+	return context.WithValue(ctx, playerKey{}, player)
+}
+
+func playerFrom(ctx context.Context) *Player {
+	player, _ := ctx.Value(playerKey{}).(*Player)
+	return player
+}
+
+// ReplayMissError 回放时没有与请求匹配的录制
+type ReplayMissError struct {
+	Method string
+	URL    string
+}
+
+func (e *ReplayMissError) Error() string {
+	return fmt.Sprintf("回放中没有匹配的录制请求: %s %s", e.Method, e.URL)
+}
+
+// RoundTrip 按录制顺序查找方法与 URL 相同且未使用的录制, 找不到时忽略查询参数再按路径查找
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	exchange := p.take(req)
+	if exchange == nil {
+		return nil, &ReplayMissError{Method: req.Method, URL: req.URL.String()}
+	}
+	if exchange.Error != "" {
+		return nil, fmt.Errorf("%s", exchange.Error)
+	}
+
+	body, err := decodeBody(exchange.ResponseBody, exchange.ResponseEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("解码录制的响应体失败: %v", err)
+	}
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Trailer:       exchange.ResponseTrailers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (p *Player) take(req *http.Request) *Exchange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	url := req.URL.String()
+	path := strings.SplitN(url, "?", 2)[0]
+	for _, exact := range []bool{true, false} {
+		for i := range p.exchanges {
+			exchange := &p.exchanges[i]
+			if p.used[i] || exchange.Method != req.Method {
+				continue
+			}
+			if exchange.URL == url || (!exact && strings.SplitN(exchange.URL, "?", 2)[0] == path) {
+				p.used[i] = true
+				return exchange
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package outbound
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError 目标主机已熔断, 请求被直接拒绝
+type CircuitOpenError struct {
+	Host    string
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn > 0 {
+		return fmt.Sprintf("目标主机 %s 已熔断, %s 后重试", e.Host, e.RetryIn.Round(time.Second))
+	}
+	return fmt.Sprintf("目标主机 %s 已熔断, 正在等待探测请求结果", e.Host)
+}
+
+// hostState 单个主机的限流器与熔断器
+type hostState struct {
+	limiter *tokenBucket
+	sem     semaphore
+	breaker *breaker
+}
+
+// Manager 管理各主机的限流与熔断状态
+type Manager struct {
+	mu     sync.Mutex
+	config Config
+	policy *compiledPolicy
+	hosts  map[string]*hostState
+
+	baseOnce sync.Once
+	base     http.RoundTripper
+
+	http2Once sync.Once
+	http2Base http.RoundTripper
+}
+
+// NewManager 创建出站请求管理器
+func NewManager(config Config) *Manager {
+	return &Manager{config: config, policy: compilePolicy(config.Policy), hosts: make(map[string]*hostState)}
+}
+
+var defaultManager = NewManager(Config{})
+
+// Configure 更新全局配置, 已有的限流与熔断状态会被清空
+func Configure(config Config) {
+	defaultManager.Configure(config)
+}
+
+// Breakers 全局熔断器状态
+func Breakers() []BreakerStatus {
+	return defaultManager.Breakers()
+}
+
+// ResetBreaker 手动关闭全局熔断器
+func ResetBreaker(host string) bool {
+	return defaultManager.ResetBreaker(host)
+}
+
+// Configure 更新配置
+func (m *Manager) Configure(config Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+	m.policy = compilePolicy(config.Policy)
+	m.hosts = make(map[string]*hostState)
+}
+
+func (m *Manager) currentPolicy() *compiledPolicy {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.policy
+}
+
+// CheckRedirect 用作 http.Client.CheckRedirect, 限制重定向次数并检查每一跳的地址
+func (m *Manager) CheckRedirect(req *http.Request, via []*http.Request) error {
+	return m.currentPolicy().checkRedirect(req, via)
+}
+
+// baseTransport 实际发送请求的 Transport, 连接建立前按出站策略检查解析出的 IP。
+// 不使用 HTTP_PROXY 等环境变量中的代理: 经过代理时连接的是代理的 IP, 目标地址不会被检查
+func (m *Manager) baseTransport() http.RoundTripper {
+	m.baseOnce.Do(func() {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			d := *dialer
+			d.Control = m.currentPolicy().dialControl(host)
+			return d.DialContext(ctx, network, addr)
+		}
+		m.base = transport
+	})
+	return m.base
+}
+
+func (m *Manager) state(host string) *hostState {
+	host = strings.ToLower(host)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.hosts[host]; ok {
+		return state
+	}
+	config := m.config.hostConfig(host)
+	state := &hostState{}
+	if rate := config.rateLimit(); rate > 0 {
+		state.limiter = newTokenBucket(rate, intValue(config.Burst))
+	}
+	if limit := intValue(config.MaxConcurrent); limit > 0 {
+		state.sem = make(semaphore, limit)
+	}
+	if threshold := intValue(config.FailureThreshold); threshold > 0 {
+		state.breaker = newBreaker(threshold, config.cooldown())
+	}
+	m.hosts[host] = state
+	return state
+}
+
+// Breakers 已启用熔断的主机状态, 按主机名排序
+func (m *Manager) Breakers() []BreakerStatus {
+	m.mu.Lock()
+	hosts := make([]string, 0, len(m.hosts))
+	breakers := make(map[string]*breaker)
+	for host, state := range m.hosts {
+		if state.breaker != nil {
+			hosts = append(hosts, host)
+			breakers[host] = state.breaker
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Strings(hosts)
+	result := make([]BreakerStatus, 0, len(hosts))
+	for _, host := range hosts {
+		result = append(result, breakers[host].status(host))
+	}
+	return result
+}
+
+// ResetBreaker 手动关闭熔断器, 主机不存在时返回 false
+func (m *Manager) ResetBreaker(host string) bool {
+	m.mu.Lock()
+	state, ok := m.hosts[strings.ToLower(host)]
+	m.mu.Unlock()
+	if !ok || state.breaker == nil {
+		return false
+	}
+	state.breaker.reset()
+	return true
+}
+
+// Transport 检查出站策略, 并按目标主机限流、限制并发与熔断的 http.RoundTripper
+type Transport struct {
+	Manager *Manager
+	Base    http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper, 上下文中有录制器时录制请求与响应
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if recorder := recorderFrom(req.Context()); recorder != nil {
+		return recorder.roundTrip(req, t.roundTrip)
+	}
+	return t.roundTrip(req)
+}
+
+// roundTrip 回放录制的响应、交给进程内的 handler 处理, 或按出站策略、限流与熔断发送请求; 试运行时拒绝发送
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	if IsDryRun(req.Context()) {
+		return nil, ErrDryRun
+	}
+	if player := playerFrom(req.Context()); player != nil {
+		return player.RoundTrip(req)
+	}
+	if handler := localHandler(req.Context()); handler != nil {
+		return serveLocal(handler, req)
+	}
+	if err := t.Manager.currentPolicy().checkURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	host := req.URL.Hostname()
+	state := t.Manager.state(host)
+	ctx := req.Context()
+
+	if state.breaker != nil {
+		if ok, retryIn := state.breaker.allow(); !ok {
+			return nil, &CircuitOpenError{Host: host, RetryIn: retryIn}
+		}
+	}
+	if state.sem != nil {
+		if err := state.sem.Acquire(ctx); err != nil {
+			if state.breaker != nil {
+				state.breaker.cancel()
+			}
+			return nil, fmt.Errorf("等待 %s 的并发配额超时: %v", host, err)
+		}
+	}
+	if state.limiter != nil {
+		if err := state.limiter.Wait(ctx); err != nil {
+			if state.sem != nil {
+				state.sem.Release()
+			}
+			if state.breaker != nil {
+				state.breaker.cancel()
+			}
+			return nil, fmt.Errorf("等待 %s 的限流配额超时: %v", host, err)
+		}
+	}
+
+	resp, err := t.base().RoundTrip(req)
+
+	if state.breaker != nil {
+		switch {
+		case err != nil:
+			state.breaker.record(false, err.Error())
+		case resp.StatusCode >= 500:
+			state.breaker.record(false, resp.Status)
+		default:
+			state.breaker.record(true, "")
+		}
+	}
+	if state.sem != nil {
+		if err != nil {
+			state.sem.Release()
+		} else {
+			// 响应体读取完毕后才释放并发配额, 升级协议(101)时连接关闭后才释放
+			body := &releaseBody{ReadCloser: resp.Body, release: state.sem.Release}
+			if w, ok := resp.Body.(io.Writer); ok {
+				resp.Body = &releaseReadWriteBody{releaseBody: body, w: w}
+			} else {
+				resp.Body = body
+			}
+		}
+	}
+	return resp, err
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return t.Manager.baseTransport()
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// releaseReadWriteBody 升级协议(如 WebSocket)的响应体可写, 包装后需要保留 Write
+type releaseReadWriteBody struct {
+	*releaseBody
+	w io.Writer
+}
+
+func (b *releaseReadWriteBody) Write(p []byte) (int, error) {
+	return b.w.Write(p)
+}
+
+var (
+	clientOnce sync.Once
+	client     *http.Client
+)
+
+// Client 节点发出请求使用的共享 http.Client
+func Client() *http.Client {
+	clientOnce.Do(func() {
+		client = &http.Client{
+			Transport:     &Transport{Manager: defaultManager},
+			CheckRedirect: defaultManager.CheckRedirect,
+		}
+	})
+	return client
+}
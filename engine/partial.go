@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"fmt"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// ReuseResults 从指定节点开始部分执行: 该节点及其后续节点重新执行, 其余节点使用上一次执行的结果(previous)。
+// 返回的结果用于 WithStubResults; 重新执行的节点依赖的上游节点没有可用结果时返回错误, 其他没有结果的节点不执行(状态为 ready)
+func ReuseResults(nodes []engine_nodes.Node, edges []Edge, startKey string, previous []core.ExecuteResult) (map[string]*core.ExecuteResult, error) {
+	found := false
+	for _, node := range nodes {
+		if node.NodeKey == startKey {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("节点 %s 不存在", startKey)
+	}
+
+	descendants := reachable(edges, []string{startKey}, func(edge Edge) (string, string) { return edge.SourceNodeKey, edge.TargetNodeKey })
+	rerun := make([]string, 0, len(descendants))
+	for key := range descendants {
+		rerun = append(rerun, key)
+	}
+	ancestors := reachable(edges, rerun, func(edge Edge) (string, string) { return edge.TargetNodeKey, edge.SourceNodeKey })
+
+	// 同一节点有多个结果时使用最后一个
+	latest := make(map[string]core.ExecuteResult)
+	for _, result := range previous {
+		latest[result.NodeKey] = result
+	}
+
+	stubs := make(map[string]*core.ExecuteResult)
+	for _, node := range nodes {
+		if descendants[node.NodeKey] {
+			continue
+		}
+		if result, ok := latest[node.NodeKey]; ok {
+			stubs[node.NodeKey] = &result
+			continue
+		}
+		if ancestors[node.NodeKey] {
+			return nil, fmt.Errorf("上游节点 %s 没有可用的执行结果", node.NodeKey)
+		}
+		stubs[node.NodeKey] = &core.ExecuteResult{Status: core.ExecuteStatusReady}
+	}
+	return stubs, nil
+}
+
+// reachable 沿连线(direction 返回连线的起点与终点)从 keys 出发可以到达的节点, 包括 keys 本身
+func reachable(edges []Edge, keys []string, direction func(Edge) (string, string)) map[string]bool {
+	visited := make(map[string]bool)
+	for _, key := range keys {
+		visited[key] = true
+	}
+	queue := append([]string(nil), keys...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges {
+			from, to := direction(edge)
+			if from == current && !visited[to] {
+				visited[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return visited
+}
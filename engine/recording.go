@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine/core"
+	"api-flow/engine/outbound"
+)
+
+// InstanceRecording 流程实例执行时录制的出站请求与响应, 用于回放
+type InstanceRecording struct {
+	core.BasicModel
+	InstanceID uint      `json:"instanceId" gorm:"index"`
+	Exchanges  Exchanges `json:"exchanges" gorm:"type:longtext"`
+}
+
+// TableName 指定表名
+func (InstanceRecording) TableName() string {
+	return "workflow_instance_recordings"
+}
+
+// Exchanges 以 JSON 保存的录制列表
+type Exchanges []outbound.Exchange
+
+// Value 实现 sql.Valuer 接口
+func (e Exchanges) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(e)
+	return string(bytes), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (e *Exchanges) Scan(value interface{}) error {
+	if value == nil {
+		*e = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return json.Unmarshal(bytes, e)
+}
+
+func MigrateInstanceRecording(db *gorm.DB) error {
+	return db.AutoMigrate(&InstanceRecording{}).Error
+}
@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/models"
+)
+
+// Runner 工作流调度器, 按连线顺序执行节点, 不依赖数据库与HTTP服务
+type Runner struct {
+	nodeEngine *engine_nodes.NodeEngine
+}
+
+// NewRunner 创建工作流调度器
+func NewRunner(nodeEngine *engine_nodes.NodeEngine) *Runner {
+	return &Runner{
+		nodeEngine: nodeEngine,
+	}
+}
+
+func (r *Runner) getRealValue(value interface{}, results []core.ExecuteResult) (interface{}, error) {
+	if expression, ok := value.(string); ok {
+		parser := core.NewExpressionParser(results)
+		return parser.Parse(expression)
+	} else {
+		return value, nil
+	}
+}
+
+// ExecuteNode 解析节点配置中的输入表达式(config.inputs)后执行节点
+func (r *Runner) ExecuteNode(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) (*core.ExecuteResult, error) {
+	r.resolveInputs(ctx, node, inputs, results)
+	return r.nodeEngine.ExecuteNode(ctx, node, inputs)
+}
+
+// resolveInputs 解析节点配置中的输入表达式(config.inputs), 结果写入 inputs; 解析失败时写入日志事件
+func (r *Runner) resolveInputs(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) {
+	config := node.Config
+	if config != nil {
+		// 覆盖默认输入
+		configInputs, ok := config["inputs"].(map[string]interface{})
+		if ok {
+			for key, expressionOrValue := range configInputs {
+				realVal, err := r.getRealValue(expressionOrValue, results)
+				if err != nil {
+					log.Println("Error parsing expression:", err)
+					eventStreamFrom(ctx).log(node.NodeKey, fmt.Sprintf("输入 %s 的表达式解析失败: %v", key, err))
+				} else {
+					inputs[key] = realVal
+				}
+			}
+		}
+	}
+}
+
+// executeNode 执行节点; 调试时先在断点处暂停, 按调试命令执行、修改输入后执行或跳过节点
+func (r *Runner) executeNode(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) (*core.ExecuteResult, error) {
+	r.resolveInputs(ctx, node, inputs, results)
+	session := debugSessionFrom(ctx)
+	if session == nil {
+		return r.run(ctx, node, inputs)
+	}
+	command, err := session.pause(ctx, node, inputs, results)
+	if err != nil {
+		return nil, err
+	}
+	if command.Action == DebugSkip {
+		result := &core.ExecuteResult{Status: core.ExecuteStatusReady, Error: "调试时跳过"}
+		skipped(ctx, node, result, false)
+		return result, nil
+	}
+	nodeInputs := inputs
+	if len(command.Inputs) > 0 {
+		nodeInputs = make(map[string]interface{}, len(inputs)+len(command.Inputs))
+		for key, value := range inputs {
+			nodeInputs[key] = value
+		}
+		for key, value := range command.Inputs {
+			nodeInputs[key] = value
+		}
+	}
+	return r.run(ctx, node, nodeInputs)
+}
+
+// run 执行节点, 上下文中有执行记录时记录输入、输出、耗时与请求次数, 有事件流时写入开始、重试与结束事件
+func (r *Runner) run(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}) (*core.ExecuteResult, error) {
+	executionLog := executionLogFrom(ctx)
+	stream := eventStreamFrom(ctx)
+	if executionLog == nil && stream == nil {
+		return r.nodeEngine.ExecuteNode(ctx, node, inputs)
+	}
+	snapshot := make(models.Record, len(inputs))
+	for key, value := range inputs {
+		snapshot[key] = value
+	}
+	var retries int32
+	ctx = engine_nodes.WithRetryHook(ctx, func(attempt int, reason string) {
+		atomic.AddInt32(&retries, 1)
+		stream.Emit(ExecutionEvent{Type: EventNodeRetry, NodeKey: node.NodeKey, NodeType: node.NodeType, Attempt: attempt, Message: reason})
+	})
+	stream.Emit(ExecutionEvent{Type: EventNodeStarted, NodeKey: node.NodeKey, NodeType: node.NodeType})
+	startTime := time.Now()
+	result, err := r.nodeEngine.ExecuteNode(ctx, node, inputs)
+	endTime := time.Now()
+
+	execution := NodeExecution{
+		NodeID:    node.ID,
+		NodeKey:   node.NodeKey,
+		NodeType:  node.NodeType,
+		Attempt:   1 + int(atomic.LoadInt32(&retries)),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Duration:  endTime.Sub(startTime).Milliseconds(),
+		Inputs:    snapshot,
+	}
+	if err != nil {
+		execution.Status = core.ExecuteStatusError
+		execution.Error = err.Error()
+	} else {
+		execution.Status = result.Status
+		execution.Output = models.Record(result.Data)
+		execution.Error = result.Error
+	}
+	if executionLog != nil {
+		executionLog.add(execution)
+	}
+	finished := execution.Result()
+	stream.Emit(ExecutionEvent{
+		Type:     EventNodeFinished,
+		NodeKey:  node.NodeKey,
+		NodeType: node.NodeType,
+		Attempt:  execution.Attempt,
+		Duration: execution.Duration,
+		Result:   &finished,
+	})
+	return result, err
+}
+
+// skipped 记录未执行的节点(使用原实例结果或调试时跳过)
+func skipped(ctx context.Context, node *engine_nodes.Node, result *core.ExecuteResult, reused bool) {
+	if executionLog := executionLogFrom(ctx); executionLog != nil {
+		executionLog.skipped(node, result, reused)
+	}
+	finished := core.ExecuteResult{NodeID: node.ID, NodeKey: node.NodeKey, Status: result.Status, Data: result.Data, Error: result.Error}
+	eventStreamFrom(ctx).Emit(ExecutionEvent{
+		Type:     EventNodeFinished,
+		NodeKey:  node.NodeKey,
+		NodeType: node.NodeType,
+		Reused:   reused,
+		Result:   &finished,
+	})
+}
+
+type stubResultsKey struct{}
+
+// WithStubResults 返回的上下文中执行工作流时, results 中的节点(以节点键为key)不执行, 直接使用给定的结果
+func WithStubResults(ctx context.Context, results map[string]*core.ExecuteResult) context.Context {
+	return context.WithValue(ctx, stubResultsKey{}, results)
+}
+
+// Run 从起始节点开始, 沿连线依次执行工作流的节点
+func (r *Runner) Run(nodes []engine_nodes.Node, edges []Edge, inputs map[string]interface{}) ([]core.ExecuteResult, error) {
+	return r.RunContext(context.Background(), nodes, edges, inputs)
+}
+
+// RunContext 与 Run 相同, ctx 传递给每个节点, 携带模拟等执行选项
+func (r *Runner) RunContext(ctx context.Context, nodes []engine_nodes.Node, edges []Edge, inputs map[string]interface{}) ([]core.ExecuteResult, error) {
+	if inputs == nil {
+		inputs = make(map[string]interface{})
+	}
+	// 建立节点映射，方便快速查找
+	nodeMap := make(map[string]*engine_nodes.Node)
+	for i := range nodes {
+		nodeMap[nodes[i].NodeKey] = &nodes[i]
+	}
+	// 获取起始节点列表
+	startNodes, err := StartNodeKeys(nodes, edges)
+	if err != nil {
+		return nil, err
+	}
+	toBeExecuted := make(chan string, len(nodes))
+	for _, startNodeKey := range startNodes {
+		toBeExecuted <- startNodeKey
+	}
+	var getNextNodeKeys = func(nodeKey string) []string {
+		nextNodes := make([]string, 0)
+		for _, edge := range edges {
+			if edge.SourceNodeKey == nodeKey {
+				nextNodes = append(nextNodes, edge.TargetNodeKey)
+			}
+		}
+		return nextNodes
+	}
+	stubs, _ := ctx.Value(stubResultsKey{}).(map[string]*core.ExecuteResult)
+	results := make([]core.ExecuteResult, 0)
+nodeloop:
+	for {
+		select {
+		case nodeKey, ok := <-toBeExecuted:
+			if !ok {
+				log.Println("读取节点异常")
+				return nil, errors.New("读取节点异常")
+			}
+			node := nodeMap[nodeKey]
+			result, stubbed := stubs[nodeKey]
+			if stubbed {
+				skipped(ctx, node, result, result.Status != core.ExecuteStatusReady)
+			} else {
+				result, err = r.executeNode(ctx, node, inputs, results)
+				if err != nil {
+					return nil, fmt.Errorf("节点 %s 执行失败: %v", node.NodeKey, err)
+				}
+			}
+			results = append(results, core.ExecuteResult{
+				NodeID:  node.ID,
+				NodeKey: node.NodeKey,
+				Status:  result.Status,
+				Data:    result.Data,
+				Error:   result.Error,
+			})
+			nextKeys := getNextNodeKeys(nodeKey)
+			for _, nextKey := range nextKeys {
+				if _, ok := nodeMap[nextKey]; ok {
+					toBeExecuted <- nextKey
+				} else {
+					log.Printf("节点 %s 不存在\n", nextKey)
+					eventStreamFrom(ctx).log(nodeKey, fmt.Sprintf("后续节点 %s 不存在", nextKey))
+				}
+			}
+		default:
+			if len(toBeExecuted) == 0 {
+				close(toBeExecuted)
+				break nodeloop
+			}
+		}
+	}
+	return results, nil
+}
+
+// StartNodeKeys 获取工作流的起始节点(入度为0)列表
+func StartNodeKeys(nodes []engine_nodes.Node, edges []Edge) ([]string, error) {
+	if len(nodes) == 0 {
+		return nil, errors.New("工作流没有节点")
+	}
+	startNodeKeyList := make([]string, 0)
+	// 使用map来存储每个节点的入度
+	inDegree := make(map[string]int)
+	for _, node := range nodes {
+		inDegree[node.NodeKey] = 0
+	}
+
+	// 计算每个节点的入度
+	for _, edge := range edges {
+		inDegree[edge.TargetNodeKey]++
+	}
+
+	// 找到入度为0的节点
+	for key, val := range inDegree {
+		if val == 0 {
+			startNodeKeyList = append(startNodeKeyList, key)
+		}
+	}
+
+	return startNodeKeyList, nil
+}
+
+// SummarizeResults 汇总节点执行结果, 返回整体状态与错误信息
+func SummarizeResults(nodeResults []core.ExecuteResult) (core.ExecuteStatus, string) {
+	var status core.ExecuteStatus = core.ExecuteStatusReady
+	var errorMessage string
+	for _, result := range nodeResults {
+		status = result.Status
+		if status != core.ExecuteStatusSuccess {
+			errorMessage += fmt.Sprintf("节点 %s 执行失败: %s\n", result.NodeKey, result.Error)
+		}
+	}
+	return status, errorMessage
+}
+
+// CollectOutputs 收集末端节点(没有后续节点)的输出, 作为流程的输出结果, 以节点键为key
+func CollectOutputs(edges []Edge, nodeResults []core.ExecuteResult) map[string]interface{} {
+	hasNext := make(map[string]bool)
+	for _, edge := range edges {
+		hasNext[edge.SourceNodeKey] = true
+	}
+	outputs := make(map[string]interface{})
+	for _, result := range nodeResults {
+		if !hasNext[result.NodeKey] && result.Status == core.ExecuteStatusSuccess {
+			outputs[result.NodeKey] = result.Data
+		}
+	}
+	return outputs
+}
@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/jsonpath"
+	"api-flow/engine/models"
+)
+
+// TestCase 工作流测试用例: 输入数据、模拟的节点输出, 以及对节点结果与流程输出的断言
+type TestCase struct {
+	core.BasicModel
+	WorkflowID  uint          `json:"workflowId"`
+	Name        string        `gorm:"size:255;not null" json:"name"`
+	Description string        `gorm:"size:1000" json:"description"`
+	Inputs      models.Record `gorm:"type:json" json:"inputs"`
+	// NodeOutputs 模拟的节点输出, 以节点键为key, 这些节点不执行, 直接以给定的输出视为成功
+	NodeOutputs models.Record `gorm:"type:json" json:"nodeOutputs"`
+	// Mock 节点的 HTTP 请求是否由模拟接口处理
+	Mock       bool           `json:"mock"`
+	Assertions TestAssertions `gorm:"type:json" json:"assertions"`
+}
+
+// TableName 指定表名
+func (TestCase) TableName() string {
+	return "workflow_test_cases"
+}
+
+// TestAssertion 测试断言, nodeKey 为空时针对流程输出(outputs), 否则针对该节点的结果
+type TestAssertion struct {
+	NodeKey string `json:"nodeKey,omitempty"`
+	// Status 节点的执行状态: success 或 error, 为空时不检查(也没有 path 时检查 success)
+	Status string `json:"status,omitempty"`
+	// Path 取值的 JSONPath, 针对节点输出(data)或流程输出; 节点断言为空时只检查状态
+	Path string `json:"path,omitempty"`
+	engine_nodes.ValueMatcher
+}
+
+// TestAssertions 以 JSON 保存的断言列表
+type TestAssertions []TestAssertion
+
+// Value 实现 sql.Valuer 接口
+func (a TestAssertions) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(a)
+	return string(bytes), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (a *TestAssertions) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// TestCaseResult 单个测试用例的执行结果
+type TestCaseResult struct {
+	ID          uint                           `json:"id"`
+	Name        string                         `json:"name"`
+	Passed      bool                           `json:"passed"`
+	Error       string                         `json:"error,omitempty"`
+	Assertions  []engine_nodes.AssertionResult `json:"assertions"`
+	NodeResults []core.ExecuteResult           `json:"nodeResults"`
+	Outputs     map[string]interface{}         `json:"outputs"`
+	Duration    int64                          `json:"duration"` // 毫秒
+}
+
+// TestReport 测试报告
+type TestReport struct {
+	WorkflowID   uint             `json:"workflowId"`
+	WorkflowName string           `json:"workflowName"`
+	Total        int              `json:"total"`
+	Passed       int              `json:"passed"`
+	Failed       int              `json:"failed"`
+	Duration     int64            `json:"duration"` // 毫秒
+	Cases        []TestCaseResult `json:"cases"`
+}
+
+// Add 添加用例结果并更新统计
+func (r *TestReport) Add(result TestCaseResult) {
+	r.Cases = append(r.Cases, result)
+	r.Total++
+	if result.Passed {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+	r.Duration += result.Duration
+}
+
+// Validate 检查测试用例的断言配置
+func (c *TestCase) Validate() error {
+	if c.Name == "" {
+		return errors.New("测试用例名称不能为空")
+	}
+	if _, err := c.stubResults(); err != nil {
+		return err
+	}
+	_, err := c.compileAssertions()
+	return err
+}
+
+// stubResults 模拟的节点输出转换为节点执行结果, 输出必须是对象
+func (c *TestCase) stubResults() (map[string]*core.ExecuteResult, error) {
+	stubs := make(map[string]*core.ExecuteResult, len(c.NodeOutputs))
+	for key, output := range c.NodeOutputs {
+		data, ok := output.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("nodeOutputs.%s 必须是对象", key)
+		}
+		stubs[key] = &core.ExecuteResult{NodeKey: key, Status: core.ExecuteStatusSuccess, Data: data}
+	}
+	return stubs, nil
+}
+
+type compiledTestAssertion struct {
+	TestAssertion
+	path *jsonpath.Path
+}
+
+func (c *TestCase) compileAssertions() ([]compiledTestAssertion, error) {
+	compiled := make([]compiledTestAssertion, 0, len(c.Assertions))
+	for i, assertion := range c.Assertions {
+		item := compiledTestAssertion{TestAssertion: assertion}
+		switch assertion.Status {
+		case "", "success", "error":
+		default:
+			return nil, fmt.Errorf("assertions[%d].status 必须是 success 或 error", i)
+		}
+		if assertion.Status != "" && assertion.NodeKey == "" {
+			return nil, fmt.Errorf("assertions[%d]: status 只能用于节点断言", i)
+		}
+		if assertion.NodeKey != "" && assertion.Status == "" && assertion.Path == "" {
+			// 只指定节点时检查节点执行成功
+			item.Status = "success"
+		}
+		if assertion.Path != "" || assertion.NodeKey == "" {
+			expr := assertion.Path
+			if expr == "" {
+				expr = "$"
+			}
+			path, err := jsonpath.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("assertions[%d]: %v", i, err)
+			}
+			item.path = path
+			if err := item.Compile(); err != nil {
+				return nil, fmt.Errorf("assertions[%d]: %v", i, err)
+			}
+		}
+		compiled = append(compiled, item)
+	}
+	return compiled, nil
+}
+
+// Run 执行测试用例: 使用用例的输入执行工作流, 模拟的节点不执行, 然后检查断言。
+// 没有断言时要求所有节点执行成功
+func (c *TestCase) Run(ctx context.Context, runner *Runner, nodes []engine_nodes.Node, edges []Edge) TestCaseResult {
+	startTime := time.Now()
+	result := TestCaseResult{ID: c.ID, Name: c.Name, Assertions: make([]engine_nodes.AssertionResult, 0)}
+	defer func() {
+		result.Duration = time.Since(startTime).Milliseconds()
+	}()
+
+	assertions, err := c.compileAssertions()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	inputs := make(map[string]interface{}, len(c.Inputs))
+	for key, value := range c.Inputs {
+		inputs[key] = value
+	}
+	if len(c.NodeOutputs) > 0 {
+		stubs, err := c.stubResults()
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		ctx = WithStubResults(ctx, stubs)
+	}
+
+	nodeResults, err := runner.RunContext(ctx, nodes, edges, inputs)
+	if err != nil {
+		result.Error = fmt.Sprintf("执行工作流失败: %v", err)
+		return result
+	}
+	result.NodeResults = nodeResults
+	result.Outputs = CollectOutputs(edges, nodeResults)
+
+	if len(assertions) == 0 {
+		status, message := SummarizeResults(nodeResults)
+		result.Passed = status == core.ExecuteStatusSuccess
+		result.Error = strings.TrimSpace(message)
+		return result
+	}
+
+	resultMap := make(map[string]core.ExecuteResult, len(nodeResults))
+	for _, nodeResult := range nodeResults {
+		resultMap[nodeResult.NodeKey] = nodeResult
+	}
+	result.Passed = true
+	for _, assertion := range assertions {
+		assertionResult := assertion.evaluate(resultMap, result.Outputs)
+		if !assertionResult.Passed {
+			result.Passed = false
+		}
+		result.Assertions = append(result.Assertions, assertionResult)
+	}
+	return result
+}
+
+// evaluate 检查单条断言
+func (a *compiledTestAssertion) evaluate(results map[string]core.ExecuteResult, outputs map[string]interface{}) engine_nodes.AssertionResult {
+	target := a.Path
+	if target == "" {
+		target = "$"
+	}
+	if a.NodeKey == "" {
+		value, found := a.path.Lookup(normalizeValue(outputs))
+		return assertionResult("output", target, a.Match(value, found))
+	}
+
+	target = a.NodeKey + " " + target
+	nodeResult, ok := results[a.NodeKey]
+	if !ok {
+		return assertionResult("node", target, "节点没有执行")
+	}
+	if a.Status != "" {
+		actual := "error"
+		if nodeResult.Status == core.ExecuteStatusSuccess {
+			actual = "success"
+		}
+		if actual != a.Status {
+			return assertionResult("node", target, strings.TrimSpace(fmt.Sprintf("状态应为 %s, 实际为 %s %s", a.Status, actual, nodeResult.Error)))
+		}
+	}
+	if a.path == nil {
+		return assertionResult("node", target, "")
+	}
+	value, found := a.path.Lookup(normalizeValue(nodeResult.Data))
+	return assertionResult("node", target, a.Match(value, found))
+}
+
+func assertionResult(assertionType, target, message string) engine_nodes.AssertionResult {
+	if message != "" {
+		message = fmt.Sprintf("%s %s", target, message)
+	}
+	return engine_nodes.AssertionResult{Type: assertionType, Target: target, Passed: message == "", Message: message}
+}
+
+// normalizeValue 转换为 JSON 解码后的形式(map[string]interface{}、float64 等), 便于 JSONPath 取值与比较
+func normalizeValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+// MigrateTestCase 创建测试用例表
+func MigrateTestCase(db *gorm.DB) error {
+	return db.AutoMigrate(&TestCase{}).Error
+}
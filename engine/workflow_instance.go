@@ -15,10 +15,22 @@ type WorkflowInstance struct {
 	Status       core.ExecuteStatus `json:"status"`
 	StartTime    time.Time          `json:"startTime"`
 	EndTime      time.Time          `json:"endTime"`
-	Inputs       string             `json:"inputs" gorm:"type:text"`  // JSON字符串
-	Results      string             `json:"results" gorm:"type:text"` // JSON字符串
+	Inputs       string             `json:"inputs" gorm:"type:text"` // JSON字符串
+	Results      string             `json:"-" gorm:"type:text"`      // JSON字符串, 仅旧实例保存; 节点执行结果见 NodeExecution
 	ErrorMessage string             `json:"errorMessage"`
 	Duration     int64              `json:"duration" gorm:"comment:'执行时间(ms)'"`
+	Mock         bool               `json:"mock" gorm:"comment:'是否使用模拟接口执行'"`
+	Recorded     bool               `json:"recorded" gorm:"comment:'是否录制了出站请求'"`
+	DryRun       bool               `json:"dryRun" gorm:"comment:'是否为试运行'"`
+	ReplayOf     uint               `json:"replayOf,omitempty" gorm:"comment:'回放的原实例ID'"`
+	RerunOf      uint               `json:"rerunOf,omitempty" gorm:"comment:'重新执行的原实例ID'"`
+	StartNode    string             `json:"startNode,omitempty" gorm:"comment:'部分执行的起始节点'"`
+	FromInstance uint               `json:"fromInstance,omitempty" gorm:"comment:'部分执行时复用结果的实例ID'"`
+	Definition   string             `json:"-" gorm:"type:longtext"` // 执行时的工作流定义, JSON字符串
+	Version      string             `json:"version,omitempty" gorm:"size:16;index;comment:'执行时工作流定义的摘要'"`
+
+	// NodeResults 节点执行结果, 查询执行历史时由 node_executions 表填充, 不保存
+	NodeResults []core.ExecuteResult `json:"nodeResults,omitempty" gorm:"-"`
 }
 
 func MigrateWorkflowInstance(db *gorm.DB) error {
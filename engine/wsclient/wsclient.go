@@ -0,0 +1,318 @@
+// Package wsclient 最小的 WebSocket(RFC 6455)客户端
+//
+// 握手通过传入的 http.Client 发送(节点使用 outbound.Client, 与 API 节点共用出站策略、限流与熔断),
+// 升级成功后在响应体(101 时可读写)上收发帧。只支持文本与二进制消息, 不支持扩展(如压缩)。
+// Accept 在服务端完成握手, 用于向客户端推送消息(如执行事件)。
+package wsclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// 消息与控制帧类型
+const (
+	OpContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	OpClose        = 0x8
+	OpPing         = 0x9
+	OpPong         = 0xA
+)
+
+// 单条消息的最大长度, 防止异常的服务端耗尽内存
+const maxMessageSize = 16 << 20
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// CloseError 服务端关闭了连接
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("WebSocket连接已关闭: %d %s", e.Code, e.Reason)
+}
+
+// Conn WebSocket 连接
+type Conn struct {
+	reader *bufio.Reader
+	rwc    io.ReadWriteCloser
+
+	writeMu sync.Mutex
+	closed  bool
+	// server 服务端连接发出的帧不使用掩码
+	server bool
+}
+
+// Dial 建立连接, rawURL 的协议为 ws 或 wss; 握手失败时仍会返回响应(如果有)
+func Dial(ctx context.Context, client *http.Client, rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("无效的WebSocket地址: %v", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, nil, fmt.Errorf("不支持的WebSocket协议: %s", u.Scheme)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建WebSocket请求失败: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("建立WebSocket连接失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("WebSocket握手失败: HTTP %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, resp, errors.New("WebSocket握手失败: 连接不可写")
+	}
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		rwc.Close()
+		return nil, resp, errors.New("WebSocket握手失败: Sec-WebSocket-Accept 不匹配")
+	}
+
+	return &Conn{reader: bufio.NewReader(rwc), rwc: rwc}, resp, nil
+}
+
+// IsUpgrade 请求是否为 WebSocket 握手请求
+func IsUpgrade(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") && headerContains(r.Header, "Upgrade", "websocket")
+}
+
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Accept 在服务端完成握手, 接管底层连接; 失败时已向客户端返回 400
+func Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !IsUpgrade(r) || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		http.Error(w, "无效的WebSocket握手请求", http.StatusBadRequest)
+		return nil, errors.New("无效的WebSocket握手请求")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "不支持WebSocket", http.StatusInternalServerError)
+		return nil, errors.New("ResponseWriter 不支持接管连接")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("接管连接失败: %v", err)
+	}
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{reader: rw.Reader, rwc: conn, server: true}, nil
+}
+
+// WriteMessage 发送一条文本或二进制消息
+func (c *Conn) WriteMessage(opcode int, data []byte) error {
+	return c.writeFrame(opcode, data)
+}
+
+// writeFrame 发送单帧, 客户端发出的帧必须使用掩码, 服务端发出的帧不能使用掩码
+func (c *Conn) writeFrame(opcode int, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return errors.New("WebSocket连接已关闭")
+	}
+
+	header := []byte{0x80 | byte(opcode), 0}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+	if c.server {
+		_, err := c.rwc.Write(append(header, payload...))
+		return err
+	}
+	header[1] |= 0x80
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame := make([]byte, 0, len(header)+4+len(payload))
+	frame = append(frame, header...)
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// ReadMessage 读取一条完整的消息, 自动回复 ping 并合并分片; 服务端关闭连接时返回 *CloseError
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		opcode  int
+		message []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case OpPing:
+			if err := c.writeFrame(OpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			closeErr := &CloseError{Code: 1005}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			c.writeFrame(OpClose, payload)
+			c.shutdown()
+			return 0, nil, closeErr
+		case OpContinuation:
+			if opcode == 0 {
+				return 0, nil, errors.New("WebSocket协议错误: 意外的分片")
+			}
+		default:
+			if opcode != 0 {
+				return 0, nil, errors.New("WebSocket协议错误: 分片未结束")
+			}
+			opcode = op
+		}
+		if len(message)+len(payload) > maxMessageSize {
+			return 0, nil, fmt.Errorf("WebSocket消息超过 %d 字节", maxMessageSize)
+		}
+		message = append(message, payload...)
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	opcode := int(head[0] & 0x0F)
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, errors.New("WebSocket协议错误: 不支持扩展")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessageSize {
+		return false, 0, nil, fmt.Errorf("WebSocket消息超过 %d 字节", maxMessageSize)
+	}
+
+	var mask []byte
+	if head[1]&0x80 != 0 {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if mask != nil {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Close 发送关闭帧(1000)并关闭连接
+func (c *Conn) Close() error {
+	c.writeFrame(OpClose, []byte{0x03, 0xE8})
+	return c.shutdown()
+}
+
+func (c *Conn) shutdown() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
@@ -4,7 +4,11 @@ const NodeTypeMap = {
   ],
   task: [
     'api',
-    'text'
+    'text',
+    'graphql',
+    'grpc',
+    'websocket',
+    'sse'
   ]
 }
 
@@ -67,4 +67,10 @@ export const workflowService = {
       throw error;
     }
   },
+
+  // 获取工作流统计分析：耗时分布、节点失败率、失败原因、趋势与版本对比
+  getWorkflowAnalytics: async (workflowId: number, params: { from?: string; to?: string; interval?: string; version?: string } = {}): Promise<any> => {
+    const response = await axios.get(`/api/workflows/${workflowId}/analytics`, { params });
+    return response.data;
+  },
 };
\ No newline at end of file
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/dto"
+	"api-flow/services"
+)
+
+// GraphQLHandler 处理 GraphQL 节点辅助API
+type GraphQLHandler struct {
+	graphqlService *services.GraphQLService
+}
+
+// NewGraphQLHandler 创建 GraphQL 处理器实例
+func NewGraphQLHandler(graphqlService *services.GraphQLService) *GraphQLHandler {
+	return &GraphQLHandler{graphqlService: graphqlService}
+}
+
+// Operations 内省 GraphQL 接口, 返回可调用的 Query 与 Mutation 操作
+func (h *GraphQLHandler) Operations(c *gin.Context) {
+	var request dto.GraphQLIntrospectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	operations, err := h.graphqlService.Operations(request)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"operations": operations})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/dto"
+	"api-flow/services"
+)
+
+// GrpcHandler 处理 gRPC 节点辅助API
+type GrpcHandler struct {
+	grpcService *services.GrpcService
+}
+
+// NewGrpcHandler 创建 gRPC 处理器实例
+func NewGrpcHandler(grpcService *services.GrpcService) *GrpcHandler {
+	return &GrpcHandler{grpcService: grpcService}
+}
+
+// Services 列出可调用的服务与方法, 通过服务端反射或上传的 FileDescriptorSet 解析
+func (h *GrpcHandler) Services(c *gin.Context) {
+	var request dto.GrpcServicesRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	upload, err := readUploadFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	services, descriptorSet, err := h.grpcService.Services(request, upload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"services":      services,
+		"descriptorSet": descriptorSet,
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/services"
+)
+
+// ImportHandler 处理从外部格式导入节点的API
+type ImportHandler struct {
+	importService   *services.ImportService
+	workflowService *services.WorkflowService
+}
+
+// NewImportHandler 创建导入处理器实例
+func NewImportHandler(importService *services.ImportService, workflowService *services.WorkflowService) *ImportHandler {
+	return &ImportHandler{
+		importService:   importService,
+		workflowService: workflowService,
+	}
+}
+
+// readUploadFile 读取 multipart 上传的 file 字段, 非 multipart 请求返回 nil
+func readUploadFile(c *gin.Context) ([]byte, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return nil, nil
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if err == http.ErrMissingFile {
+			return nil, nil
+		}
+		return nil, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+// OpenAPIOperations 解析上传的 OpenAPI 文档, 返回可导入的接口列表
+// 文档可以作为请求体直接提交, 也可以通过 multipart 的 file 字段上传
+func (h *ImportHandler) OpenAPIOperations(c *gin.Context) {
+	data, err := readUploadFile(c)
+	if err == nil && data == nil {
+		data, err = c.GetRawData()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	spec, operations, err := h.importService.OpenAPIOperations(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"title":      spec.Title(),
+		"version":    spec.Version,
+		"baseUrl":    spec.BaseURL(),
+		"operations": operations,
+	})
+}
+
+// OpenAPINodes 将选中的接口转换为预配置的 API 节点(不保存, 由编辑器添加到画布)
+func (h *ImportHandler) OpenAPINodes(c *gin.Context) {
+	var request dto.OpenAPIImportRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := readUploadFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if data == nil {
+		data = []byte(request.Spec)
+	}
+
+	nodes, err := h.importService.OpenAPINodes(data, request.OperationIDs, request.BaseURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": nodes})
+}
+
+// Curl 将 cURL 命令转换为预配置的 API 节点
+func (h *ImportHandler) Curl(c *gin.Context) {
+	var request dto.CurlImportRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	node, warnings, err := h.importService.CurlNode(request.Command)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": node, "warnings": warnings})
+}
+
+// HAR 将 HAR 文件中的请求转换为 API 节点
+// 参数: filter 只导入 URL 包含该字符串的请求; chain=true 按顺序串联为线性流程; name 不为空时同时保存为新工作流
+func (h *ImportHandler) HAR(c *gin.Context) {
+	data, err := readUploadFile(c)
+	if err == nil && data == nil {
+		data, err = c.GetRawData()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chain := c.Query("chain") == "true"
+	result, err := h.importService.HARNodes(data, c.Query("filter"), chain)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if name := c.Query("name"); name != "" {
+		response, err := h.workflowService.SaveWorkflow(&dto.WorkflowDTO{
+			Name:   name,
+			Status: engine.WorkflowDraft,
+			Nodes:  result.Nodes,
+			Edges:  result.Edges,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		result.ID = response.ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
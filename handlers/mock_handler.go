@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/mock"
+	"api-flow/services"
+)
+
+// MockPrefix 模拟接口的路径前缀
+const MockPrefix = "/mock"
+
+// MockHandler 处理模拟接口的管理API, 并提供模拟接口
+type MockHandler struct {
+	mockService *services.MockService
+	server      http.Handler
+}
+
+// NewMockHandler 创建模拟接口处理器实例
+func NewMockHandler(mockService *services.MockService) *MockHandler {
+	return &MockHandler{
+		mockService: mockService,
+		server:      http.StripPrefix(MockPrefix, mockService.Server()),
+	}
+}
+
+// List 获取全部模拟接口
+func (h *MockHandler) List(c *gin.Context) {
+	routes, err := h.mockService.ListRoutes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": routes})
+}
+
+// Create 创建模拟接口
+func (h *MockHandler) Create(c *gin.Context) {
+	var route mock.Route
+	if err := c.ShouldBindJSON(&route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.mockService.CreateRoute(&route); err != nil {
+		h.error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "模拟接口创建成功",
+		"id":      route.ID,
+	})
+}
+
+// Update 更新模拟接口
+func (h *MockHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var route mock.Route
+	if err := c.ShouldBindJSON(&route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.mockService.UpdateRoute(uint(id), &route); err != nil {
+		h.error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "模拟接口更新成功"})
+}
+
+// Delete 删除模拟接口
+func (h *MockHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	if err := h.mockService.DeleteRoute(uint(id)); err != nil {
+		h.error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "模拟接口删除成功"})
+}
+
+// Reset 清零调用次数
+func (h *MockHandler) Reset(c *gin.Context) {
+	h.mockService.Reset()
+	c.JSON(http.StatusOK, gin.H{"message": "模拟接口调用次数已清零"})
+}
+
+// Serve 按请求路径(去掉 /mock 前缀)返回模拟响应
+func (h *MockHandler) Serve(c *gin.Context) {
+	h.server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *MockHandler) error(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMock):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMockNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+	"api-flow/mock"
 	"api-flow/services"
 )
 
@@ -99,7 +101,15 @@ func (h *NodeHandler) Execute(c *gin.Context) {
 	}
 
 	// 执行流程
-	result, err := h.nodeExecutionService.ExecuteNodeWithoutWorkflow(uint(id), inputs)
+	// mock=true 时节点的 HTTP 请求由模拟接口处理, dryRun=true 时只渲染请求
+	ctx := c.Request.Context()
+	if c.Query("mock") == "true" {
+		ctx = mock.Default().Redirect(ctx)
+	}
+	if c.Query("dryRun") == "true" {
+		ctx = outbound.WithDryRun(ctx)
+	}
+	result, err := h.nodeExecutionService.ExecuteNodeWithoutWorkflow(ctx, uint(id), inputs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
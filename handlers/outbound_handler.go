@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/services"
+)
+
+// OutboundHandler 处理出站请求治理相关API
+type OutboundHandler struct {
+	outboundService *services.OutboundService
+}
+
+// NewOutboundHandler 创建出站请求处理器实例
+func NewOutboundHandler(outboundService *services.OutboundService) *OutboundHandler {
+	return &OutboundHandler{outboundService: outboundService}
+}
+
+// Breakers 获取各目标主机的熔断器状态
+func (h *OutboundHandler) Breakers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.outboundService.GetBreakers())
+}
+
+// ResetBreaker 手动关闭熔断器
+func (h *OutboundHandler) ResetBreaker(c *gin.Context) {
+	if err := h.outboundService.ResetBreaker(c.Param("host")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "熔断器已重置"})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/engine"
+	"api-flow/services"
+)
+
+// 默认统计最近 7 天, 时间范围不超过 2 天时默认按小时分桶
+const (
+	defaultAnalyticsRange  = 7 * 24 * time.Hour
+	hourlyAnalyticsMaxSpan = 48 * time.Hour
+)
+
+// GetWorkflowAnalytics 工作流的统计分析: from/to 为 RFC3339 时间或日期(2006-01-02), interval 为 hour 或 day, version 筛选工作流版本
+func (h *WorkflowHandler) GetWorkflowAnalytics(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	if _, err := h.workflowService.GetWorkflowByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, err = parseAnalyticsTime(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("无效的to参数: %v", err)})
+			return
+		}
+	}
+	from := to.Add(-defaultAnalyticsRange)
+	if value := c.Query("from"); value != "" {
+		if from, err = parseAnalyticsTime(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("无效的from参数: %v", err)})
+			return
+		}
+	}
+	interval := c.Query("interval")
+	if interval == "" {
+		interval = engine.AnalyticsDaily
+		if to.Sub(from) <= hourlyAnalyticsMaxSpan {
+			interval = engine.AnalyticsHourly
+		}
+	}
+
+	analytics, err := h.workflowService.GetWorkflowAnalytics(uint(id), engine.AnalyticsQuery{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Version:  c.Query("version"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnalytics) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
+
+// parseAnalyticsTime 解析 RFC3339 时间, 或本地时区的日期
+func parseAnalyticsTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local(), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/services"
+)
+
+// StartDebug 以调试模式执行工作流, 返回第一次暂停或执行结束时的会话状态
+func (h *WorkflowHandler) StartDebug(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var request dto.WorkflowDebugRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	session, err := h.workflowService.StartDebug(uint(id), &request)
+	if err != nil {
+		debugError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session.Wait(c.Request.Context()))
+}
+
+// GetDebugSession 调试会话的当前状态: 暂停的节点、解析后的输入参数与已有的执行结果
+func (h *WorkflowHandler) GetDebugSession(c *gin.Context) {
+	session, err := h.workflowService.DebugSession(c.Param("sessionId"))
+	if err != nil {
+		debugError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session.State())
+}
+
+// DebugCommand 返回执行调试命令(continue/step/skip)的 handler, 请求体可选 {"inputs": {...}} 修改当前节点的输入参数,
+// 返回下一次暂停或执行结束时的会话状态
+func (h *WorkflowHandler) DebugCommand(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := h.workflowService.DebugSession(c.Param("sessionId"))
+		if err != nil {
+			debugError(c, err)
+			return
+		}
+		command := engine.DebugCommand{Action: action}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&command); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			command.Action = action
+		}
+		if err := session.Command(command); err != nil {
+			debugError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, session.Wait(c.Request.Context()))
+	}
+}
+
+// SetDebugBreakpoints 修改调试会话的断点
+func (h *WorkflowHandler) SetDebugBreakpoints(c *gin.Context) {
+	session, err := h.workflowService.DebugSession(c.Param("sessionId"))
+	if err != nil {
+		debugError(c, err)
+		return
+	}
+	var request struct {
+		Breakpoints []string `json:"breakpoints"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	session.SetBreakpoints(request.Breakpoints)
+	c.JSON(http.StatusOK, session.State())
+}
+
+// AbortDebug 终止调试会话
+func (h *WorkflowHandler) AbortDebug(c *gin.Context) {
+	session, err := h.workflowService.AbortDebug(c.Param("sessionId"))
+	if err != nil {
+		debugError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session.State())
+}
+
+func debugError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDebugSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, engine.ErrDebugNotPaused):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidExecution):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
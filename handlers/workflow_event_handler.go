@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/engine"
+	"api-flow/engine/wsclient"
+)
+
+// GetInstanceEvents 推送流程实例的执行事件: 默认使用 SSE, WebSocket 握手请求时使用 WebSocket;
+// 执行中的实例实时推送, 已结束的实例回放保存的事件, 最后一个事件(instance.finished)之后关闭连接。
+// after(或 SSE 重连时的 Last-Event-ID)为已收到的最后一个事件序号, 只推送之后的事件
+func (h *WorkflowHandler) GetInstanceEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("instanceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	after := 0
+	if value := c.DefaultQuery("after", c.GetHeader("Last-Event-ID")); value != "" {
+		if after, err = strconv.Atoi(value); err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的after参数"})
+			return
+		}
+	}
+	stream, err := h.workflowService.InstanceEventStream(uint(id))
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+
+	if wsclient.IsUpgrade(c.Request) {
+		streamEventsWebSocket(c, stream, after)
+		return
+	}
+	streamEventsSSE(c, stream, after)
+}
+
+func streamEventsSSE(c *gin.Context, stream *engine.EventStream, after int) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for {
+		events, more := stream.Wait(ctx, after)
+		if !more {
+			return
+		}
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data); err != nil {
+				return
+			}
+			after = event.Seq
+		}
+		c.Writer.Flush()
+	}
+}
+
+// streamEventsWebSocket 每个事件为一条 JSON 文本消息, 客户端断开时停止推送
+func streamEventsWebSocket(c *gin.Context, stream *engine.EventStream, after int) {
+	conn, err := wsclient.Accept(c.Writer, c.Request)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	// 读取客户端消息以响应 ping 并发现断开
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		events, more := stream.Wait(ctx, after)
+		if !more {
+			return
+		}
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(wsclient.OpText, data); err != nil {
+				return
+			}
+			after = event.Seq
+		}
+	}
+}
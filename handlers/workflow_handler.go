@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"api-flow/dto"
+	"api-flow/engine/definition"
 	"api-flow/services"
 )
 
@@ -129,25 +132,94 @@ func (h *WorkflowHandler) ExecuteWorkflow(c *gin.Context) {
 		return
 	}
 
-	var result *dto.WorkflowExecutionResult
-	var err error
-	if request.Sync {
-		// 执行同步工作流
-		result, err = h.workflowService.ExecuteWorkflow(&request)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// async 为 true 时在后台执行, 返回运行中的实例, 执行过程通过实例的事件流查看
+	result, err := h.workflowService.ExecuteWorkflow(&request)
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	if request.Async {
+		c.JSON(http.StatusAccepted, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExecuteByID 同步执行指定工作流, 请求体即为流程入参; mock=true 时使用模拟接口, record=true 时录制出站请求,
+// dryRun=true 时只渲染请求, startNode 与 fromInstance 同时指定时只执行该节点及其后续节点, async=true 时在后台执行
+func (h *WorkflowHandler) ExecuteByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var fromInstance uint64
+	if value := c.Query("fromInstance"); value != "" {
+		if fromInstance, err = strconv.ParseUint(value, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的fromInstance参数"})
 			return
 		}
-	} else {
-		// 执行异步工作流
-		log.Fatalln("异步执行工作流的逻辑尚未实现")
-		c.JSON(http.StatusNotImplemented, gin.H{"error": "异步执行工作流的逻辑尚未实现"})
+	}
+
+	inputs := make(map[string]interface{})
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&inputs); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入参数"})
+			return
+		}
+	}
+
+	async := c.Query("async") == "true"
+	result, err := h.workflowService.ExecuteWorkflow(&dto.WorkflowExecutionRequest{
+		WorkflowID:   uint(id),
+		Async:        async,
+		Inputs:       inputs,
+		Mock:         c.Query("mock") == "true",
+		Record:       c.Query("record") == "true",
+		DryRun:       c.Query("dryRun") == "true",
+		StartNode:    c.Query("startNode"),
+		FromInstance: uint(fromInstance),
+	})
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	if async {
+		c.JSON(http.StatusAccepted, result)
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// OpenAPI 生成已发布工作流的 OpenAPI 文档, format=json|yaml
+func (h *WorkflowHandler) OpenAPI(c *gin.Context) {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	serverURL := fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+
+	doc, err := h.workflowService.GenerateOpenAPI(serverURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.DefaultQuery("format", "json") == "yaml" {
+		data, err := doc.YAML()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/x-yaml; charset=utf-8", data)
+		return
+	}
+	c.JSON(http.StatusOK, doc)
+}
+
 func (h *WorkflowHandler) PublishWorkflow(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -185,4 +257,82 @@ func (h *WorkflowHandler) GetWorkflowInstances(c *gin.Context) {
 		"statistics": statistics,
 		"data":  instanceList,
 	})
-}
\ No newline at end of file
+}
+
+// Export 导出工作流定义文件
+func (h *WorkflowHandler) Export(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	format, err := definition.ParseFormat(c.DefaultQuery("format", "yaml"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	def, err := h.workflowService.ExportWorkflow(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := definition.Marshal(def, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("workflow-%d.%s", id, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, format.ContentType(), data)
+}
+
+// Import 导入工作流定义文件, 请求体为定义文件内容
+// 格式由 format 参数指定, 未指定时根据 Content-Type 判断; validateOnly=true 时只校验不保存
+func (h *WorkflowHandler) Import(c *gin.Context) {
+	formatName := c.Query("format")
+	if formatName == "" && strings.Contains(c.ContentType(), "json") {
+		formatName = "json"
+	}
+	format, err := definition.ParseFormat(formatName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	def, err := definition.Unmarshal(data, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	validateOnly := c.Query("validateOnly") == "true"
+	result, err := h.workflowService.ImportWorkflow(def, validateOnly)
+	if err == services.ErrInvalidDefinition {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":    err.Error(),
+			"problems": result.Problems,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "工作流导入成功"
+	if validateOnly {
+		message = "工作流定义校验通过"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    result,
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/dto"
+	"api-flow/engine/core"
+	"api-flow/services"
+)
+
+// GetNodeExecutions 分页获取流程实例的节点执行记录, 可按 nodeKey 与 status 筛选
+func (h *WorkflowHandler) GetNodeExecutions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("instanceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 20
+	}
+	query := services.NodeExecutionQuery{NodeKey: c.Query("nodeKey")}
+	if value := c.Query("status"); value != "" {
+		status, err := strconv.ParseUint(value, 10, 8)
+		if err != nil || core.ExecuteStatus(status) > core.ExecuteStatusError {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的status参数"})
+			return
+		}
+		executeStatus := core.ExecuteStatus(status)
+		query.Status = &executeStatus
+	}
+	executions, total, err := h.workflowService.GetNodeExecutions(uint(id), query, page, size)
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  executions,
+		"total": total,
+	})
+}
+
+// GetRecording 获取流程实例录制的出站请求与响应
+func (h *WorkflowHandler) GetRecording(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("instanceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	recording, err := h.workflowService.GetRecording(uint(id))
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recording)
+}
+
+// ReplayInstance 使用录制的响应重新执行流程实例
+func (h *WorkflowHandler) ReplayInstance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("instanceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	result, err := h.workflowService.ReplayInstance(uint(id))
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RerunInstance 使用原实例的输入参数重新执行, 请求体可覆盖输入参数并选择工作流版本
+func (h *WorkflowHandler) RerunInstance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("instanceId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var request dto.WorkflowRerunRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	result, err := h.workflowService.RerunInstance(uint(id), &request)
+	if err != nil {
+		instanceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func instanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRerun), errors.Is(err, services.ErrInvalidExecution),
+		errors.Is(err, services.ErrNoDefinitionSnapshot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInstanceNotFound), errors.Is(err, services.ErrRecordingNotFound),
+		errors.Is(err, services.ErrEventsNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"api-flow/engine"
+	"api-flow/services"
+)
+
+// ListTestCases 获取工作流的测试用例
+func (h *WorkflowHandler) ListTestCases(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	testCases, err := h.workflowService.ListTestCases(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": testCases})
+}
+
+// CreateTestCase 创建测试用例
+func (h *WorkflowHandler) CreateTestCase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var testCase engine.TestCase
+	if err := c.ShouldBindJSON(&testCase); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.workflowService.CreateTestCase(uint(id), &testCase); err != nil {
+		testCaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "测试用例创建成功",
+		"id":      testCase.ID,
+	})
+}
+
+// UpdateTestCase 更新测试用例
+func (h *WorkflowHandler) UpdateTestCase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	var testCase engine.TestCase
+	if err := c.ShouldBindJSON(&testCase); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.workflowService.UpdateTestCase(uint(id), &testCase); err != nil {
+		testCaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "测试用例更新成功"})
+}
+
+// DeleteTestCase 删除测试用例
+func (h *WorkflowHandler) DeleteTestCase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	if err := h.workflowService.DeleteTestCase(uint(id)); err != nil {
+		testCaseError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "测试用例删除成功"})
+}
+
+// RunTestCase 执行单个测试用例
+func (h *WorkflowHandler) RunTestCase(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	report, err := h.workflowService.RunTestCase(uint(id))
+	if err != nil {
+		testCaseError(c, err)
+		return
+	}
+	writeTestReport(c, report, fmt.Sprintf("test-case-%d.xml", id))
+}
+
+// RunTestCases 执行工作流的全部测试用例
+func (h *WorkflowHandler) RunTestCases(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID参数"})
+		return
+	}
+	report, err := h.workflowService.RunTestCases(uint(id))
+	if err != nil {
+		testCaseError(c, err)
+		return
+	}
+	writeTestReport(c, report, fmt.Sprintf("workflow-%d-tests.xml", id))
+}
+
+// writeTestReport 输出测试报告, format=junit 时输出 JUnit XML
+func writeTestReport(c *gin.Context, report *engine.TestReport, filename string) {
+	if c.Query("format") != "junit" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	data, err := report.JUnitXML()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+func testCaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTestCase):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTestCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
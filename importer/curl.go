@@ -0,0 +1,366 @@
+package importer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SupportedMethods API 节点支持的请求方法
+var SupportedMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH"}
+
+// IsSupportedMethod 判断 API 节点是否支持该请求方法
+func IsSupportedMethod(method string) bool {
+	for _, m := range SupportedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// 不影响请求内容、直接忽略的 curl 参数
+var curlIgnoredFlags = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true,
+	"-k": true, "--insecure": true, "-L": true, "--location": true,
+	"-i": true, "--include": true, "-v": true, "--verbose": true,
+	"--compressed": true, "-g": true, "--globoff": true, "-f": true, "--fail": true,
+	"-N": true, "--no-buffer": true, "--http1.1": true, "--http2": true,
+}
+
+// 需要一个值但不影响请求内容的 curl 参数
+var curlIgnoredValueFlags = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "-w": true, "--write-out": true,
+	"--retry": true, "-x": true, "--proxy": true, "--resolve": true,
+}
+
+// ParseCurl 解析 cURL 命令(支持浏览器开发者工具 "Copy as cURL" 的 bash 格式)
+func ParseCurl(command string) (*Request, error) {
+	args, err := splitShellArgs(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("不是有效的 curl 命令")
+	}
+
+	request := newRequest()
+	var (
+		rawURL  string
+		data    []string
+		form    = url.Values{}
+		hasForm bool
+		useGet  bool
+	)
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		next := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("参数 %s 缺少值", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		// 支持 --header=value 的写法
+		if strings.HasPrefix(arg, "--") && strings.Contains(arg, "=") {
+			parts := strings.SplitN(arg, "=", 2)
+			arg = parts[0]
+			args = append(args[:i+1], append([]string{parts[1]}, args[i+1:]...)...)
+		}
+
+		switch {
+		case arg == "-X" || arg == "--request":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.Method = strings.ToUpper(value)
+		case arg == "-H" || arg == "--header":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.SplitN(value, ":", 2)
+			if len(parts) == 2 {
+				request.SetHeader(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+			}
+		case arg == "-d" || arg == "--data" || arg == "--data-raw" || arg == "--data-binary" || arg == "--data-ascii":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(value, "@") && arg != "--data-raw" {
+				return nil, fmt.Errorf("不支持从文件读取请求体: %s", value)
+			}
+			data = append(data, value)
+		case arg == "--data-urlencode":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, encodeCurlData(value))
+		case arg == "--json":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, value)
+			if request.Header("Content-Type") == "" {
+				request.SetHeader("Content-Type", "application/json")
+			}
+			if request.Header("Accept") == "" {
+				request.SetHeader("Accept", "application/json")
+			}
+		case arg == "-F" || arg == "--form" || arg == "--form-string":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.SplitN(value, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("无效的表单参数: %s", value)
+			}
+			name, content := parts[0], parts[1]
+			if arg != "--form-string" && (strings.HasPrefix(content, "@") || strings.HasPrefix(content, "<")) {
+				// 文件在执行 curl 的机器上, 服务端无法读取
+				request.Warnings = append(request.Warnings, fmt.Sprintf("已忽略文件字段 %s(%s), 请在节点中上传文件内容", name, content))
+				hasForm = true
+				continue
+			}
+			if arg != "--form-string" {
+				// 去掉 ;type=... 等字段属性
+				content = strings.SplitN(content, ";", 2)[0]
+			}
+			form.Add(name, content)
+			hasForm = true
+		case arg == "-u" || arg == "--user":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.SetHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(value)))
+		case arg == "-b" || arg == "--cookie":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.SetHeader("Cookie", value)
+		case arg == "-A" || arg == "--user-agent":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.SetHeader("User-Agent", value)
+		case arg == "-e" || arg == "--referer":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.SetHeader("Referer", value)
+		case arg == "--url":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			rawURL = value
+		case arg == "-G" || arg == "--get":
+			useGet = true
+		case arg == "-I" || arg == "--head":
+			request.Method = "HEAD"
+		case curlIgnoredFlags[arg]:
+		case curlIgnoredValueFlags[arg]:
+			if _, err := next(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(arg, "-"):
+			return nil, fmt.Errorf("不支持的 curl 参数: %s", arg)
+		default:
+			rawURL = arg
+		}
+	}
+
+	if rawURL == "" {
+		return nil, errors.New("curl 命令中缺少 URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	if err := request.setURL(rawURL); err != nil {
+		return nil, fmt.Errorf("无效的 URL: %v", err)
+	}
+
+	body := strings.Join(data, "&")
+	if useGet && body != "" {
+		query, err := url.ParseQuery(body)
+		if err != nil {
+			return nil, fmt.Errorf("无效的查询参数: %v", err)
+		}
+		for name, values := range query {
+			request.Params[name] = append(request.Params[name], values...)
+		}
+		body = ""
+	}
+	if hasForm {
+		// multipart 请求体由节点执行时生成, 使用生成的 boundary
+		body = ""
+		request.Form = form
+		for key := range request.Headers {
+			if strings.EqualFold(key, "Content-Type") {
+				delete(request.Headers, key)
+			}
+		}
+	}
+	if body != "" && len(data) > 0 && request.Header("Content-Type") == "" {
+		request.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	request.Body = body
+	if request.Method == "" {
+		if body != "" || hasForm {
+			request.Method = "POST"
+		} else {
+			request.Method = "GET"
+		}
+	}
+	if !IsSupportedMethod(request.Method) {
+		return nil, fmt.Errorf("API节点不支持的请求方法: %s", request.Method)
+	}
+	return request, nil
+}
+
+// encodeCurlData 按 curl --data-urlencode 的规则编码: name=content 只编码 content, 否则编码全部内容
+func encodeCurlData(value string) string {
+	if index := strings.Index(value, "="); index > 0 {
+		return value[:index+1] + url.QueryEscape(value[index+1:])
+	}
+	return url.QueryEscape(strings.TrimPrefix(value, "="))
+}
+
+// splitShellArgs 按 shell 规则拆分命令行, 支持单引号、双引号、$'...' 与反斜杠续行
+func splitShellArgs(command string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg := false
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' || runes[i] == '\r' {
+					// 续行
+					if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+						i++
+					}
+					continue
+				}
+				current.WriteRune(runes[i])
+				inArg = true
+			}
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, errors.New("单引号未闭合")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inArg = true
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			value, end, err := readANSIQuoted(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			i = end
+			inArg = true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("双引号未闭合")
+			}
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func indexRune(runes []rune, start int, target rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// readANSIQuoted 读取 $'...' 形式的字符串, 处理常见转义
+func readANSIQuoted(runes []rune, start int) (string, int, error) {
+	var builder strings.Builder
+	for i := start; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\'' {
+			return builder.String(), i, nil
+		}
+		if r == '\\' && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case 'n':
+				builder.WriteRune('\n')
+			case 't':
+				builder.WriteRune('\t')
+			case 'r':
+				builder.WriteRune('\r')
+			case 'u':
+				if i+4 < len(runes) {
+					if decoded, err := decodeHexRune(string(runes[i+1 : i+5])); err == nil {
+						builder.WriteRune(decoded)
+						i += 4
+						continue
+					}
+				}
+				builder.WriteString("\\u")
+			default:
+				builder.WriteRune(runes[i])
+			}
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return "", 0, errors.New("$'...' 引号未闭合")
+}
+
+func decodeHexRune(hex string) (rune, error) {
+	var value rune
+	if _, err := fmt.Sscanf(hex, "%04x", &value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
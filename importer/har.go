@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// harFile HAR 1.2 文件中用到的字段
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method      string         `json:"method"`
+				URL         string         `json:"url"`
+				Headers     []harNameValue `json:"headers"`
+				QueryString []harNameValue `json:"queryString"`
+				PostData    *struct {
+					MimeType string         `json:"mimeType"`
+					Text     string         `json:"text"`
+					Params   []harNameValue `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// 由客户端或传输层自动生成、不需要保存到节点配置的请求头
+var harSkippedHeaders = map[string]bool{
+	"content-length":    true,
+	"host":              true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// HAREntryError 跳过的 HAR 条目
+type HAREntryError struct {
+	Index   int    `json:"index"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
+
+// ParseHAR 解析 HAR 文件, filter 不为空时只保留 URL 包含该字符串的条目
+// 不支持的请求(如 OPTIONS 预检请求)会被跳过并在第二个返回值中说明
+func ParseHAR(data []byte, filter string) ([]*Request, []HAREntryError, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, nil, fmt.Errorf("解析HAR文件失败: %v", err)
+	}
+
+	requests := make([]*Request, 0)
+	skipped := make([]HAREntryError, 0)
+	for index, entry := range har.Log.Entries {
+		req := entry.Request
+		if filter != "" && !strings.Contains(req.URL, filter) {
+			continue
+		}
+		method := strings.ToUpper(req.Method)
+		if !IsSupportedMethod(method) {
+			skipped = append(skipped, HAREntryError{Index: index, URL: req.URL, Message: "不支持的请求方法 " + method})
+			continue
+		}
+		request := newRequest()
+		request.Method = method
+		if err := request.setURL(req.URL); err != nil {
+			skipped = append(skipped, HAREntryError{Index: index, URL: req.URL, Message: "无效的URL"})
+			continue
+		}
+		if len(req.QueryString) > 0 {
+			// queryString 为已解码的查询参数, 以它为准
+			request.Params = url.Values{}
+			for _, param := range req.QueryString {
+				request.Params.Add(param.Name, param.Value)
+			}
+		}
+		for _, header := range req.Headers {
+			// 跳过 HTTP/2 伪请求头(:authority 等)
+			if strings.HasPrefix(header.Name, ":") || harSkippedHeaders[strings.ToLower(header.Name)] {
+				continue
+			}
+			request.SetHeader(header.Name, header.Value)
+		}
+		if postData := req.PostData; postData != nil {
+			request.Body = postData.Text
+			if request.Body == "" && len(postData.Params) > 0 {
+				values := url.Values{}
+				for _, param := range postData.Params {
+					values.Add(param.Name, param.Value)
+				}
+				request.Body = values.Encode()
+			}
+			if postData.MimeType != "" && request.Header("Content-Type") == "" {
+				request.SetHeader("Content-Type", postData.MimeType)
+			}
+		}
+		requests = append(requests, request)
+	}
+	return requests, skipped, nil
+}
@@ -0,0 +1,121 @@
+// Package importer 将抓取到的请求(cURL 命令、HAR 文件)转换为 API 节点配置
+package importer
+
+import (
+	"net/url"
+	"strings"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// Request 解析得到的 HTTP 请求
+type Request struct {
+	Method string
+	// URL 不含查询参数, 查询参数保存在 Params 中
+	URL     string
+	Params  url.Values
+	Headers map[string]string
+	Body    string
+	// Form multipart 表单字段, 不为空时以 multipart 请求体发送
+	Form url.Values
+	// Warnings 转换时忽略的内容, 如无法读取的上传文件
+	Warnings []string
+}
+
+func newRequest() *Request {
+	return &Request{
+		Params:   url.Values{},
+		Headers:  make(map[string]string),
+		Warnings: []string{},
+	}
+}
+
+// setURL 设置请求地址, URL 中的查询参数拆分到 Params
+func (r *Request) setURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	for name, values := range u.Query() {
+		r.Params[name] = append(r.Params[name], values...)
+	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+	r.URL = u.String()
+	return nil
+}
+
+// SetHeader 设置请求头, 同名请求头(忽略大小写)以后设置的为准
+func (r *Request) SetHeader(name, value string) {
+	for key := range r.Headers {
+		if strings.EqualFold(key, name) {
+			delete(r.Headers, key)
+		}
+	}
+	r.Headers[name] = value
+}
+
+// Header 获取请求头(忽略大小写)
+func (r *Request) Header(name string) string {
+	for key, value := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// Name 节点名称, 格式为 METHOD /path
+func (r *Request) Name() string {
+	path := r.URL
+	if u, err := url.Parse(r.URL); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	return r.Method + " " + path
+}
+
+// NodeConfig 转换为 API 节点配置
+func (r *Request) NodeConfig() core.ItemConfig {
+	headers := make(map[string]interface{}, len(r.Headers))
+	for key, value := range r.Headers {
+		headers[key] = value
+	}
+	config := core.ItemConfig{
+		"url":           r.URL,
+		"method":        r.Method,
+		"headers":       headers,
+		"body":          r.Body,
+		"params":        configValues(r.Params),
+		"timeout":       30,
+		"retry":         0,
+		"retryInterval": 5,
+	}
+	if len(r.Form) > 0 {
+		config["bodyType"] = engine_nodes.BodyTypeMultipart
+		config["body"] = configValues(r.Form)
+	}
+	return config
+}
+
+// configValues 转换为节点配置中的参数对象, 同名的多个值为数组
+func configValues(values url.Values) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for name, items := range values {
+		if len(items) == 1 {
+			result[name] = items[0]
+			continue
+		}
+		list := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			list = append(list, item)
+		}
+		result[name] = list
+	}
+	return result
+}
+
+// Node 转换为预配置的 API 节点(未保存)
+func (r *Request) Node() engine_nodes.Node {
+	return engine_nodes.NewAPINode(r.Name(), "", r.NodeConfig())
+}
@@ -3,21 +3,37 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
+	"api-flow/cli"
 	"api-flow/config"
 	"api-flow/database"
 	"api-flow/engine"
 	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+	"api-flow/mock"
 	"api-flow/router"
+	"api-flow/secrets"
 )
 
 func main() {
+	// 命令行执行工作流定义文件, 不连接数据库也不启动服务
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(cli.Run(os.Args[2:]))
+	}
+
 	// 加载配置
 	cfg, err := config.LoadConfig("config/config.yaml")
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
 
+	// 注册密钥
+	secrets.Register(cfg.Secrets)
+
+	// 出站请求限流与熔断
+	outbound.Configure(cfg.Outbound)
+
 	// 初始化数据库连接
 	if err := database.Initialize(cfg.GetDSN()); err != nil {
 		log.Fatalf("数据库连接失败: %v", err)
@@ -48,6 +64,26 @@ func main() {
 		log.Fatalf("流程实例表迁移失败: %v", err)
 	}
 
+	if err = engine.MigrateInstanceRecording(database.DB); err != nil {
+		log.Fatalf("实例录制表迁移失败: %v", err)
+	}
+
+	if err = engine.MigrateNodeExecution(database.DB); err != nil {
+		log.Fatalf("节点执行记录表迁移失败: %v", err)
+	}
+
+	if err = engine.MigrateInstanceEvents(database.DB); err != nil {
+		log.Fatalf("执行事件表迁移失败: %v", err)
+	}
+
+	if err = engine.MigrateTestCase(database.DB); err != nil {
+		log.Fatalf("测试用例表迁移失败: %v", err)
+	}
+
+	if err = mock.MigrateRoute(database.DB); err != nil {
+		log.Fatalf("模拟接口表迁移失败: %v", err)
+	}
+
 	// 设置路由
 	r := router.SetupRouter()
 
@@ -0,0 +1,111 @@
+// Package mock 模拟接口, 在真实的上游接口就绪前构建与演示工作流, 或在隔离的测试环境中执行工作流
+//
+// 模拟接口由 api-flow 进程在 /mock 前缀下提供, 执行工作流时开启 mock 后,
+// 节点发出的 HTTP 请求不经过网络, 按请求路径直接由模拟接口处理。
+package mock
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine/core"
+)
+
+// 多个响应的选择方式
+const (
+	ModeSequential  = "sequential"  // 按调用次数依次返回, 用完后重复最后一个
+	ModeConditional = "conditional" // 返回第一个满足条件的响应
+)
+
+// 条件取值的位置
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InBody   = "body"
+)
+
+// Route 模拟接口
+type Route struct {
+	core.BasicModel
+	Name string `gorm:"size:255" json:"name"`
+	// Method 请求方法, 为空或 * 时匹配任意方法
+	Method string `gorm:"size:16" json:"method"`
+	// Path 路径模式, 支持 /users/:id、/users/{id} 与 /files/*path
+	Path      string    `gorm:"size:1000;not null" json:"path"`
+	Mode      string    `gorm:"size:20" json:"mode"`
+	Responses Responses `gorm:"type:json" json:"responses"`
+}
+
+// TableName 指定表名
+func (Route) TableName() string {
+	return "mock_routes"
+}
+
+// Response 模拟响应, body 中的字符串按模板渲染
+type Response struct {
+	// When 条件模式下选择该响应的条件, 全部满足才匹配, 为空时总是匹配
+	When    []Condition       `json:"when,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body"`
+	// Delay 返回前等待的时间（秒）
+	Delay float64 `json:"delay,omitempty"`
+}
+
+// Condition 请求需要满足的条件, 取值按字符串比较
+type Condition struct {
+	In string `json:"in"`
+	// Name 参数名, in 为 body 时为 JSONPath(如 $.user.id)
+	Name     string      `json:"name"`
+	Equals   interface{} `json:"equals,omitempty"`
+	Contains *string     `json:"contains,omitempty"`
+	Regex    string      `json:"regex,omitempty"`
+	Exists   *bool       `json:"exists,omitempty"`
+}
+
+// Responses 以 JSON 保存的响应列表
+type Responses []Response
+
+// Value 实现 sql.Valuer 接口
+func (r Responses) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	bytes, err := json.Marshal(r)
+	return string(bytes), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (r *Responses) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return json.Unmarshal(bytes, r)
+}
+
+// Validate 检查模拟接口的配置
+func (r *Route) Validate() error {
+	_, err := compileRoute(r)
+	return err
+}
+
+// MigrateRoute 创建模拟接口表
+func MigrateRoute(db *gorm.DB) error {
+	return db.AutoMigrate(&Route{}).Error
+}
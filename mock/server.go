@@ -0,0 +1,515 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"api-flow/engine/jsonpath"
+	"api-flow/engine/outbound"
+)
+
+// 读取请求体的上限
+const maxRequestBody = 10 << 20
+
+// Server 按路径与方法匹配模拟接口并返回模拟响应, 实现 http.Handler
+type Server struct {
+	mu     sync.Mutex
+	routes []*compiledRoute
+	calls  map[int]int // 各模拟接口的调用次数, 依次返回时使用
+}
+
+// NewServer 创建模拟接口服务
+func NewServer() *Server {
+	return &Server{calls: make(map[int]int)}
+}
+
+var defaultServer = NewServer()
+
+// Default 进程内共享的模拟接口服务
+func Default() *Server {
+	return defaultServer
+}
+
+// Redirect 返回的上下文中节点发出的 HTTP 请求都由模拟接口处理
+func (s *Server) Redirect(ctx context.Context) context.Context {
+	return outbound.WithHandler(ctx, s)
+}
+
+// SetRoutes 替换全部模拟接口, 调用次数同时清零
+func (s *Server) SetRoutes(routes []Route) error {
+	compiled := make([]*compiledRoute, 0, len(routes))
+	for i := range routes {
+		route, err := compileRoute(&routes[i])
+		if err != nil {
+			return fmt.Errorf("模拟接口 %s %s: %v", routes[i].Method, routes[i].Path, err)
+		}
+		compiled = append(compiled, route)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = compiled
+	s.calls = make(map[int]int)
+	return nil
+}
+
+// Reset 清零调用次数, 依次返回的模拟接口重新从第一个响应开始
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = make(map[int]int)
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	index, route, params := s.match(r.Method, r.URL.Path)
+	if route == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("没有匹配的模拟接口: %s %s", r.Method, r.URL.Path))
+		return
+	}
+	s.mu.Lock()
+	s.calls[index]++
+	count := s.calls[index]
+	s.mu.Unlock()
+
+	data, err := requestData(r, params, count)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	response := route.choose(count, data)
+	if response == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("模拟接口 %s %s 没有满足条件的响应", route.method, route.path))
+		return
+	}
+	body, err := renderValue(response.Body, data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("渲染模拟响应失败: %v", err))
+		return
+	}
+
+	if response.Delay > 0 {
+		select {
+		case <-time.After(time.Duration(response.Delay * float64(time.Second))):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var content []byte
+	contentType := "application/json"
+	switch v := body.(type) {
+	case nil:
+	case string:
+		content = []byte(v)
+		contentType = "text/plain; charset=utf-8"
+	default:
+		if content, err = json.Marshal(v); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("序列化模拟响应失败: %v", err))
+			return
+		}
+	}
+	for key, value := range response.Headers {
+		rendered, err := renderString(value, data)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("渲染响应头 %s 失败: %v", key, err))
+			return
+		}
+		w.Header().Set(key, rendered)
+	}
+	if content != nil && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(content)
+}
+
+// match 查找匹配的模拟接口, 多个接口匹配时选择固定段最多的
+func (s *Server) match(method, path string) (int, *compiledRoute, map[string]string) {
+	s.mu.Lock()
+	routes := s.routes
+	s.mu.Unlock()
+
+	var (
+		bestIndex  = -1
+		bestRoute  *compiledRoute
+		bestParams map[string]string
+	)
+	for i, route := range routes {
+		if route.method != "" && route.method != strings.ToUpper(method) {
+			continue
+		}
+		params, ok := route.matchPath(path)
+		if !ok {
+			continue
+		}
+		if bestRoute == nil || route.static > bestRoute.static {
+			bestIndex, bestRoute, bestParams = i, route, params
+		}
+	}
+	return bestIndex, bestRoute, bestParams
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// compiledRoute 解析后的模拟接口
+type compiledRoute struct {
+	method    string
+	path      string
+	mode      string
+	segments  []pathSegment
+	static    int
+	responses []compiledResponse
+}
+
+// pathSegment 路径模式的一段: 固定值、参数(:id 或 {id})或通配(*path, 匹配剩余路径)
+type pathSegment struct {
+	literal  string
+	param    string
+	wildcard bool
+}
+
+type compiledResponse struct {
+	*Response
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	*Condition
+	path  *jsonpath.Path
+	regex *regexp.Regexp
+}
+
+var validMethods = map[string]bool{
+	"": true, "GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+func compileRoute(r *Route) (*compiledRoute, error) {
+	method := strings.ToUpper(r.Method)
+	if method == "*" {
+		method = ""
+	}
+	if !validMethods[method] {
+		return nil, fmt.Errorf("不支持的请求方法 %s", r.Method)
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return nil, fmt.Errorf("路径必须以 / 开头")
+	}
+	mode := r.Mode
+	if mode == "" {
+		mode = ModeSequential
+	}
+	if mode != ModeSequential && mode != ModeConditional {
+		return nil, fmt.Errorf("不支持的模式 %s, 可选 %s、%s", r.Mode, ModeSequential, ModeConditional)
+	}
+	if len(r.Responses) == 0 {
+		return nil, fmt.Errorf("至少需要一个响应")
+	}
+
+	route := &compiledRoute{method: method, path: r.Path, mode: mode}
+	parts := splitPath(r.Path)
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "*"):
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("通配段 %s 只能出现在路径末尾", part)
+			}
+			route.segments = append(route.segments, pathSegment{param: part[1:], wildcard: true})
+		case strings.HasPrefix(part, ":"):
+			route.segments = append(route.segments, pathSegment{param: part[1:]})
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			route.segments = append(route.segments, pathSegment{param: part[1 : len(part)-1]})
+		default:
+			route.segments = append(route.segments, pathSegment{literal: part})
+			route.static++
+		}
+	}
+
+	for i := range r.Responses {
+		response := compiledResponse{Response: &r.Responses[i]}
+		if response.Status != 0 && (response.Status < 100 || response.Status > 599) {
+			return nil, fmt.Errorf("第%d个响应的状态码 %d 无效", i+1, response.Status)
+		}
+		for j := range response.When {
+			condition, err := compileCondition(&response.When[j])
+			if err != nil {
+				return nil, fmt.Errorf("第%d个响应的第%d个条件: %v", i+1, j+1, err)
+			}
+			response.conditions = append(response.conditions, condition)
+		}
+		route.responses = append(route.responses, response)
+	}
+	return route, nil
+}
+
+func compileCondition(c *Condition) (compiledCondition, error) {
+	condition := compiledCondition{Condition: c}
+	switch c.In {
+	case InPath, InQuery, InHeader:
+		if c.Name == "" {
+			return condition, fmt.Errorf("name 不能为空")
+		}
+	case InBody:
+		expr := c.Name
+		if expr == "" {
+			expr = "$"
+		}
+		path, err := jsonpath.Compile(expr)
+		if err != nil {
+			return condition, err
+		}
+		condition.path = path
+	default:
+		return condition, fmt.Errorf("in 必须是 %s、%s、%s 或 %s", InPath, InQuery, InHeader, InBody)
+	}
+	if c.Regex != "" {
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return condition, fmt.Errorf("无效的正则表达式 %s: %v", c.Regex, err)
+		}
+		condition.regex = re
+	}
+	return condition, nil
+}
+
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// matchPath 匹配请求路径, 返回路径参数
+func (r *compiledRoute) matchPath(path string) (map[string]string, bool) {
+	parts := splitPath(path)
+	params := make(map[string]string)
+	for i, segment := range r.segments {
+		if segment.wildcard {
+			params[segment.param] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if segment.param != "" {
+			value, err := url.PathUnescape(parts[i])
+			if err != nil {
+				value = parts[i]
+			}
+			params[segment.param] = value
+		} else if segment.literal != parts[i] {
+			return nil, false
+		}
+	}
+	return params, len(parts) == len(r.segments)
+}
+
+// choose 选择本次返回的响应
+func (r *compiledRoute) choose(count int, data map[string]interface{}) *Response {
+	if r.mode == ModeSequential {
+		index := count - 1
+		if index >= len(r.responses) {
+			index = len(r.responses) - 1
+		}
+		return r.responses[index].Response
+	}
+	for _, response := range r.responses {
+		matched := true
+		for _, condition := range response.conditions {
+			if !condition.match(data) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return response.Response
+		}
+	}
+	return nil
+}
+
+// match 检查请求是否满足条件
+func (c *compiledCondition) match(data map[string]interface{}) bool {
+	var (
+		value interface{}
+		found bool
+	)
+	switch c.In {
+	case InBody:
+		value, found = c.path.Lookup(data["body"])
+	case InPath:
+		value, found = data["params"].(map[string]interface{})[c.Name]
+	case InQuery:
+		value, found = data["query"].(map[string]interface{})[c.Name]
+	case InHeader:
+		value, found = data["headers"].(map[string]interface{})[http.CanonicalHeaderKey(c.Name)]
+	}
+
+	if c.Exists != nil {
+		return *c.Exists == found
+	}
+	if !found {
+		return false
+	}
+	text := stringify(value)
+	if c.Equals != nil && text != stringify(c.Equals) {
+		return false
+	}
+	if c.Contains != nil && !strings.Contains(text, *c.Contains) {
+		return false
+	}
+	if c.regex != nil && !c.regex.MatchString(text) {
+		return false
+	}
+	return true
+}
+
+// requestData 模板与条件使用的请求数据:
+// method、path、params(路径参数)、query、headers(取第一个值)、body(JSON 或表单解析为对象, 否则为字符串)、count(第几次调用)
+func requestData(r *http.Request, params map[string]string, count int) (map[string]interface{}, error) {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody))
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %v", err)
+	}
+
+	var body interface{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case len(raw) == 0:
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("解析表单失败: %v", err)
+		}
+		body = firstValues(form)
+	default:
+		if err := json.Unmarshal(raw, &body); err != nil {
+			body = string(raw)
+		}
+	}
+
+	pathParams := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		pathParams[key] = value
+	}
+	return map[string]interface{}{
+		"method":  r.Method,
+		"path":    r.URL.Path,
+		"params":  pathParams,
+		"query":   firstValues(r.URL.Query()),
+		"headers": firstValues(r.Header),
+		"body":    body,
+		"count":   count,
+	}, nil
+}
+
+func firstValues(values map[string][]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, list := range values {
+		if len(list) > 0 {
+			result[key] = list[0]
+		}
+	}
+	return result
+}
+
+// wholeReference 只引用一个字段的模板, 如 {{.body.user}}
+var wholeReference = regexp.MustCompile(`^\{\{\s*\.([A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*)\s*\}\}$`)
+
+// renderValue 渲染响应体, 字符串按模板渲染; 只引用一个字段的字符串保留字段的原始类型
+func renderValue(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if m := wholeReference.FindStringSubmatch(v); m != nil {
+			if field, ok := lookupField(data, m[1]); ok {
+				return field, nil
+			}
+		}
+		return renderString(v, data)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = rendered
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = rendered
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+func lookupField(data map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = data
+	for _, name := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func renderString(tpl string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(tpl, "{{") {
+		return tpl, nil
+	}
+	t, err := template.New("mock").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// stringify 条件比较使用的字符串形式, 对象与数组序列化为 JSON
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
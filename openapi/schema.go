@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"strings"
+
+	"api-flow/engine/core"
+)
+
+// SchemaFromParam 将参数定义转换为 Schema
+func SchemaFromParam(param *core.ParamDefination) *Schema {
+	schema := &Schema{
+		Description: param.Description,
+		Default:     param.Default,
+	}
+	switch param.Datatype {
+	case core.DataTypeString:
+		schema.Type = "string"
+	case core.DataTypeNumber:
+		schema.Type = "number"
+	case core.DataTypeBoolean:
+		schema.Type = "boolean"
+	case core.DataTypeArray:
+		schema.Type = "array"
+		schema.Items = &Schema{}
+	case core.DataTypeObject:
+		schema.Type = "object"
+	case core.DataTypeOptions:
+		schema.Enum = param.Options
+		schema.Type = optionsType(param.Options)
+	case core.DataTypeNull:
+		schema.Nullable = true
+	}
+	if isEmptyDefault(param.Default) {
+		schema.Default = nil
+	}
+	return schema
+}
+
+// SchemaFromParamFormat 将参数格式转换为对象 Schema
+func SchemaFromParamFormat(format core.ParamFormat) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for _, param := range format {
+		if param == nil || param.Field == "" {
+			continue
+		}
+		schema.Properties[param.Field] = SchemaFromParam(param)
+	}
+	return schema
+}
+
+// ExampleFromParamFormat 根据参数格式生成示例值, 优先使用默认值
+func ExampleFromParamFormat(format core.ParamFormat) map[string]interface{} {
+	example := make(map[string]interface{})
+	for _, param := range format {
+		if param == nil || param.Field == "" {
+			continue
+		}
+		example[param.Field] = exampleValue(param)
+	}
+	return example
+}
+
+func exampleValue(param *core.ParamDefination) interface{} {
+	if !isEmptyDefault(param.Default) {
+		return param.Default
+	}
+	switch param.Datatype {
+	case core.DataTypeString:
+		return "string"
+	case core.DataTypeNumber:
+		return 0
+	case core.DataTypeBoolean:
+		return false
+	case core.DataTypeArray:
+		return []interface{}{}
+	case core.DataTypeObject:
+		return map[string]interface{}{}
+	case core.DataTypeOptions:
+		if len(param.Options) > 0 {
+			return param.Options[0]
+		}
+	}
+	return nil
+}
+
+// OutputsSchema 将工作流 nodeKey.field 形式的出参定义按节点分组, 生成以节点键为key的 Schema 与示例。
+// 节点键不含 ".", 按第一个 "." 拆分; 字段中的 "."(如导入节点的 response.id)表示嵌套对象
+func OutputsSchema(outputs core.ParamFormat) (*Schema, map[string]interface{}) {
+	grouped, order := groupByFirstDot(outputs)
+	schema := &Schema{
+		Type:        "object",
+		Description: "末端节点的输出, 以节点键为key",
+		Properties:  make(map[string]*Schema),
+	}
+	example := make(map[string]interface{})
+	for _, nodeKey := range order {
+		schema.Properties[nodeKey], example[nodeKey] = nestedSchema(grouped[nodeKey])
+	}
+	return schema, example
+}
+
+// nestedSchema 与 SchemaFromParamFormat 相同, 但带 "." 的字段生成嵌套对象
+func nestedSchema(format core.ParamFormat) (*Schema, map[string]interface{}) {
+	flat := make(core.ParamFormat, 0, len(format))
+	for _, param := range format {
+		if param != nil && !strings.Contains(param.Field, ".") {
+			flat = append(flat, param)
+		}
+	}
+	schema := SchemaFromParamFormat(flat)
+	example := ExampleFromParamFormat(flat)
+
+	nested, order := groupByFirstDot(format)
+	for _, name := range order {
+		child, childExample := nestedSchema(nested[name])
+		if declared, ok := schema.Properties[name]; ok {
+			child.Description = declared.Description
+		}
+		schema.Properties[name] = child
+		example[name] = childExample
+	}
+	return schema, example
+}
+
+// groupByFirstDot 按第一个 "." 前的部分分组, 组内字段去掉该前缀; 不含 "." 的字段被忽略
+func groupByFirstDot(format core.ParamFormat) (map[string]core.ParamFormat, []string) {
+	grouped := make(map[string]core.ParamFormat)
+	order := make([]string, 0)
+	for _, param := range format {
+		if param == nil {
+			continue
+		}
+		index := strings.Index(param.Field, ".")
+		if index < 0 {
+			continue
+		}
+		prefix := param.Field[:index]
+		field := *param
+		field.Field = param.Field[index+1:]
+		if _, ok := grouped[prefix]; !ok {
+			order = append(order, prefix)
+		}
+		grouped[prefix] = append(grouped[prefix], &field)
+	}
+	return grouped, order
+}
+
+// isEmptyDefault 空字符串默认值视为未设置(编辑器会把未填写的默认值保存为空字符串)
+func isEmptyDefault(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && str == ""
+}
+
+// optionsType 根据选项值推断类型
+func optionsType(options []interface{}) string {
+	if len(options) == 0 {
+		return ""
+	}
+	switch options[0].(type) {
+	case string:
+		return "string"
+	case float64, float32, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,466 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"api-flow/engine/core"
+)
+
+// Spec 上传的 OpenAPI 2(Swagger)/3 文档, 以通用结构保存以便同时兼容两个版本
+type Spec struct {
+	raw     map[string]interface{}
+	Version int
+}
+
+// SpecOperation 文档中的一个接口操作
+type SpecOperation struct {
+	ID          string   `json:"id"`
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	Parameters  []SpecParameter        `json:"-"`
+	BodyType    string                 `json:"-"` // 请求体内容类型
+	BodySchema  map[string]interface{} `json:"-"`
+	RespSchema  map[string]interface{} `json:"-"`
+	RespSummary string                 `json:"-"`
+}
+
+// SpecParameter 接口参数
+type SpecParameter struct {
+	Name        string
+	In          string // path, query, header, cookie, formData
+	Description string
+	Required    bool
+	Schema      map[string]interface{}
+}
+
+// supportedMethods API 节点支持的请求方法
+var supportedMethods = []string{"get", "post", "put", "delete", "patch"}
+
+// ParseSpec 解析 JSON 或 YAML 格式的 OpenAPI 文档
+func ParseSpec(data []byte) (*Spec, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var yamlRaw interface{}
+		if err := yaml.Unmarshal(data, &yamlRaw); err != nil {
+			return nil, fmt.Errorf("解析OpenAPI文档失败: %v", err)
+		}
+		m, ok := core.NormalizeYAML(yamlRaw).(map[string]interface{})
+		if !ok {
+			return nil, errors.New("解析OpenAPI文档失败: 文档必须是对象")
+		}
+		raw = m
+	}
+
+	spec := &Spec{raw: raw}
+	if version, ok := raw["openapi"].(string); ok && strings.HasPrefix(version, "3.") {
+		spec.Version = 3
+	} else if version, ok := raw["swagger"].(string); ok && strings.HasPrefix(version, "2.") {
+		spec.Version = 2
+	} else {
+		return nil, errors.New("不支持的文档版本, 仅支持 OpenAPI 2.0 与 3.x")
+	}
+	return spec, nil
+}
+
+// Title 文档标题
+func (s *Spec) Title() string {
+	info, _ := s.raw["info"].(map[string]interface{})
+	title, _ := info["title"].(string)
+	return title
+}
+
+// BaseURL 接口的基础地址, 3.x 取第一个 server, 2.0 由 schemes/host/basePath 拼接
+func (s *Spec) BaseURL() string {
+	if s.Version == 3 {
+		servers, _ := s.raw["servers"].([]interface{})
+		if len(servers) == 0 {
+			return ""
+		}
+		server, _ := servers[0].(map[string]interface{})
+		serverURL, _ := server["url"].(string)
+		// 替换 server 变量为默认值
+		if variables, ok := server["variables"].(map[string]interface{}); ok {
+			for name, v := range variables {
+				variable, _ := v.(map[string]interface{})
+				if def, ok := variable["default"]; ok {
+					serverURL = strings.Replace(serverURL, "{"+name+"}", fmt.Sprint(def), -1)
+				}
+			}
+		}
+		return strings.TrimSuffix(serverURL, "/")
+	}
+
+	host, _ := s.raw["host"].(string)
+	basePath, _ := s.raw["basePath"].(string)
+	scheme := "https"
+	if schemes, ok := s.raw["schemes"].([]interface{}); ok && len(schemes) > 0 {
+		scheme = fmt.Sprint(schemes[0])
+	}
+	if host == "" {
+		return strings.TrimSuffix(basePath, "/")
+	}
+	u := url.URL{Scheme: scheme, Host: host, Path: basePath}
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// Operations 列出文档中 API 节点支持的全部接口操作, 按路径与方法排序
+func (s *Spec) Operations() []*SpecOperation {
+	paths, _ := s.raw["paths"].(map[string]interface{})
+	pathKeys := make([]string, 0, len(paths))
+	for path := range paths {
+		pathKeys = append(pathKeys, path)
+	}
+	sort.Strings(pathKeys)
+
+	operations := make([]*SpecOperation, 0)
+	for _, path := range pathKeys {
+		pathItem, _ := s.resolve(paths[path]).(map[string]interface{})
+		if pathItem == nil {
+			continue
+		}
+		pathParams, _ := pathItem["parameters"].([]interface{})
+		for _, method := range supportedMethods {
+			op, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operations = append(operations, s.parseOperation(path, method, op, pathParams))
+		}
+	}
+	return operations
+}
+
+// FindOperations 按ID查找接口操作, 返回找到的操作与未找到的ID
+func (s *Spec) FindOperations(ids []string) ([]*SpecOperation, []string) {
+	byID := make(map[string]*SpecOperation)
+	for _, op := range s.Operations() {
+		byID[op.ID] = op
+	}
+	found := make([]*SpecOperation, 0, len(ids))
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if op, ok := byID[id]; ok {
+			found = append(found, op)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+func (s *Spec) parseOperation(path, method string, op map[string]interface{}, pathParams []interface{}) *SpecOperation {
+	operation := &SpecOperation{
+		Method: strings.ToUpper(method),
+		Path:   path,
+	}
+	operation.ID, _ = op["operationId"].(string)
+	if operation.ID == "" {
+		operation.ID = operation.Method + " " + path
+	}
+	operation.Summary, _ = op["summary"].(string)
+	operation.Description, _ = op["description"].(string)
+	if tags, ok := op["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			operation.Tags = append(operation.Tags, fmt.Sprint(tag))
+		}
+	}
+
+	// 操作级参数覆盖同名的路径级参数
+	params := make(map[string]SpecParameter)
+	order := make([]string, 0)
+	opParams, _ := op["parameters"].([]interface{})
+	for _, p := range append(append([]interface{}{}, pathParams...), opParams...) {
+		param, _ := s.resolve(p).(map[string]interface{})
+		if param == nil {
+			continue
+		}
+		parsed := s.parseParameter(param)
+		if parsed.In == "body" {
+			operation.BodyType = "application/json"
+			operation.BodySchema = parsed.Schema
+			continue
+		}
+		key := parsed.In + ":" + parsed.Name
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = parsed
+	}
+	for _, key := range order {
+		operation.Parameters = append(operation.Parameters, params[key])
+	}
+
+	if s.Version == 3 {
+		if body, ok := s.resolve(op["requestBody"]).(map[string]interface{}); ok {
+			operation.BodyType, operation.BodySchema = s.pickContent(body["content"])
+		}
+	} else if operation.BodySchema == nil {
+		for _, param := range operation.Parameters {
+			if param.In == "formData" {
+				operation.BodyType = "application/x-www-form-urlencoded"
+				if consumes, ok := op["consumes"].([]interface{}); ok && len(consumes) > 0 {
+					operation.BodyType = fmt.Sprint(consumes[0])
+				}
+				break
+			}
+		}
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	if response, ok := s.resolve(pickResponse(responses)).(map[string]interface{}); ok {
+		operation.RespSummary, _ = response["description"].(string)
+		if s.Version == 3 {
+			_, operation.RespSchema = s.pickContent(response["content"])
+		} else {
+			operation.RespSchema, _ = s.resolve(response["schema"]).(map[string]interface{})
+		}
+	}
+	return operation
+}
+
+func (s *Spec) parseParameter(param map[string]interface{}) SpecParameter {
+	parsed := SpecParameter{}
+	parsed.Name, _ = param["name"].(string)
+	parsed.In, _ = param["in"].(string)
+	parsed.Description, _ = param["description"].(string)
+	parsed.Required, _ = param["required"].(bool)
+	if schema, ok := param["schema"].(map[string]interface{}); ok {
+		parsed.Schema, _ = s.resolve(schema).(map[string]interface{})
+	} else {
+		// 2.0 的非 body 参数直接在参数上声明类型
+		parsed.Schema = map[string]interface{}{}
+		for _, key := range []string{"type", "format", "enum", "items", "default"} {
+			if v, ok := param[key]; ok {
+				parsed.Schema[key] = v
+			}
+		}
+	}
+	return parsed
+}
+
+// pickContent 优先选择 JSON 内容类型
+func (s *Spec) pickContent(content interface{}) (string, map[string]interface{}) {
+	contents, _ := content.(map[string]interface{})
+	if len(contents) == 0 {
+		return "", nil
+	}
+	types := make([]string, 0, len(contents))
+	for contentType := range contents {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	chosen := types[0]
+	for _, contentType := range types {
+		if strings.Contains(contentType, "json") {
+			chosen = contentType
+			break
+		}
+	}
+	media, _ := contents[chosen].(map[string]interface{})
+	schema, _ := s.resolve(media["schema"]).(map[string]interface{})
+	return chosen, schema
+}
+
+// pickResponse 选择成功响应: 200, 201, 其他 2xx, default
+func pickResponse(responses map[string]interface{}) interface{} {
+	for _, code := range []string{"200", "201"} {
+		if r, ok := responses[code]; ok {
+			return r
+		}
+	}
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return responses[code]
+		}
+	}
+	return responses["default"]
+}
+
+// resolve 解析文档内部的 $ref 引用
+func (s *Spec) resolve(value interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return value
+		}
+		value = s.lookup(ref)
+	}
+	return nil
+}
+
+// lookup 按 JSON Pointer 查找 #/a/b/c 形式的引用, 不支持外部文件引用
+func (s *Spec) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var current interface{} = s.raw
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// ExampleFromSchema 根据 Schema 生成示例值, 优先使用 example/default/enum
+func (s *Spec) ExampleFromSchema(schema map[string]interface{}) interface{} {
+	return s.example(schema, 0)
+}
+
+func (s *Spec) example(value interface{}, depth int) interface{} {
+	schema, _ := s.resolve(value).(map[string]interface{})
+	if schema == nil || depth > 8 {
+		return nil
+	}
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, sub := range allOf {
+			if m, ok := s.example(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if list, ok := schema[key].([]interface{}); ok && len(list) > 0 {
+			return s.example(list[0], depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := make(map[string]interface{})
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, prop := range properties {
+			obj[name] = s.example(prop, depth+1)
+		}
+		return obj
+	case "array":
+		return []interface{}{s.example(schema["items"], depth+1)}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		return "string"
+	}
+	return nil
+}
+
+// ParamFromSchema 将 Schema 转换为参数定义
+func (s *Spec) ParamFromSchema(field, description string, value interface{}) *core.ParamDefination {
+	schema, _ := s.resolve(value).(map[string]interface{})
+	if description == "" {
+		description, _ = schema["description"].(string)
+	}
+	param := core.NewParamDefination(field, core.DataTypeAny, description)
+	param.Default = schema["default"]
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		param.Datatype = core.DataTypeOptions
+		param.Options = enum
+		return param
+	}
+	switch schemaType(schema) {
+	case "string":
+		param.Datatype = core.DataTypeString
+	case "integer", "number":
+		param.Datatype = core.DataTypeNumber
+	case "boolean":
+		param.Datatype = core.DataTypeBoolean
+	case "array":
+		param.Datatype = core.DataTypeArray
+	case "object":
+		param.Datatype = core.DataTypeObject
+	}
+	return param
+}
+
+// PropertiesFormat 将对象 Schema 的一级属性转换为参数格式, 字段名加上 prefix 前缀
+func (s *Spec) PropertiesFormat(prefix string, value interface{}) core.ParamFormat {
+	format := core.ParamFormat{}
+	schema, _ := s.resolve(value).(map[string]interface{})
+	if schema == nil {
+		return format
+	}
+	properties := make(map[string]interface{})
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			subSchema, _ := s.resolve(sub).(map[string]interface{})
+			if props, ok := subSchema["properties"].(map[string]interface{}); ok {
+				for k, v := range props {
+					properties[k] = v
+				}
+			}
+		}
+	}
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		for k, v := range props {
+			properties[k] = v
+		}
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		format = append(format, s.ParamFromSchema(prefix+name, "", properties[name]))
+	}
+	return format
+}
+
+// schemaType 获取 Schema 类型, 未声明时根据 properties/items 推断
+func schemaType(schema map[string]interface{}) string {
+	if t, ok := schema["type"].(string); ok {
+		return t
+	}
+	// 3.1 允许 type 为数组, 取第一个非 null 类型
+	if types, ok := schema["type"].([]interface{}); ok {
+		for _, t := range types {
+			if t != "null" {
+				return fmt.Sprint(t)
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
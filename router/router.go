@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"api-flow/engine"
 	"api-flow/handlers"
 	"api-flow/services"
 )
@@ -15,10 +16,20 @@ func SetupRouter() *gin.Engine {
 	workflowService := services.NewWorkflowService()
 	nodeService := services.NewNodeService()
 	nodeExecutionService := services.NewNodeExecutionService(nodeService)
+	importService := services.NewImportService()
+	outboundService := services.NewOutboundService()
+	graphqlService := services.NewGraphQLService()
+	grpcService := services.NewGrpcService()
+	mockService := services.NewMockService()
 
 	// 创建处理器实例
 	workflowHandler := handlers.NewWorkflowHandler(workflowService)
 	nodeHandler := handlers.NewNodeHandler(nodeService, nodeExecutionService)
+	importHandler := handlers.NewImportHandler(importService, workflowService)
+	outboundHandler := handlers.NewOutboundHandler(outboundService)
+	graphqlHandler := handlers.NewGraphQLHandler(graphqlService)
+	grpcHandler := handlers.NewGrpcHandler(grpcService)
+	mockHandler := handlers.NewMockHandler(mockService)
 
 	// 定义API路由
 	api := r.Group("/api")
@@ -33,7 +44,40 @@ func SetupRouter() *gin.Engine {
 			workflows.DELETE("/:id", workflowHandler.Delete)
 			workflows.POST("/:id/publish", workflowHandler.PublishWorkflow) // 发布工作流
 			workflows.POST("/execute", workflowHandler.ExecuteWorkflow) // 执行工作流
+			workflows.POST("/:id/execute", workflowHandler.ExecuteByID) // 同步执行指定工作流, 请求体为流程入参
+			workflows.GET("/openapi", workflowHandler.OpenAPI)          // 已发布工作流的 OpenAPI 文档
 			workflows.GET("/execute/:workflowId/history", workflowHandler.GetWorkflowInstances) // 获取工作流执行历史
+			workflows.GET("/:id/analytics", workflowHandler.GetWorkflowAnalytics)               // 节点耗时分布、失败原因与趋势
+			workflows.GET("/instances/:instanceId/nodes", workflowHandler.GetNodeExecutions) // 流程实例的节点执行记录
+			workflows.GET("/instances/:instanceId/events", workflowHandler.GetInstanceEvents) // 流程实例的执行事件(SSE/WebSocket)
+			workflows.GET("/instances/:instanceId/recording", workflowHandler.GetRecording)  // 流程实例录制的出站请求与响应
+			workflows.POST("/instances/:instanceId/replay", workflowHandler.ReplayInstance) // 使用录制回放流程实例
+			workflows.POST("/instances/:instanceId/rerun", workflowHandler.RerunInstance)   // 使用原实例的输入参数重新执行
+			workflows.POST("/:id/debug", workflowHandler.StartDebug)                         // 调试执行, 在断点处暂停
+			workflows.GET("/:id/export", workflowHandler.Export) // 导出工作流定义文件
+			workflows.POST("/import", workflowHandler.Import)    // 导入工作流定义文件
+			workflows.GET("/:id/tests", workflowHandler.ListTestCases)      // 工作流的测试用例
+			workflows.POST("/:id/tests", workflowHandler.CreateTestCase)    // 创建测试用例
+			workflows.POST("/:id/tests/run", workflowHandler.RunTestCases)  // 执行全部测试用例, format=junit 时返回 JUnit XML
+		}
+
+		// 测试用例路由
+		testCases := api.Group("/test-cases")
+		{
+			testCases.PUT("/:id", workflowHandler.UpdateTestCase)
+			testCases.DELETE("/:id", workflowHandler.DeleteTestCase)
+			testCases.POST("/:id/run", workflowHandler.RunTestCase) // 执行单个测试用例, format=junit 时返回 JUnit XML
+		}
+
+		// 调试会话路由
+		debugSessions := api.Group("/debug-sessions")
+		{
+			debugSessions.GET("/:sessionId", workflowHandler.GetDebugSession)
+			debugSessions.POST("/:sessionId/continue", workflowHandler.DebugCommand(engine.DebugContinue)) // 执行到下一个断点
+			debugSessions.POST("/:sessionId/step", workflowHandler.DebugCommand(engine.DebugStep))         // 执行当前节点, 在下一个节点前暂停
+			debugSessions.POST("/:sessionId/skip", workflowHandler.DebugCommand(engine.DebugSkip))         // 跳过当前节点
+			debugSessions.PUT("/:sessionId/breakpoints", workflowHandler.SetDebugBreakpoints)
+			debugSessions.DELETE("/:sessionId", workflowHandler.AbortDebug)
 		}
 
 		// 节点路由
@@ -48,7 +92,42 @@ func SetupRouter() *gin.Engine {
 
 		// 节点类型路由
 		api.GET("/node-types", nodeHandler.GetNodeTypes)
+
+		// 从外部格式导入节点
+		imports := api.Group("/import")
+		{
+			imports.POST("/openapi/operations", importHandler.OpenAPIOperations) // 解析OpenAPI文档, 列出接口
+			imports.POST("/openapi/nodes", importHandler.OpenAPINodes)           // 将选中的接口转换为API节点
+			imports.POST("/curl", importHandler.Curl)                            // 将cURL命令转换为API节点
+			imports.POST("/har", importHandler.HAR)                              // 将HAR文件中的请求转换为API节点
+		}
+
+		// 出站请求治理
+		outbound := api.Group("/outbound")
+		{
+			outbound.GET("/breakers", outboundHandler.Breakers)                  // 各目标主机的熔断器状态
+			outbound.POST("/breakers/:host/reset", outboundHandler.ResetBreaker) // 手动关闭熔断器
+		}
+
+		// GraphQL 节点辅助
+		api.POST("/graphql/operations", graphqlHandler.Operations) // 内省接口, 列出可调用的操作
+
+		// gRPC 节点辅助
+		api.POST("/grpc/services", grpcHandler.Services) // 通过反射或上传的描述符列出服务与方法
+
+		// 模拟接口管理
+		mocks := api.Group("/mocks")
+		{
+			mocks.GET("", mockHandler.List)
+			mocks.POST("", mockHandler.Create)
+			mocks.PUT("/:id", mockHandler.Update)
+			mocks.DELETE("/:id", mockHandler.Delete)
+			mocks.POST("/reset", mockHandler.Reset) // 清零调用次数
+		}
 	}
 
+	// 模拟接口, 路径去掉 /mock 前缀后匹配
+	r.Any(handlers.MockPrefix+"/*path", mockHandler.Serve)
+
 	return r
 }
@@ -0,0 +1,51 @@
+// Package secrets 密钥管理, 节点配置中只保存密钥名称, 实际值来自配置文件或环境变量
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// EnvPrefix 环境变量中密钥的前缀, 如密钥 github_token 对应 API_FLOW_SECRET_GITHUB_TOKEN
+const EnvPrefix = "API_FLOW_SECRET_"
+
+var (
+	mu     sync.RWMutex
+	values = make(map[string]string)
+)
+
+var envNameReplacer = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// Register 注册密钥(通常来自配置文件的 secrets 段), 同名密钥会被覆盖
+func Register(secrets map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for name, value := range secrets {
+		values[name] = value
+	}
+}
+
+// Lookup 查找密钥, 先查已注册的密钥, 再查环境变量
+func Lookup(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("密钥名称不能为空")
+	}
+	mu.RLock()
+	value, ok := values[name]
+	mu.RUnlock()
+	if ok {
+		return value, nil
+	}
+	if value, ok := os.LookupEnv(EnvName(name)); ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("密钥 %s 不存在", name)
+}
+
+// EnvName 密钥对应的环境变量名
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(envNameReplacer.ReplaceAllString(name, "_"))
+}
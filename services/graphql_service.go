@@ -0,0 +1,29 @@
+package services
+
+import (
+	"api-flow/dto"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// GraphQLService GraphQL 节点的辅助功能
+type GraphQLService struct {
+	executor *engine_nodes.GraphQLNodeExecutor
+}
+
+// NewGraphQLService 创建 GraphQL 服务实例
+func NewGraphQLService() *GraphQLService {
+	return &GraphQLService{executor: engine_nodes.NewGraphQLNodeExecutor()}
+}
+
+// Operations 内省 GraphQL 接口, 列出可调用的操作及生成的查询文档
+func (s *GraphQLService) Operations(request dto.GraphQLIntrospectionRequest) ([]*engine_nodes.GraphQLOperation, error) {
+	config := core.ItemConfig{"endpoint": request.Endpoint}
+	if request.Headers != nil {
+		config["headers"] = request.Headers
+	}
+	if request.Auth != nil {
+		config["auth"] = request.Auth
+	}
+	return s.executor.Introspect(config)
+}
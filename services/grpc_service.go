@@ -0,0 +1,41 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"api-flow/dto"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/grpcclient"
+)
+
+// 反射列出服务的超时时间
+const grpcReflectionTimeout = 30 * time.Second
+
+// GrpcService gRPC 节点的辅助功能
+type GrpcService struct {
+	executor *engine_nodes.GrpcNodeExecutor
+}
+
+// NewGrpcService 创建 gRPC 服务实例
+func NewGrpcService() *GrpcService {
+	return &GrpcService{executor: engine_nodes.NewGrpcNodeExecutor()}
+}
+
+// Services 列出可调用的服务与方法, 使用上传的描述符时同时返回其 base64 编码, 供填入节点的 descriptorSet
+func (s *GrpcService) Services(request dto.GrpcServicesRequest, upload []byte) ([]grpcclient.ServiceInfo, string, error) {
+	descriptorSet := request.DescriptorSet
+	if len(upload) > 0 {
+		descriptorSet = base64.StdEncoding.EncodeToString(upload)
+	}
+	if descriptorSet == "" && request.Target == "" {
+		return nil, "", errors.New("使用服务端反射时target不能为空")
+	}
+
+	services, err := s.executor.Services(grpcclient.Target{Address: request.Target, TLS: request.TLS}, []byte(descriptorSet), grpcReflectionTimeout)
+	if err != nil {
+		return nil, "", err
+	}
+	return services, descriptorSet, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/models"
+	"api-flow/importer"
+	"api-flow/openapi"
+)
+
+// ImportService 从外部格式(OpenAPI 等)生成预配置节点
+type ImportService struct{}
+
+// NewImportService 创建导入服务实例
+func NewImportService() *ImportService {
+	return &ImportService{}
+}
+
+// OpenAPIOperations 解析 OpenAPI 文档, 列出可导入的接口操作
+func (s *ImportService) OpenAPIOperations(data []byte) (*openapi.Spec, []*openapi.SpecOperation, error) {
+	spec, err := openapi.ParseSpec(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, spec.Operations(), nil
+}
+
+// OpenAPINodes 将选中的接口操作转换为预配置的 API 节点, baseURL 为空时使用文档中声明的地址
+func (s *ImportService) OpenAPINodes(data []byte, operationIDs []string, baseURL string) ([]engine_nodes.Node, error) {
+	spec, err := openapi.ParseSpec(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(operationIDs) == 0 {
+		return nil, errors.New("请选择要导入的接口")
+	}
+	operations, missing := spec.FindOperations(operationIDs)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("接口不存在: %s", strings.Join(missing, ", "))
+	}
+	if baseURL == "" {
+		baseURL = spec.BaseURL()
+	}
+
+	nodes := make([]engine_nodes.Node, 0, len(operations))
+	for _, operation := range operations {
+		name := operation.Summary
+		if name == "" {
+			name = operation.ID
+		}
+		nodes = append(nodes, engine_nodes.NewAPINode(name, operation.Description, openAPINodeConfig(spec, operation, baseURL)))
+	}
+	return nodes, nil
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPINodeConfig 根据接口操作生成 API 节点配置
+func openAPINodeConfig(spec *openapi.Spec, operation *openapi.SpecOperation, baseURL string) core.ItemConfig {
+	url := pathParamPattern.ReplaceAllStringFunc(operation.Path, func(match string) string {
+		return engine_nodes.TemplateRef(match[1 : len(match)-1])
+	})
+
+	headers := make(map[string]interface{})
+	params := make(map[string]interface{})
+	inputFormat := core.ParamFormat{}
+	cookies := make([]string, 0)
+	formFields := make(map[string]interface{})
+	for _, param := range operation.Parameters {
+		ref := engine_nodes.TemplateRef(param.Name)
+		switch param.In {
+		case "query":
+			params[param.Name] = ref
+		case "header":
+			headers[param.Name] = ref
+		case "cookie":
+			cookies = append(cookies, param.Name+"="+ref)
+		case "formData":
+			formFields[param.Name] = ref
+		}
+		inputFormat = append(inputFormat, spec.ParamFromSchema(param.Name, param.Description, param.Schema))
+	}
+	if len(cookies) > 0 {
+		headers["Cookie"] = strings.Join(cookies, "; ")
+	}
+
+	var body interface{} = ""
+	bodyType := engine_nodes.BodyTypeNone
+	switch {
+	case strings.HasPrefix(operation.BodyType, "multipart/form-data"):
+		bodyType = engine_nodes.BodyTypeMultipart
+	case strings.HasPrefix(operation.BodyType, "application/x-www-form-urlencoded"):
+		bodyType = engine_nodes.BodyTypeForm
+	case operation.BodyType != "":
+		bodyType = engine_nodes.BodyTypeRaw
+	}
+	if operation.BodySchema != nil {
+		if skeleton, err := json.MarshalIndent(spec.ExampleFromSchema(operation.BodySchema), "", "  "); err == nil {
+			body = string(skeleton)
+		}
+	} else if len(formFields) > 0 {
+		body = formFields
+	}
+	if bodyType == engine_nodes.BodyTypeRaw {
+		headers["Content-Type"] = operation.BodyType
+	}
+
+	outputFormat := spec.PropertiesFormat("response.", operation.RespSchema)
+	if len(outputFormat) == 0 && operation.RespSchema != nil {
+		outputFormat = core.ParamFormat{spec.ParamFromSchema("response", operation.RespSummary, operation.RespSchema)}
+	}
+	if len(outputFormat) > 0 {
+		// 保留状态码、响应头等通用输出字段
+		for _, param := range engine_nodes.ApiNodeType.Output {
+			if param.Field != "response" {
+				outputFormat = append(outputFormat, param)
+			}
+		}
+	}
+
+	return core.ItemConfig{
+		"url":           baseURL + url,
+		"method":        operation.Method,
+		"headers":       headers,
+		"bodyType":      bodyType,
+		"body":          body,
+		"params":        params,
+		"timeout":       30,
+		"retry":         0,
+		"retryInterval": 5,
+		"inputFormat":   inputFormat,
+		"outputFormat":  outputFormat,
+	}
+}
+
+// CurlNode 将 cURL 命令转换为预配置的 API 节点, 同时返回转换时忽略的内容(如上传的文件)
+func (s *ImportService) CurlNode(command string) (*engine_nodes.Node, []string, error) {
+	request, err := importer.ParseCurl(command)
+	if err != nil {
+		return nil, nil, err
+	}
+	node := request.Node()
+	return &node, request.Warnings, nil
+}
+
+// HARNodes 将 HAR 文件中的请求转换为 API 节点, chain 为 true 时按请求顺序用连线串联为线性流程
+func (s *ImportService) HARNodes(data []byte, filter string, chain bool) (*dto.HARImportResult, error) {
+	requests, skipped, err := importer.ParseHAR(data, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, errors.New("HAR文件中没有可导入的请求")
+	}
+
+	result := &dto.HARImportResult{
+		Nodes:   make([]engine_nodes.Node, 0, len(requests)),
+		Edges:   make([]engine.Edge, 0),
+		Skipped: skipped,
+	}
+	for i, request := range requests {
+		node := request.Node()
+		if chain {
+			// 从左到右排列, 便于在编辑器中查看
+			node.Ui = models.Record{"x": 100 + i*200, "y": 100}
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+	if chain {
+		for i := 1; i < len(result.Nodes); i++ {
+			result.Edges = append(result.Edges, engine.NewEdge(result.Nodes[i-1].NodeKey, result.Nodes[i].NodeKey))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/database"
+	"api-flow/mock"
+)
+
+var (
+	// ErrInvalidMock 模拟接口配置无效
+	ErrInvalidMock = errors.New("模拟接口配置无效")
+	// ErrMockNotFound 模拟接口不存在
+	ErrMockNotFound = errors.New("模拟接口不存在")
+)
+
+// MockService 模拟接口服务, 模拟接口保存在数据库中, 修改后重新加载到进程内的模拟接口服务
+type MockService struct {
+	DB     *gorm.DB
+	server *mock.Server
+}
+
+// NewMockService 创建模拟接口服务实例, 并加载已保存的模拟接口
+func NewMockService() *MockService {
+	s := &MockService{
+		DB:     database.DB,
+		server: mock.Default(),
+	}
+	if err := s.reload(); err != nil {
+		log.Printf("加载模拟接口失败: %v", err)
+	}
+	return s
+}
+
+// Server 进程内的模拟接口服务
+func (s *MockService) Server() *mock.Server {
+	return s.server
+}
+
+// ListRoutes 获取全部模拟接口
+func (s *MockService) ListRoutes() ([]mock.Route, error) {
+	var routes []mock.Route
+	if err := s.DB.Where("deleted_at IS NULL").Order("id").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// CreateRoute 创建模拟接口
+func (s *MockService) CreateRoute(route *mock.Route) error {
+	if err := route.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMock, err)
+	}
+	route.ID = 0
+	if err := s.DB.Create(route).Error; err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// UpdateRoute 更新模拟接口
+func (s *MockService) UpdateRoute(id uint, route *mock.Route) error {
+	if err := route.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMock, err)
+	}
+	existing := &mock.Route{}
+	if err := s.DB.Where("id = ? AND deleted_at IS NULL", id).First(existing).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrMockNotFound
+		}
+		return err
+	}
+	route.BasicModel = existing.BasicModel
+	if err := s.DB.Save(route).Error; err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// DeleteRoute 删除模拟接口 (软删除)
+func (s *MockService) DeleteRoute(id uint) error {
+	var route mock.Route
+	if err := s.DB.Where("id = ? AND deleted_at IS NULL", id).First(&route).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return ErrMockNotFound
+		}
+		return err
+	}
+	if err := s.DB.Delete(&route).Error; err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// Reset 清零调用次数, 依次返回的模拟接口重新从第一个响应开始
+func (s *MockService) Reset() {
+	s.server.Reset()
+}
+
+// reload 从数据库重新加载全部模拟接口
+func (s *MockService) reload() error {
+	routes, err := s.ListRoutes()
+	if err != nil {
+		return err
+	}
+	return s.server.SetRoutes(routes)
+}
@@ -1,52 +1,43 @@
 package services
 
 import (
+	"context"
+
+	"api-flow/engine"
 	"api-flow/engine/core"
 	"api-flow/engine/engine_nodes"
-	"fmt"
 )
 
 // NodeExecutionService 节点执行服务
 type NodeExecutionService struct {
 	nodeService *NodeService
 	nodeEngine  *engine_nodes.NodeEngine
+	runner      *engine.Runner
 }
 
 // NewNodeExecutionService 创建节点执行服务实例
 func NewNodeExecutionService(nodeService *NodeService) *NodeExecutionService {
+	nodeEngine := engine_nodes.NewNodeEngine()
 	return &NodeExecutionService{
 		nodeService: nodeService,
-		nodeEngine:  engine_nodes.NewNodeEngine(),
+		nodeEngine:  nodeEngine,
+		runner:      engine.NewRunner(nodeEngine),
 	}
 }
 
-func (s *NodeExecutionService) getRealValue(value interface{}, results []core.ExecuteResult) (interface{}, error) {
-	if expression, ok := value.(string); ok {
-		parser := core.NewExpressionParser(results)
-		return parser.Parse(expression)
-	} else {
-		return value, nil
-	}
+// ExecuteNode 解析输入表达式后执行节点
+func (s *NodeExecutionService) ExecuteNode(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) (*core.ExecuteResult, error) {
+	return s.runner.ExecuteNode(ctx, node, inputs, results)
 }
 
-func (s *NodeExecutionService) ExecuteNode(node *engine_nodes.Node, inputs map[string]interface{}, results []core.ExecuteResult) (*core.ExecuteResult, error) {
-	config := node.Config
-	if config != nil {
-		// 覆盖默认输入
-		configInputs, ok := config["inputs"].(map[string]interface{})
-		if ok {
-			for key, expressionOrValue := range configInputs {
-				fmt.Println("expressionOrValue:", expressionOrValue)
-				realVal, err := s.getRealValue(expressionOrValue, results)
-				if err != nil {
-					fmt.Println("Error parsing expression:", err)
-				} else {
-					inputs[key] = realVal
-				}
-			}
-		}
-	}
-	return s.nodeEngine.ExecuteNode(node, inputs)
+// Runner 工作流调度器
+func (s *NodeExecutionService) Runner() *engine.Runner {
+	return s.runner
+}
+
+// ExecuteNodes 按连线顺序执行工作流的节点, ctx 携带模拟等执行选项
+func (s *NodeExecutionService) ExecuteNodes(ctx context.Context, nodes []engine_nodes.Node, edges []engine.Edge, inputs map[string]interface{}) ([]core.ExecuteResult, error) {
+	return s.runner.RunContext(ctx, nodes, edges, inputs)
 }
 
 // ExecuteNode 执行节点
@@ -57,10 +48,10 @@ func (s *NodeExecutionService) ExecuteNodeById(nodeID uint, inputs map[string]in
 	// }
 
 	// 执行节点
-	return s.ExecuteNodeWithoutWorkflow(nodeID, inputs)
+	return s.ExecuteNodeWithoutWorkflow(context.Background(), nodeID, inputs)
 }
 
-func (s *NodeExecutionService) ExecuteNodeWithoutWorkflow(nodeID uint, inputs map[string]interface{}) (*core.ExecuteResult, error) {
+func (s *NodeExecutionService) ExecuteNodeWithoutWorkflow(ctx context.Context, nodeID uint, inputs map[string]interface{}) (*core.ExecuteResult, error) {
 	// TODO 验证工作流是否已发布
 	// ...
 	
@@ -71,5 +62,5 @@ func (s *NodeExecutionService) ExecuteNodeWithoutWorkflow(nodeID uint, inputs ma
 	}
 
 	// 执行节点
-	return s.nodeEngine.ExecuteNode(node, inputs)
+	return s.nodeEngine.ExecuteNode(ctx, node, inputs)
 }
\ No newline at end of file
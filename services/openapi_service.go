@@ -0,0 +1,190 @@
+package services
+
+import (
+	"fmt"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/definition"
+	"api-flow/engine/engine_nodes"
+	"api-flow/openapi"
+)
+
+// PublishedWorkflow 生成调用文档使用的工作流及其节点与连线
+type PublishedWorkflow struct {
+	Workflow engine.Workflow
+	Nodes    []engine_nodes.Node
+	Edges    []engine.Edge
+}
+
+// GenerateOpenAPI 为所有已发布的工作流生成 OpenAPI 文档
+func (s *WorkflowService) GenerateOpenAPI(serverURL string) (*openapi.Document, error) {
+	var workflows []engine.Workflow
+	if err := s.DB.Where("status = ? AND deleted_at IS NULL", engine.WorkflowPublished).
+		Order("id").Find(&workflows).Error; err != nil {
+		return nil, fmt.Errorf("获取已发布工作流失败: %v", err)
+	}
+
+	published := make([]PublishedWorkflow, 0, len(workflows))
+	for _, workflow := range workflows {
+		item := PublishedWorkflow{Workflow: workflow}
+		if err := s.DB.Where("workflow_id = ?", workflow.ID).Find(&item.Nodes).Error; err != nil {
+			return nil, fmt.Errorf("获取工作流节点失败: %v", err)
+		}
+		if err := s.DB.Where("workflow_id = ?", workflow.ID).Find(&item.Edges).Error; err != nil {
+			return nil, fmt.Errorf("获取工作流连线失败: %v", err)
+		}
+		published = append(published, item)
+	}
+	return BuildOpenAPI(serverURL, published), nil
+}
+
+// BuildOpenAPI 生成工作流执行接口的 OpenAPI 文档, serverURL 为空时不设置 servers。
+// 请求体结构来自 execInput 节点的入参定义, 响应中的 outputs 结构来自末端节点的输出格式
+func BuildOpenAPI(serverURL string, workflows []PublishedWorkflow) *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "api-flow 工作流调用接口",
+			Description: "已发布工作流的同步执行接口, 请求体为流程入参, 响应为执行结果",
+			Version:     "1.0.0",
+		},
+		Tags:  []openapi.Tag{{Name: "workflows", Description: "已发布的工作流"}},
+		Paths: make(map[string]*openapi.PathItem),
+		Components: &openapi.Components{
+			Schemas: map[string]*openapi.Schema{
+				"ExecuteResult":           executeResultSchema(),
+				"WorkflowExecutionResult": workflowExecutionResultSchema(),
+				"Error":                   errorSchema(),
+			},
+			Responses: map[string]*openapi.Response{
+				"Error": {
+					Description: "请求错误或执行异常",
+					Content: map[string]*openapi.MediaType{
+						"application/json": {Schema: &openapi.Schema{Ref: "#/components/schemas/Error"}},
+					},
+				},
+			},
+		},
+	}
+	if serverURL != "" {
+		doc.Servers = []openapi.Server{{URL: serverURL}}
+	}
+
+	for i := range workflows {
+		workflow := &workflows[i]
+		path := fmt.Sprintf("/api/workflows/%d/execute", workflow.Workflow.ID)
+		doc.Paths[path] = &openapi.PathItem{
+			Post: workflowOperation(&workflow.Workflow, workflow.Nodes, workflow.Edges),
+		}
+	}
+
+	return doc
+}
+
+// workflowOperation 生成单个工作流的执行接口描述
+func workflowOperation(workflow *engine.Workflow, nodes []engine_nodes.Node, edges []engine.Edge) *openapi.Operation {
+	inputs := definition.WorkflowInputs(nodes)
+	outputs := definition.WorkflowOutputs(nodes, edges)
+
+	outputsSchema, outputsExample := openapi.OutputsSchema(outputs)
+	responseExample := map[string]interface{}{
+		"workflowId":   workflow.ID,
+		"workflowName": workflow.Name,
+		"status":       core.ExecuteStatusSuccess,
+		"nodeResults":  []interface{}{},
+		"outputs":      outputsExample,
+		"duration":     0,
+	}
+
+	return &openapi.Operation{
+		OperationID: fmt.Sprintf("executeWorkflow%d", workflow.ID),
+		Summary:     workflow.Name,
+		Description: workflow.Description,
+		Tags:        []string{"workflows"},
+		RequestBody: &openapi.RequestBody{
+			Description: "流程入参",
+			Required:    len(inputs) > 0,
+			Content: map[string]*openapi.MediaType{
+				"application/json": {
+					Schema:  openapi.SchemaFromParamFormat(inputs),
+					Example: openapi.ExampleFromParamFormat(inputs),
+				},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "执行结果",
+				Content: map[string]*openapi.MediaType{
+					"application/json": {
+						Schema: &openapi.Schema{
+							AllOf: []*openapi.Schema{
+								{Ref: "#/components/schemas/WorkflowExecutionResult"},
+								{
+									Type:       "object",
+									Properties: map[string]*openapi.Schema{"outputs": outputsSchema},
+								},
+							},
+						},
+						Example: responseExample,
+					},
+				},
+			},
+			"400": {Ref: "#/components/responses/Error"},
+			"500": {Ref: "#/components/responses/Error"},
+		},
+	}
+}
+
+func executeResultSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"nodeId":  {Type: "string"},
+			"nodeKey": {Type: "string"},
+			"status":  executeStatusSchema(),
+			"data":    {Type: "object", Description: "节点输出"},
+			"error":   {Type: "string"},
+		},
+	}
+}
+
+func workflowExecutionResultSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"workflowId":   {Type: "integer"},
+			"workflowName": {Type: "string"},
+			"status":       executeStatusSchema(),
+			"nodeResults": {
+				Type:  "array",
+				Items: &openapi.Schema{Ref: "#/components/schemas/ExecuteResult"},
+			},
+			"outputs":      {Type: "object", Description: "末端节点的输出, 以节点键为key"},
+			"errorMessage": {Type: "string"},
+			"duration":     {Type: "integer", Description: "执行时间(ms)"},
+		},
+	}
+}
+
+func executeStatusSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type:        "integer",
+		Description: "执行状态: 0 ready, 1 running, 2 success, 3 error",
+		Enum: []interface{}{
+			core.ExecuteStatusReady,
+			core.ExecuteStatusRunning,
+			core.ExecuteStatusSuccess,
+			core.ExecuteStatusError,
+		},
+	}
+}
+
+func errorSchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"error": {Type: "string"},
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+
+	"api-flow/engine/outbound"
+)
+
+// OutboundService 节点出站请求治理(限流、熔断)的状态查询
+type OutboundService struct{}
+
+// NewOutboundService 创建出站请求服务实例
+func NewOutboundService() *OutboundService {
+	return &OutboundService{}
+}
+
+// GetBreakers 获取各目标主机的熔断器状态
+func (s *OutboundService) GetBreakers() []outbound.BreakerStatus {
+	return outbound.Breakers()
+}
+
+// ResetBreaker 手动关闭目标主机的熔断器
+func (s *OutboundService) ResetBreaker(host string) error {
+	if !outbound.ResetBreaker(host) {
+		return errors.New("该主机没有熔断器")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/mock"
+)
+
+// ErrDebugSessionNotFound 调试会话不存在或已过期
+var ErrDebugSessionNotFound = errors.New("调试会话不存在或已过期")
+
+// debugSessions 进行中与最近结束的调试会话, 会话结束后保留 Timeout 时长以便查询结果
+var debugSessions = struct {
+	sync.Mutex
+	sessions map[string]*engine.DebugSession
+}{sessions: make(map[string]*engine.DebugSession)}
+
+// StartDebug 以调试模式执行工作流, 在断点处暂停; 调试执行不记录到执行历史
+func (s *WorkflowService) StartDebug(workflowID uint, request *dto.WorkflowDebugRequest) (*engine.DebugSession, error) {
+	if _, err := s.GetWorkflowByID(workflowID); err != nil {
+		return nil, fmt.Errorf("获取工作流失败: %v", err)
+	}
+	nodes, edges, err := s.loadGraph(workflowID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		keys[node.NodeKey] = true
+	}
+	for _, key := range request.Breakpoints {
+		if !keys[key] {
+			return nil, fmt.Errorf("%w: 断点节点 %s 不存在", ErrInvalidExecution, key)
+		}
+	}
+
+	ctx := context.Background()
+	if request.Mock {
+		ctx = mock.Default().Redirect(ctx)
+	}
+	inputs := request.Inputs
+	if inputs == nil {
+		inputs = make(map[string]interface{})
+	}
+
+	session := engine.NewDebugSession(request.Breakpoints, time.Duration(request.Timeout)*time.Second)
+	debugSessions.Lock()
+	debugSessions.sessions[session.ID] = session
+	debugSessions.Unlock()
+
+	session.Run(ctx, s.NodeExecutionService.Runner(), nodes, edges, inputs)
+	go func() {
+		<-session.Done()
+		time.AfterFunc(session.Timeout, func() { removeDebugSession(session.ID) })
+	}()
+	return session, nil
+}
+
+// DebugSession 获取调试会话
+func (s *WorkflowService) DebugSession(id string) (*engine.DebugSession, error) {
+	debugSessions.Lock()
+	defer debugSessions.Unlock()
+	session, ok := debugSessions.sessions[id]
+	if !ok {
+		return nil, ErrDebugSessionNotFound
+	}
+	return session, nil
+}
+
+// AbortDebug 终止调试会话并移除
+func (s *WorkflowService) AbortDebug(id string) (*engine.DebugSession, error) {
+	session, err := s.DebugSession(id)
+	if err != nil {
+		return nil, err
+	}
+	session.Abort()
+	<-session.Done()
+	removeDebugSession(id)
+	return session, nil
+}
+
+func removeDebugSession(id string) {
+	debugSessions.Lock()
+	defer debugSessions.Unlock()
+	delete(debugSessions.sessions, id)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/definition"
+	"api-flow/engine/engine_nodes"
+)
+
+// ErrInvalidDefinition 定义文件校验未通过
+var ErrInvalidDefinition = errors.New("工作流定义校验失败")
+
+// ExportWorkflow 导出工作流定义
+func (s *WorkflowService) ExportWorkflow(id uint) (*definition.WorkflowDefinition, error) {
+	workflow, err := s.GetWorkflowByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []engine_nodes.Node
+	if err := s.DB.Where("workflow_id = ?", id).Order("created_at").Find(&nodes).Error; err != nil {
+		return nil, fmt.Errorf("获取工作流节点失败: %v", err)
+	}
+
+	var edges []engine.Edge
+	if err := s.DB.Where("workflow_id = ?", id).Order("created_at").Find(&edges).Error; err != nil {
+		return nil, fmt.Errorf("获取工作流连线失败: %v", err)
+	}
+
+	return definition.FromWorkflow(workflow, nodes, edges), nil
+}
+
+// ImportWorkflow 导入工作流定义
+//
+// 先完成全部校验, 有问题时返回 ErrInvalidDefinition 与问题列表, 不写入任何数据;
+// 节点键与已有节点冲突时会重新生成, 映射关系在结果中返回。validateOnly 为 true 时只校验不保存。
+func (s *WorkflowService) ImportWorkflow(def *definition.WorkflowDefinition, validateOnly bool) (*dto.WorkflowImportResult, error) {
+	nodeTypes, err := NewNodeService().GetAllNodeTypes()
+	if err != nil {
+		return nil, fmt.Errorf("获取节点类型失败: %v", err)
+	}
+	nodeTypeCodes := make(map[string]bool)
+	for _, nodeType := range nodeTypes {
+		nodeTypeCodes[nodeType.Code] = true
+	}
+
+	result := &dto.WorkflowImportResult{
+		Name:     def.Name,
+		Problems: def.Validate(func(code string) bool { return nodeTypeCodes[code] }),
+	}
+	if len(result.Problems) > 0 {
+		return result, ErrInvalidDefinition
+	}
+
+	existingKeys, err := s.existingNodeKeys(def)
+	if err != nil {
+		return nil, err
+	}
+	result.KeyMapping = def.RemapKeys(func(key string) bool { return existingKeys[key] })
+
+	if validateOnly {
+		return result, nil
+	}
+
+	nodes, edges := def.ToModels()
+
+	tx := s.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	workflow := engine.Workflow{
+		Name:        def.Name,
+		Description: def.Description,
+		Status:      engine.WorkflowDraft,
+	}
+	if err := tx.Create(&workflow).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range nodes {
+		nodes[i].WorkflowID = workflow.ID
+		if err := tx.Create(&nodes[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	for i := range edges {
+		edges[i].ID = engine.GenerateEdgeID()
+		edges[i].WorkflowID = workflow.ID
+		if err := tx.Create(&edges[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	result.ID = workflow.ID
+	return result, nil
+}
+
+// existingNodeKeys 查询定义中已被占用的节点键(包含已删除的节点, 因为节点ID与节点键一致)
+func (s *WorkflowService) existingNodeKeys(def *definition.WorkflowDefinition) (map[string]bool, error) {
+	keys := make([]string, 0, len(def.Nodes))
+	for _, node := range def.Nodes {
+		keys = append(keys, node.Key)
+	}
+
+	var nodes []engine_nodes.Node
+	if err := s.DB.Unscoped().Where("node_key IN (?) OR id IN (?)", keys, keys).Find(&nodes).Error; err != nil {
+		return nil, fmt.Errorf("检查节点键冲突失败: %v", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, node := range nodes {
+		existing[node.NodeKey] = true
+		existing[node.ID] = true
+	}
+	return existing, nil
+}
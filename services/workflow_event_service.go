@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine"
+)
+
+// ErrEventsNotFound 流程实例没有保存执行事件(早于该功能的实例)
+var ErrEventsNotFound = errors.New("流程实例没有执行事件")
+
+// eventStreams 执行中的流程实例的事件流, 实例执行结束并保存事件后移除
+var eventStreams = struct {
+	sync.Mutex
+	streams map[uint]*engine.EventStream
+}{streams: make(map[uint]*engine.EventStream)}
+
+func registerEventStream(instanceID uint, stream *engine.EventStream) {
+	eventStreams.Lock()
+	defer eventStreams.Unlock()
+	eventStreams.streams[instanceID] = stream
+}
+
+func unregisterEventStream(instanceID uint) {
+	eventStreams.Lock()
+	defer eventStreams.Unlock()
+	delete(eventStreams.streams, instanceID)
+}
+
+// InstanceEventStream 流程实例的事件流: 执行中的实例返回实时事件流, 已结束的实例使用保存的事件回放
+func (s *WorkflowService) InstanceEventStream(instanceID uint) (*engine.EventStream, error) {
+	eventStreams.Lock()
+	stream, ok := eventStreams.streams[instanceID]
+	eventStreams.Unlock()
+	if ok {
+		return stream, nil
+	}
+
+	if _, err := s.getInstance(instanceID); err != nil {
+		return nil, err
+	}
+	var events engine.InstanceEvents
+	if err := s.DB.Where("instance_id = ?", instanceID).First(&events).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrEventsNotFound
+		}
+		return nil, err
+	}
+	return engine.ClosedEventStream(events.Events), nil
+}
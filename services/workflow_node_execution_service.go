@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+)
+
+// NodeExecutionQuery 节点执行记录的筛选条件, 为空时不筛选
+type NodeExecutionQuery struct {
+	NodeKey string
+	Status  *core.ExecuteStatus
+}
+
+type nodeStatistics struct {
+	Total       uint    `gorm:"column:total"`
+	ErrorCount  uint    `gorm:"column:errorCount"`
+	AvgDuration float64 `gorm:"column:avgDuration"`
+}
+
+// completeInstance 在同一个事务中更新执行结束的流程实例, 保存节点执行记录与执行事件
+func (s *WorkflowService) completeInstance(instance *engine.WorkflowInstance, executions []engine.NodeExecution, events []engine.ExecutionEvent) error {
+	tx := s.DB.Begin()
+	if err := tx.Save(instance).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("保存流程实例失败: %v", err)
+	}
+	for i := range executions {
+		execution := &executions[i]
+		execution.InstanceID = instance.ID
+		execution.WorkflowID = instance.WorkflowID
+		if err := tx.Create(execution).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("保存节点执行记录失败: %v", err)
+		}
+	}
+	if err := tx.Create(&engine.InstanceEvents{InstanceID: instance.ID, Events: events}).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("保存执行事件失败: %v", err)
+	}
+	return tx.Commit().Error
+}
+
+// finishInstanceAttempts 更新流程实例最终状态的最大尝试次数
+const finishInstanceAttempts = 3
+
+// finishInstance 只更新流程实例的最终状态, 用于 completeInstance 失败后; 失败时间隔重试
+func (s *WorkflowService) finishInstance(instance *engine.WorkflowInstance) {
+	for attempt := 1; ; attempt++ {
+		err := s.DB.Model(instance).Updates(map[string]interface{}{
+			"status":        instance.Status,
+			"end_time":      instance.EndTime,
+			"error_message": instance.ErrorMessage,
+			"duration":      instance.Duration,
+		}).Error
+		if err == nil {
+			return
+		}
+		if attempt == finishInstanceAttempts {
+			log.Printf("更新流程实例 %d 的状态失败: %v", instance.ID, err)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+	}
+}
+
+// GetNodeExecutions 分页获取流程实例的节点执行记录, 按执行顺序排列
+func (s *WorkflowService) GetNodeExecutions(instanceID uint, query NodeExecutionQuery, page, size int) ([]engine.NodeExecution, int, error) {
+	if _, err := s.getInstance(instanceID); err != nil {
+		return nil, 0, err
+	}
+	db := s.DB.Model(&engine.NodeExecution{}).Where("instance_id = ?", instanceID)
+	if query.NodeKey != "" {
+		db = db.Where("node_key = ?", query.NodeKey)
+	}
+	if query.Status != nil {
+		db = db.Where("status = ?", *query.Status)
+	}
+
+	var count int
+	if err := db.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+	var executions []engine.NodeExecution
+	if err := db.Order("id").Offset((page - 1) * size).Limit(size).Find(&executions).Error; err != nil {
+		return nil, 0, err
+	}
+	return executions, count, nil
+}
+
+// instanceNodeResults 流程实例的节点执行结果, 没有节点执行记录的旧实例使用实例中保存的 results
+func (s *WorkflowService) instanceNodeResults(instance *engine.WorkflowInstance) ([]core.ExecuteResult, error) {
+	results, err := s.instancesNodeResults([]engine.WorkflowInstance{*instance})
+	if err != nil {
+		return nil, err
+	}
+	return results[instance.ID], nil
+}
+
+// instancesNodeResults 批量查询流程实例的节点执行结果, 以实例ID为key
+func (s *WorkflowService) instancesNodeResults(instances []engine.WorkflowInstance) (map[uint][]core.ExecuteResult, error) {
+	results := make(map[uint][]core.ExecuteResult, len(instances))
+	if len(instances) == 0 {
+		return results, nil
+	}
+	ids := make([]uint, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.ID)
+	}
+
+	var executions []engine.NodeExecution
+	if err := s.DB.Select("id, instance_id, node_id, node_key, status, output, error").
+		Where("instance_id IN (?)", ids).
+		Order("id").
+		Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("查询节点执行记录失败: %v", err)
+	}
+	for i := range executions {
+		execution := &executions[i]
+		results[execution.InstanceID] = append(results[execution.InstanceID], execution.Result())
+	}
+
+	for _, instance := range instances {
+		if _, ok := results[instance.ID]; ok || instance.Results == "" {
+			continue
+		}
+		var legacy []core.ExecuteResult
+		if err := json.Unmarshal([]byte(instance.Results), &legacy); err != nil {
+			return nil, fmt.Errorf("解析实例 %d 的执行结果失败: %v", instance.ID, err)
+		}
+		results[instance.ID] = legacy
+	}
+	return results, nil
+}
+
+// addNodeStatistics 统计工作流的节点执行次数、失败次数与平均用时
+func (s *WorkflowService) addNodeStatistics(workflowID uint, statistics map[string]uint) error {
+	var stats nodeStatistics
+	err := s.DB.Model(&engine.NodeExecution{}).
+		Where("workflow_id = ? AND attempt > 0", workflowID).
+		Select(`
+		COUNT(*) AS total,
+		SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS errorCount,
+		AVG(duration) AS avgDuration
+	`, core.ExecuteStatusError).
+		Scan(&stats).Error
+	if err != nil {
+		return err
+	}
+	statistics["nodeTotal"] = stats.Total
+	statistics["nodeErrorCount"] = stats.ErrorCount
+	statistics["avgNodeDuration"] = uint(stats.AvgDuration)
+	return nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// ErrInvalidExecution 执行参数无效
+var ErrInvalidExecution = errors.New("执行参数无效")
+
+// reuseInstanceResults 部分执行时从原实例取得其余节点的结果, 原实例的输入参数按顶层字段被请求的输入参数覆盖
+func (s *WorkflowService) reuseInstanceResults(request *dto.WorkflowExecutionRequest, nodes []engine_nodes.Node, edges []engine.Edge) (map[string]*core.ExecuteResult, error) {
+	if request.FromInstance == 0 {
+		return nil, fmt.Errorf("%w: 从节点 %s 开始执行时需要指定 fromInstance", ErrInvalidExecution, request.StartNode)
+	}
+	instance, err := s.getInstance(request.FromInstance)
+	if err != nil {
+		return nil, err
+	}
+	if instance.WorkflowID != request.WorkflowID {
+		return nil, fmt.Errorf("%w: 实例 %d 不属于工作流 %d", ErrInvalidExecution, instance.ID, request.WorkflowID)
+	}
+
+	previous, err := s.instanceNodeResults(instance)
+	if err != nil {
+		return nil, err
+	}
+	stubs, err := engine.ReuseResults(nodes, edges, request.StartNode, previous)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExecution, err)
+	}
+
+	inputs, err := instanceInputs(instance)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range request.Inputs {
+		inputs[key] = value
+	}
+	request.Inputs = inputs
+	return stubs, nil
+}
+
+// reusedNodeKeys 使用原实例结果的节点, 按节点键排序
+func reusedNodeKeys(stubs map[string]*core.ExecuteResult) []string {
+	keys := make([]string, 0, len(stubs))
+	for key, result := range stubs {
+		if result.Status != core.ExecuteStatusReady {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/outbound"
+)
+
+var (
+	// ErrInstanceNotFound 流程实例不存在
+	ErrInstanceNotFound = errors.New("流程实例不存在")
+	// ErrRecordingNotFound 流程实例执行时没有录制出站请求
+	ErrRecordingNotFound = errors.New("流程实例没有录制")
+)
+
+// GetRecording 获取流程实例执行时录制的出站请求与响应
+func (s *WorkflowService) GetRecording(instanceID uint) (*engine.InstanceRecording, error) {
+	if _, err := s.getInstance(instanceID); err != nil {
+		return nil, err
+	}
+	var recording engine.InstanceRecording
+	if err := s.DB.Where("instance_id = ?", instanceID).First(&recording).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrRecordingNotFound
+		}
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// ReplayInstance 使用原实例的输入参数与执行时的工作流定义重新执行, 节点的出站请求由录制回放, 不访问网络
+func (s *WorkflowService) ReplayInstance(instanceID uint) (*dto.WorkflowExecutionResult, error) {
+	instance, err := s.getInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	recording, err := s.GetRecording(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := instanceInputs(instance)
+	if err != nil {
+		return nil, err
+	}
+	// 早于定义快照的实例使用工作流当前的节点与连线
+	def, err := instanceDefinition(instance)
+	if err != nil && !errors.Is(err, ErrNoDefinitionSnapshot) {
+		return nil, err
+	}
+
+	request := &dto.WorkflowExecutionRequest{
+		WorkflowID: instance.WorkflowID,
+		Inputs:     inputs,
+	}
+	return s.executeWorkflow(request, executionOptions{
+		definition: def,
+		player:     outbound.NewPlayer(recording.Exchanges),
+		replayOf:   instance.ID,
+	})
+}
+
+func (s *WorkflowService) getInstance(id uint) (*engine.WorkflowInstance, error) {
+	var instance engine.WorkflowInstance
+	if err := s.DB.Where("id = ?", id).First(&instance).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrInstanceNotFound
+		}
+		return nil, err
+	}
+	return &instance, nil
+}
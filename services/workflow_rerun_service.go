@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/definition"
+)
+
+// 重新执行时使用的工作流版本
+const (
+	RerunVersionCurrent  = "current"
+	RerunVersionOriginal = "original"
+)
+
+var (
+	// ErrInvalidRerun 重新执行的参数无效
+	ErrInvalidRerun = errors.New("重新执行参数无效")
+	// ErrNoDefinitionSnapshot 流程实例没有保存执行时的工作流定义
+	ErrNoDefinitionSnapshot = errors.New("流程实例没有保存执行时的工作流定义")
+)
+
+// RerunInstance 使用原实例的输入参数(可按顶层字段覆盖)重新执行工作流, 新实例关联到原实例
+func (s *WorkflowService) RerunInstance(instanceID uint, request *dto.WorkflowRerunRequest) (*dto.WorkflowExecutionResult, error) {
+	instance, err := s.getInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	execution, def, err := PrepareRerun(instance, request)
+	if err != nil {
+		return nil, err
+	}
+	return s.executeWorkflow(execution, executionOptions{rerunOf: instance.ID, definition: def})
+}
+
+// PrepareRerun 根据原实例生成重新执行的请求: 输入参数按顶层字段合并覆盖;
+// version 为 original 时同时返回实例执行时的工作流定义, 为 current 时定义为 nil
+func PrepareRerun(instance *engine.WorkflowInstance, request *dto.WorkflowRerunRequest) (*dto.WorkflowExecutionRequest, *definition.WorkflowDefinition, error) {
+	inputs, err := instanceInputs(instance)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, value := range request.Inputs {
+		inputs[key] = value
+	}
+
+	var def *definition.WorkflowDefinition
+	switch request.Version {
+	case "", RerunVersionCurrent:
+	case RerunVersionOriginal:
+		if def, err = instanceDefinition(instance); err != nil {
+			if errors.Is(err, ErrNoDefinitionSnapshot) {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRerun, err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: version 应为 %s 或 %s", ErrInvalidRerun, RerunVersionCurrent, RerunVersionOriginal)
+	}
+
+	execution := &dto.WorkflowExecutionRequest{
+		WorkflowID: instance.WorkflowID,
+		Inputs:     inputs,
+		Mock:       request.Mock,
+		Record:     request.Record,
+	}
+	if request.StartNode != "" {
+		execution.StartNode = request.StartNode
+		execution.FromInstance = instance.ID
+	}
+	return execution, def, nil
+}
+
+// instanceInputs 解析实例保存的输入参数
+func instanceInputs(instance *engine.WorkflowInstance) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{})
+	if instance.Inputs != "" && instance.Inputs != "null" {
+		if err := json.Unmarshal([]byte(instance.Inputs), &inputs); err != nil {
+			return nil, fmt.Errorf("解析实例输入参数失败: %v", err)
+		}
+	}
+	return inputs, nil
+}
+
+// instanceDefinition 解析实例执行时的工作流定义
+func instanceDefinition(instance *engine.WorkflowInstance) (*definition.WorkflowDefinition, error) {
+	if instance.Definition == "" {
+		return nil, ErrNoDefinitionSnapshot
+	}
+	var def definition.WorkflowDefinition
+	if err := json.Unmarshal([]byte(instance.Definition), &def); err != nil {
+		return nil, fmt.Errorf("解析实例的工作流定义失败: %v", err)
+	}
+	return &def, nil
+}
@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -12,7 +14,10 @@ import (
 	"api-flow/dto"
 	"api-flow/engine"
 	"api-flow/engine/core"
+	"api-flow/engine/definition"
 	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+	"api-flow/mock"
 )
 
 type Statistics struct {
@@ -241,49 +246,13 @@ func (s *WorkflowService) flowValidate(workflowDto *dto.WorkflowDTO) error {
 	}
 
 	// 检查循环依赖
-	if err := s.checkCircularDependency(workflowDto.Edges); err != nil {
+	if err := engine.CheckCircularDependency(workflowDto.Edges); err != nil {
 		return errors.New("工作流存在循环依赖")
 	}
 
 	return nil
 }
 
-func (s *WorkflowService) checkCircularDependency(edges []engine.Edge) error {
-	// 构建邻接表
-	graph := make(map[string][]string)
-	for _, edge := range edges {
-		graph[edge.SourceNodeKey] = append(graph[edge.SourceNodeKey], edge.TargetNodeKey)
-	}
-	// 深度优先搜索
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
-	var dfs func(node string) bool
-	dfs = func(node string) bool {
-		if !visited[node] {
-			visited[node] = true
-			recStack[node] = true
-
-			for _, neighbor := range graph[node] {
-				if !visited[neighbor] && dfs(neighbor) {
-					return true
-				} else if recStack[neighbor] {
-					return true
-				}
-			}
-		}
-		recStack[node] = false
-		return false
-	}
-	for node := range graph {
-		if !visited[node] {
-			if dfs(node) {
-				return errors.New("工作流存在循环依赖")
-			}
-		}
-	}
-	return nil
-}
-
 // GetWorkflowWithNodes 获取工作流及其关联节点
 func (s *WorkflowService) GetWorkflowWithNodes(workflowID uint) (*dto.WorkflowDTO, error) {
 	// 获取工作流
@@ -318,61 +287,133 @@ func (s *WorkflowService) GetWorkflowWithNodes(workflowID uint) (*dto.WorkflowDT
 	return response, nil
 }
 
-// ExecuteWorkflow 执行工作流
+// ExecuteWorkflow 执行工作流; async 为 true 时在后台执行, 立即返回运行中的实例
 func (s *WorkflowService) ExecuteWorkflow(request *dto.WorkflowExecutionRequest) (*dto.WorkflowExecutionResult, error) {
-	startTime := time.Now()
+	if request.Async {
+		return s.startWorkflow(request)
+	}
+	return s.executeWorkflow(request, executionOptions{})
+}
 
+// executionOptions 重新执行、回放流程实例时的执行选项
+type executionOptions struct {
+	// definition 不为空时按该定义执行, 否则使用工作流当前的节点与连线
+	definition *definition.WorkflowDefinition
+	// player 不为空时节点的出站请求由录制回放
+	player *outbound.Player
+	// replayOf 回放的原实例ID
+	replayOf uint
+	// rerunOf 重新执行的原实例ID
+	rerunOf uint
+}
+
+// workflowExecution 已创建实例、尚未执行完成的工作流
+type workflowExecution struct {
+	ctx          context.Context
+	request      *dto.WorkflowExecutionRequest
+	options      executionOptions
+	workflow     *engine.Workflow
+	nodes        []engine_nodes.Node
+	edges        []engine.Edge
+	instance     *engine.WorkflowInstance
+	reused       []string
+	recorder     *outbound.Recorder
+	executionLog *engine.ExecutionLog
+	stream       *engine.EventStream
+}
+
+// executeWorkflow 执行工作流并保存流程实例, 实例中保存执行时的工作流定义
+func (s *WorkflowService) executeWorkflow(request *dto.WorkflowExecutionRequest, options executionOptions) (*dto.WorkflowExecutionResult, error) {
+	execution, err := s.prepareExecution(request, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.runExecution(execution)
+}
+
+// startWorkflow 创建流程实例后在后台执行, 执行过程可通过实例的事件流查看
+func (s *WorkflowService) startWorkflow(request *dto.WorkflowExecutionRequest) (*dto.WorkflowExecutionResult, error) {
+	execution, err := s.prepareExecution(request, executionOptions{})
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		// 后台执行时 panic 不能让进程退出, 实例标记为失败并关闭事件流, 避免一直处于运行中
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("流程实例 %d 执行异常: %v", execution.instance.ID, r)
+				s.abortExecution(execution, fmt.Sprintf("执行异常: %v", r))
+			}
+		}()
+		if _, err := s.runExecution(execution); err != nil {
+			log.Printf("流程实例 %d 执行失败: %v", execution.instance.ID, err)
+		}
+	}()
+	return &dto.WorkflowExecutionResult{
+		WorkflowID:   execution.workflow.ID,
+		WorkflowName: execution.workflow.Name,
+		Status:       core.ExecuteStatusRunning,
+		InstanceID:   execution.instance.ID,
+		Mock:         request.Mock,
+		Recorded:     request.Record,
+		DryRun:       request.DryRun,
+		StartNode:    request.StartNode,
+		FromInstance: request.FromInstance,
+		Reused:       execution.reused,
+	}, nil
+}
+
+// prepareExecution 加载工作流、准备执行选项, 并创建运行中的流程实例
+func (s *WorkflowService) prepareExecution(request *dto.WorkflowExecutionRequest, options executionOptions) (*workflowExecution, error) {
 	// 获取工作流信息
 	workflow, err := s.GetWorkflowByID(request.WorkflowID)
 	if err != nil {
 		return nil, fmt.Errorf("获取工作流失败: %v", err)
 	}
 
-	// 获取工作流的所有节点
+	// 获取工作流的所有节点与连线
 	var nodes []engine_nodes.Node
-	if err := s.DB.Where("workflow_id = ?", request.WorkflowID).Find(&nodes).Error; err != nil {
-		return nil, fmt.Errorf("获取工作流节点失败: %v", err)
-	}
-
-	// 获取工作流的所有连线
 	var edges []engine.Edge
-	if err := s.DB.Where("workflow_id = ?", request.WorkflowID).Find(&edges).Error; err != nil {
-		return nil, fmt.Errorf("获取工作流连线失败: %v", err)
+	if options.definition != nil {
+		nodes, edges = options.definition.ToModels()
+	} else if nodes, edges, err = s.loadGraph(request.WorkflowID); err != nil {
+		return nil, err
 	}
-
-	// 建立节点映射，方便快速查找
-	nodeMap := make(map[string]*engine_nodes.Node)
-	for i := range nodes {
-		nodeMap[nodes[i].NodeKey] = &nodes[i]
+	snapshot := definition.FromWorkflow(workflow, nodes, edges)
+	definitionJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("序列化工作流定义失败: %v", err)
 	}
 
-	var status core.ExecuteStatus = core.ExecuteStatusReady
-	var errorMessage string
-
-	// 执行节点
-	nodeResults, err := s.executeNodes(nodes, edges, request.Inputs)
-	if err != nil {
-		status = core.ExecuteStatusError
-		errorMessage = fmt.Sprintf("执行节点失败: %v", err)
-	} else {
-		for _, result := range nodeResults {
-			status = result.Status
-			if status != core.ExecuteStatusSuccess {
-				errorMessage += fmt.Sprintf("节点 %s 执行失败: %s\n", result.NodeKey, result.Error)
-			}
+	// 开启 mock 时节点的 HTTP 请求由模拟接口处理
+	ctx := context.Background()
+	if request.Mock {
+		ctx = mock.Default().Redirect(ctx)
+	}
+	if request.DryRun {
+		ctx = outbound.WithDryRun(ctx)
+	}
+	if options.player != nil {
+		ctx = outbound.WithPlayer(ctx, options.player)
+	}
+	// 部分执行: 指定节点及其后续节点重新执行, 其余节点使用原实例的结果
+	var reused []string
+	if request.StartNode != "" {
+		stubs, err := s.reuseInstanceResults(request, nodes, edges)
+		if err != nil {
+			return nil, err
 		}
+		ctx = engine.WithStubResults(ctx, stubs)
+		reused = reusedNodeKeys(stubs)
 	}
-
-	Duration := time.Since(startTime).Milliseconds()
-
-	// 构建工作流执行结果
-	executionResult := &dto.WorkflowExecutionResult{
-		WorkflowID:   workflow.ID,
-		WorkflowName: workflow.Name,
-		Status:       status,
-		NodeResults:  nodeResults,
-		ErrorMessage: errorMessage,
-		Duration:     Duration,
+	executionLog := engine.NewExecutionLog()
+	ctx = engine.WithExecutionLog(ctx, executionLog)
+	stream := engine.NewEventStream()
+	ctx = engine.WithEventStream(ctx, stream)
+	var recorder *outbound.Recorder
+	if request.Record {
+		recorder = outbound.NewRecorder()
+		ctx = outbound.WithRecorder(ctx, recorder)
 	}
 
 	// 转换Inputs为JSON字符串
@@ -381,120 +422,127 @@ func (s *WorkflowService) ExecuteWorkflow(request *dto.WorkflowExecutionRequest)
 		return nil, fmt.Errorf("序列化输入参数失败: %v", err)
 	}
 
-	// 转换Results为JSON字符串
-	resultsJSON, err := json.Marshal(nodeResults)
-	if err != nil {
-		return nil, fmt.Errorf("序列化执行结果失败: %v", err)
-	}
-
-	// 创建并保存流程实例记录
+	// 创建运行中的流程实例, 执行结束后更新状态, 节点的执行结果保存在 node_executions 表
 	instance := &engine.WorkflowInstance{
 		WorkflowID:   workflow.ID,
 		WorkflowName: workflow.Name,
-		Status:       status,
-		StartTime:    startTime,
-		EndTime:      time.Now(),
+		Status:       core.ExecuteStatusRunning,
+		StartTime:    time.Now(),
 		Inputs:       string(inputsJSON),
-		Results:      string(resultsJSON),
-		ErrorMessage: errorMessage,
-		Duration:     Duration,
+		Mock:         request.Mock,
+		Recorded:     request.Record,
+		DryRun:       request.DryRun,
+		ReplayOf:     options.replayOf,
+		RerunOf:      options.rerunOf,
+		StartNode:    request.StartNode,
+		FromInstance: request.FromInstance,
+		Definition:   string(definitionJSON),
+		Version:      snapshot.Digest(),
 	}
-
 	if err := s.DB.Create(instance).Error; err != nil {
 		return nil, fmt.Errorf("保存流程实例失败: %v", err)
 	}
-
-	return executionResult, nil
+	registerEventStream(instance.ID, stream)
+
+	return &workflowExecution{
+		ctx:          ctx,
+		request:      request,
+		options:      options,
+		workflow:     workflow,
+		nodes:        nodes,
+		edges:        edges,
+		instance:     instance,
+		reused:       reused,
+		recorder:     recorder,
+		executionLog: executionLog,
+		stream:       stream,
+	}, nil
 }
 
-func (s *WorkflowService) executeNodes(nodes []engine_nodes.Node, edges []engine.Edge, inputs map[string]interface{}) ([]core.ExecuteResult, error) {
-	// 建立节点映射，方便快速查找
-	nodeMap := make(map[string]*engine_nodes.Node)
-	for i := range nodes {
-		nodeMap[nodes[i].NodeKey] = &nodes[i]
-	}
-	// 获取起始节点列表
-	startNodes, err := s.getStartNodeKeyList(nodes, edges)
+// runExecution 执行节点, 更新流程实例并保存节点执行记录、执行事件与录制
+func (s *WorkflowService) runExecution(execution *workflowExecution) (*dto.WorkflowExecutionResult, error) {
+	defer unregisterEventStream(execution.instance.ID)
+	request, instance := execution.request, execution.instance
+
+	var status core.ExecuteStatus = core.ExecuteStatusReady
+	var errorMessage string
+
+	// 执行节点
+	nodeResults, err := s.NodeExecutionService.ExecuteNodes(execution.ctx, execution.nodes, execution.edges, request.Inputs)
 	if err != nil {
+		status = core.ExecuteStatusError
+		errorMessage = fmt.Sprintf("执行节点失败: %v", err)
+	} else {
+		status, errorMessage = engine.SummarizeResults(nodeResults)
+	}
+
+	instance.Status = status
+	instance.EndTime = time.Now()
+	instance.ErrorMessage = errorMessage
+	instance.Duration = instance.EndTime.Sub(instance.StartTime).Milliseconds()
+	execution.stream.Emit(engine.ExecutionEvent{
+		Type:     engine.EventInstanceFinished,
+		Status:   &status,
+		Duration: instance.Duration,
+		Message:  errorMessage,
+	})
+	execution.stream.Close()
+
+	// 构建工作流执行结果
+	executionResult := &dto.WorkflowExecutionResult{
+		WorkflowID:   execution.workflow.ID,
+		WorkflowName: execution.workflow.Name,
+		Status:       status,
+		NodeResults:  nodeResults,
+		Outputs:      engine.CollectOutputs(execution.edges, nodeResults),
+		ErrorMessage: errorMessage,
+		Duration:     instance.Duration,
+		Mock:         request.Mock,
+		InstanceID:   instance.ID,
+		Recorded:     request.Record,
+		DryRun:       request.DryRun,
+		ReplayOf:     execution.options.replayOf,
+		RerunOf:      execution.options.rerunOf,
+		StartNode:    request.StartNode,
+		FromInstance: request.FromInstance,
+		Reused:       execution.reused,
+	}
+
+	if err := s.completeInstance(instance, execution.executionLog.Executions(), execution.stream.Events()); err != nil {
+		// 节点执行记录保存失败时仍要更新实例的最终状态, 否则实例一直处于运行中
+		s.finishInstance(instance)
 		return nil, err
 	}
-	toBeExecuted := make(chan string, len(nodes))
-	for _, startNodeKey := range startNodes {
-		toBeExecuted <- startNodeKey
-	}
-	var getNextNodeKeys = func(nodeKey string) []string {
-		nextNodes := make([]string, 0)
-		for _, edge := range edges {
-			if edge.SourceNodeKey == nodeKey {
-				nextNodes = append(nextNodes, edge.TargetNodeKey)
-			}
-		}
-		return nextNodes
-	}
-	results := make([]core.ExecuteResult, 0)
-nodeloop:
-	for {
-		select {
-		case nodeKey, ok := <-toBeExecuted:
-			if !ok {
-				fmt.Println("读取节点异常")
-				return nil, errors.New("读取节点异常")
-			}
-			node := nodeMap[nodeKey]
-			result, err := s.NodeExecutionService.ExecuteNode(node, inputs, results)
-			if err != nil {
-				return nil, fmt.Errorf("节点 %s 执行失败: %v", node.NodeKey, err)
-			}
-			results = append(results, core.ExecuteResult{
-				NodeID:  node.ID,
-				NodeKey: node.NodeKey,
-				Status:  result.Status,
-				Data:    result.Data,
-				Error:   result.Error,
-			})
-			nextKeys := getNextNodeKeys(nodeKey)
-			for _, nextKey := range nextKeys {
-				if _, ok := nodeMap[nextKey]; ok {
-					toBeExecuted <- nextKey
-				} else {
-					fmt.Printf("节点 %s 不存在\n", nextKey)
-				}
-			}
-		default:
-			if len(toBeExecuted) == 0 {
-				close(toBeExecuted)
-				break nodeloop
-			}
-		}
-	}
-	return results, nil
-}
 
-// getStartNodeKeyList 获取工作流的起始节点列表
-func (s *WorkflowService) getStartNodeKeyList(nodes []engine_nodes.Node, edges []engine.Edge) ([]string, error) {
-	if len(nodes) == 0 {
-		return nil, errors.New("工作流没有节点")
-	}
-	startNodeKeyList := make([]string, 0)
-	// 使用map来存储每个节点的入度
-	inDegree := make(map[string]int)
-	for _, node := range nodes {
-		inDegree[node.NodeKey] = 0
+	if execution.recorder != nil {
+		recording := &engine.InstanceRecording{InstanceID: instance.ID, Exchanges: execution.recorder.Exchanges()}
+		if err := s.DB.Create(recording).Error; err != nil {
+			return nil, fmt.Errorf("保存录制失败: %v", err)
+		}
 	}
 
-	// 计算每个节点的入度
-	for _, edge := range edges {
-		inDegree[edge.TargetNodeKey]++
-	}
+	return executionResult, nil
+}
 
-	// 找到入度为0的节点
-	for key, val := range inDegree {
-		if val == 0 {
-			startNodeKeyList = append(startNodeKeyList, key)
-		}
+// abortExecution 执行异常中断时结束流程实例: 标记为失败, 写入结束事件并关闭事件流
+func (s *WorkflowService) abortExecution(execution *workflowExecution, message string) {
+	instance := execution.instance
+	status := core.ExecuteStatusError
+	instance.Status = status
+	instance.EndTime = time.Now()
+	instance.ErrorMessage = message
+	instance.Duration = instance.EndTime.Sub(instance.StartTime).Milliseconds()
+	execution.stream.Emit(engine.ExecutionEvent{
+		Type:     engine.EventInstanceFinished,
+		Status:   &status,
+		Duration: instance.Duration,
+		Message:  message,
+	})
+	execution.stream.Close()
+	if err := s.completeInstance(instance, execution.executionLog.Executions(), execution.stream.Events()); err != nil {
+		log.Printf("保存流程实例 %d 失败: %v", instance.ID, err)
+		s.finishInstance(instance)
 	}
-
-	return startNodeKeyList, nil
 }
 
 // PublishWorkflow 发布工作流
@@ -542,6 +590,9 @@ func (s *WorkflowService) GetWorkflowInstances(workflowID uint, page, size int)
 		"todayCount":   stats.TodayCount,
 		"avgDuration":  uint(stats.AvgDuration),
 	}
+	if err := s.addNodeStatistics(workflowID, statistics); err != nil {
+		return nil, nil, err
+	}
 	if err := s.DB.Where("workflow_id = ?", workflowID).
 		Order("created_at DESC").
 		Offset((page - 1) * size).Limit(size).
@@ -549,20 +600,12 @@ func (s *WorkflowService) GetWorkflowInstances(workflowID uint, page, size int)
 		return nil, nil, err
 	}
 
-	for index, instance := range instances {
-		res := dto.WorkflowExecutionResult{}
-		res.WorkflowID = instance.WorkflowID
-		res.WorkflowName = instance.WorkflowName
-		res.Status = instance.Status
-		res.Duration = instance.Duration
-		res.ErrorMessage = instance.ErrorMessage
-		json.Unmarshal([]byte(instance.Results), &res.NodeResults)
-		resultBytes, err := json.Marshal(res)
-		if err != nil {
-			return nil, nil, err
-		}
-		instance.Results = string(resultBytes)
-		instances[index] = instance
+	nodeResults, err := s.instancesNodeResults(instances)
+	if err != nil {
+		return nil, nil, err
+	}
+	for index := range instances {
+		instances[index].NodeResults = nodeResults[instances[index].ID]
 	}
 
 	return instances, statistics, nil
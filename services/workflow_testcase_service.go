@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"api-flow/engine"
+	"api-flow/engine/engine_nodes"
+	"api-flow/mock"
+)
+
+var (
+	// ErrInvalidTestCase 测试用例配置无效
+	ErrInvalidTestCase = errors.New("测试用例配置无效")
+	// ErrTestCaseNotFound 测试用例不存在
+	ErrTestCaseNotFound = errors.New("测试用例不存在")
+)
+
+// ListTestCases 获取工作流的全部测试用例
+func (s *WorkflowService) ListTestCases(workflowID uint) ([]engine.TestCase, error) {
+	var testCases []engine.TestCase
+	if err := s.DB.Where("workflow_id = ? AND deleted_at IS NULL", workflowID).Order("id").Find(&testCases).Error; err != nil {
+		return nil, err
+	}
+	return testCases, nil
+}
+
+// CreateTestCase 为工作流创建测试用例
+func (s *WorkflowService) CreateTestCase(workflowID uint, testCase *engine.TestCase) error {
+	if _, err := s.GetWorkflowByID(workflowID); err != nil {
+		return err
+	}
+	if err := testCase.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTestCase, err)
+	}
+	testCase.ID = 0
+	testCase.WorkflowID = workflowID
+	return s.DB.Create(testCase).Error
+}
+
+// UpdateTestCase 更新测试用例, 所属工作流不变
+func (s *WorkflowService) UpdateTestCase(id uint, testCase *engine.TestCase) error {
+	if err := testCase.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTestCase, err)
+	}
+	existing, err := s.getTestCase(id)
+	if err != nil {
+		return err
+	}
+	testCase.BasicModel = existing.BasicModel
+	testCase.WorkflowID = existing.WorkflowID
+	return s.DB.Save(testCase).Error
+}
+
+// DeleteTestCase 删除测试用例 (软删除)
+func (s *WorkflowService) DeleteTestCase(id uint) error {
+	testCase, err := s.getTestCase(id)
+	if err != nil {
+		return err
+	}
+	return s.DB.Delete(testCase).Error
+}
+
+// RunTestCase 执行单个测试用例
+func (s *WorkflowService) RunTestCase(id uint) (*engine.TestReport, error) {
+	testCase, err := s.getTestCase(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.runTestCases(testCase.WorkflowID, []engine.TestCase{*testCase})
+}
+
+// RunTestCases 执行工作流的全部测试用例
+func (s *WorkflowService) RunTestCases(workflowID uint) (*engine.TestReport, error) {
+	testCases, err := s.ListTestCases(workflowID)
+	if err != nil {
+		return nil, err
+	}
+	return s.runTestCases(workflowID, testCases)
+}
+
+// runTestCases 依次执行测试用例并生成报告, 测试执行不记录到执行历史
+func (s *WorkflowService) runTestCases(workflowID uint, testCases []engine.TestCase) (*engine.TestReport, error) {
+	workflow, err := s.GetWorkflowByID(workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流失败: %v", err)
+	}
+	nodes, edges, err := s.loadGraph(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &engine.TestReport{
+		WorkflowID:   workflow.ID,
+		WorkflowName: workflow.Name,
+		Cases:        make([]engine.TestCaseResult, 0, len(testCases)),
+	}
+	var routes []mock.Route
+	for i := range testCases {
+		ctx := context.Background()
+		if testCases[i].Mock {
+			// 每个用例使用独立的模拟接口服务, 依次返回的模拟响应不受其他执行的影响
+			if routes == nil {
+				if routes, err = s.mockRoutes(); err != nil {
+					return nil, err
+				}
+			}
+			server := mock.NewServer()
+			if err := server.SetRoutes(routes); err != nil {
+				return nil, fmt.Errorf("加载模拟接口失败: %v", err)
+			}
+			ctx = server.Redirect(ctx)
+		}
+		report.Add(testCases[i].Run(ctx, s.NodeExecutionService.Runner(), nodes, edges))
+	}
+	return report, nil
+}
+
+// mockRoutes 已保存的全部模拟接口
+func (s *WorkflowService) mockRoutes() ([]mock.Route, error) {
+	routes := make([]mock.Route, 0)
+	if err := s.DB.Where("deleted_at IS NULL").Order("id").Find(&routes).Error; err != nil {
+		return nil, fmt.Errorf("获取模拟接口失败: %v", err)
+	}
+	return routes, nil
+}
+
+// loadGraph 获取工作流的全部节点与连线
+func (s *WorkflowService) loadGraph(workflowID uint) ([]engine_nodes.Node, []engine.Edge, error) {
+	var nodes []engine_nodes.Node
+	if err := s.DB.Where("workflow_id = ?", workflowID).Find(&nodes).Error; err != nil {
+		return nil, nil, fmt.Errorf("获取工作流节点失败: %v", err)
+	}
+
+	var edges []engine.Edge
+	if err := s.DB.Where("workflow_id = ?", workflowID).Find(&edges).Error; err != nil {
+		return nil, nil, fmt.Errorf("获取工作流连线失败: %v", err)
+	}
+	return nodes, edges, nil
+}
+
+func (s *WorkflowService) getTestCase(id uint) (*engine.TestCase, error) {
+	var testCase engine.TestCase
+	if err := s.DB.Where("id = ? AND deleted_at IS NULL", id).First(&testCase).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, ErrTestCaseNotFound
+		}
+		return nil, err
+	}
+	return &testCase, nil
+}
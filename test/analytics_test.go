@@ -0,0 +1,129 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"api-flow/engine"
+	"api-flow/engine/definition"
+)
+
+func TestWorkflowAnalytics(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	query := engine.AnalyticsQuery{From: from, To: from.Add(3 * time.Hour), Interval: engine.AnalyticsHourly}
+	if err := query.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(minutes int) time.Time { return from.Add(time.Duration(minutes) * time.Minute) }
+	var instances []engine.AnalyticsSample
+	var nodes []engine.NodeSample
+	// v1: 第一个小时 10 次, 耗时 10..100ms, 2 次失败
+	for i := 1; i <= 10; i++ {
+		failed := i > 8
+		instances = append(instances, engine.AnalyticsSample{Time: at(i), Duration: int64(i * 10), Failed: failed, Version: "v1"})
+		node := engine.NodeSample{
+			AnalyticsSample: engine.AnalyticsSample{Time: at(i), Duration: int64(i * 10), Failed: failed, Version: "v1"},
+			NodeKey:         "login",
+			NodeType:        "api",
+			Attempt:         1,
+		}
+		if failed {
+			node.Attempt = 3
+			node.Error = "HTTP 503"
+		}
+		nodes = append(nodes, node)
+	}
+	// v2: 第三个小时 2 次, 1 次失败
+	for i, failed := range []bool{false, true} {
+		instances = append(instances, engine.AnalyticsSample{Time: at(130 + i), Duration: 500, Failed: failed, Version: "v2"})
+		node := engine.NodeSample{
+			AnalyticsSample: engine.AnalyticsSample{Time: at(130 + i), Duration: 500, Failed: failed, Version: "v2"},
+			NodeKey:         "profile",
+			NodeType:        "api",
+			Attempt:         1,
+		}
+		if failed {
+			node.Error = "timeout"
+		}
+		nodes = append(nodes, node)
+	}
+
+	analytics := engine.BuildAnalytics(query, instances, nodes)
+	summary := analytics.Summary
+	if summary.Total != 12 || summary.Errors != 3 || summary.ErrorRate != 0.25 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.P50 != 60 || summary.P95 != 500 || summary.P99 != 500 {
+		t.Errorf("unexpected percentiles: %+v", summary)
+	}
+
+	if len(analytics.Series) != 3 {
+		t.Fatalf("unexpected series: %+v", analytics.Series)
+	}
+	if analytics.Series[0].Total != 10 || analytics.Series[1].Total != 0 || analytics.Series[2].Total != 2 || !analytics.Series[1].Time.Equal(from.Add(time.Hour)) {
+		t.Errorf("unexpected series: %+v", analytics.Series)
+	}
+
+	if len(analytics.Nodes) != 2 || analytics.Nodes[0].NodeKey != "login" || analytics.Nodes[0].Errors != 2 || analytics.Nodes[0].Retries != 4 {
+		t.Errorf("unexpected nodes: %+v", analytics.Nodes)
+	}
+	if len(analytics.Errors) != 2 || analytics.Errors[0].Message != "HTTP 503" || analytics.Errors[0].Count != 2 || !analytics.Errors[0].LastSeen.Equal(at(10)) {
+		t.Errorf("unexpected errors: %+v", analytics.Errors)
+	}
+
+	if len(analytics.Versions) != 2 || analytics.Versions[0].Version != "v1" || analytics.Versions[0].ErrorRate != 0.2 || analytics.Versions[1].ErrorRate != 0.5 {
+		t.Errorf("unexpected versions: %+v", analytics.Versions)
+	}
+
+	// 按版本筛选时版本对比仍包含全部版本
+	query.Version = "v2"
+	analytics = engine.BuildAnalytics(query, instances, nodes)
+	if analytics.Summary.Total != 2 || len(analytics.Nodes) != 1 || analytics.Nodes[0].NodeKey != "profile" || len(analytics.Versions) != 2 {
+		t.Errorf("unexpected filtered analytics: %+v", analytics)
+	}
+
+	invalid := []engine.AnalyticsQuery{
+		{From: from, To: from, Interval: engine.AnalyticsHourly},
+		{From: from, To: from.Add(time.Hour), Interval: "week"},
+		{From: from, To: from.AddDate(0, 3, 0), Interval: engine.AnalyticsHourly},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); err == nil {
+			t.Errorf("query should be invalid: %+v", q)
+		}
+	}
+}
+
+func TestDefinitionDigest(t *testing.T) {
+	def := &definition.WorkflowDefinition{
+		Name: "flow",
+		Nodes: []definition.NodeDefinition{
+			{Key: "a", Type: "api", Config: map[string]interface{}{"url": "https://a.invalid", "method": "GET"}},
+			{Key: "b", Type: "text", UI: map[string]interface{}{"x": 1}},
+		},
+		Edges: []definition.EdgeDefinition{{Source: "a", Target: "b"}},
+	}
+	digest := def.Digest()
+	if len(digest) != 12 {
+		t.Fatalf("unexpected digest %q", digest)
+	}
+
+	// 名称、布局与顺序不影响版本
+	reordered := &definition.WorkflowDefinition{
+		Name: "renamed",
+		Nodes: []definition.NodeDefinition{
+			{Key: "b", Type: "text", UI: map[string]interface{}{"x": 200}},
+			{Key: "a", Type: "api", Config: map[string]interface{}{"method": "GET", "url": "https://a.invalid"}},
+		},
+		Edges: []definition.EdgeDefinition{{Source: "a", Target: "b"}},
+	}
+	if reordered.Digest() != digest {
+		t.Error("digest should ignore names, layout and order")
+	}
+
+	reordered.Nodes[1].Config["url"] = "https://b.invalid"
+	if reordered.Digest() == digest {
+		t.Error("digest should change with node config")
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/jsonpath"
+	"api-flow/engine/jsonschema"
+)
+
+func TestJSONPathLookup(t *testing.T) {
+	data := map[string]interface{}{
+		"data": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"id": 1.0, "tags": []interface{}{"a"}},
+				map[string]interface{}{"id": 2.0},
+			},
+			"next-cursor": "abc",
+		},
+	}
+	cases := map[string]interface{}{
+		"$.data.items[0].id":      1.0,
+		"data.items[-1].id":       2.0,
+		"$.data['next-cursor']":   "abc",
+		"$.data.items[*].id":      []interface{}{1.0, 2.0},
+		"$..id":                   []interface{}{1.0, 2.0},
+		"$.data.items[0].tags[0]": "a",
+	}
+	for expr, expected := range cases {
+		value, ok, err := jsonpath.Lookup(data, expr)
+		if err != nil || !ok {
+			t.Errorf("%s: lookup failed: %v", expr, err)
+			continue
+		}
+		if core.Sprint(value) != core.Sprint(expected) {
+			t.Errorf("%s: expected %v, got %v", expr, expected, value)
+		}
+	}
+	if _, ok, _ := jsonpath.Lookup(data, "$.data.missing"); ok {
+		t.Error("missing path should not match")
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"id", "name"},
+		"properties": map[string]interface{}{
+			"id":   map[string]interface{}{"type": "integer", "minimum": 1.0},
+			"name": map[string]interface{}{"$ref": "#/definitions/name"},
+		},
+		"definitions": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string", "minLength": 2.0},
+		},
+	}
+	if errs := jsonschema.Validate(schema, map[string]interface{}{"id": 3.0, "name": "ok"}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	errs := jsonschema.Validate(schema, map[string]interface{}{"id": 0.5, "name": "x"})
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+}
+
+func TestAPINodeAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7, "name": "demo", "items": []}`))
+	}))
+	defer server.Close()
+
+	config := core.ItemConfig{
+		"url":    server.URL,
+		"method": "GET",
+		"assertions": map[string]interface{}{
+			"status":  []interface{}{"2xx"},
+			"headers": []interface{}{map[string]interface{}{"name": "content-type", "contains": "json"}},
+			"body": []interface{}{
+				map[string]interface{}{"path": "$.id", "equals": 7.0},
+				map[string]interface{}{"path": "$.name", "regex": "^dem"},
+			},
+			"maxLatency": 5000.0,
+			"schema":     map[string]interface{}{"type": "object", "required": []interface{}{"id"}},
+		},
+	}
+	executor := engine_nodes.NewAPINodeExecutor()
+	if err := executor.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	node := &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: config}
+	if result := executor.Execute(context.Background(), node, nil); result.Status != core.ExecuteStatusSuccess {
+		t.Fatalf("assertions should pass: %s", result.Error)
+	}
+
+	config["assertions"] = map[string]interface{}{
+		"status": []interface{}{201},
+		"body": []interface{}{
+			map[string]interface{}{"path": "$.id", "equals": 8.0},
+			map[string]interface{}{"path": "$.missing"},
+		},
+	}
+	result := executor.Execute(context.Background(), node, nil)
+	if result.Status != core.ExecuteStatusError {
+		t.Fatal("assertions should fail")
+	}
+	if !strings.HasPrefix(result.Error, "断言失败(3项)") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+}
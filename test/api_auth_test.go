@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/secrets"
+)
+
+func TestAPINodeOAuth2ClientCredentials(t *testing.T) {
+	secrets.Register(map[string]string{"oauth_client_secret": "s3cret"})
+
+	var issued int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+	}))
+	defer tokenServer.Close()
+
+	// 第一个令牌被服务端吊销, 节点应重新获取令牌后重试
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer api.Close()
+
+	node := &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: core.ItemConfig{
+		"url":    api.URL,
+		"method": "GET",
+		"auth": map[string]interface{}{
+			"type":         "oauth2",
+			"grantType":    "client_credentials",
+			"tokenUrl":     tokenServer.URL,
+			"clientId":     "client",
+			"clientSecret": map[string]interface{}{"secret": "oauth_client_secret"},
+		},
+	}}
+	executor := engine_nodes.NewAPINodeExecutor()
+	if err := executor.ValidateConfig(node.Config); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		result := executor.Execute(context.Background(), node, map[string]interface{}{})
+		if result.Status != core.ExecuteStatusSuccess {
+			t.Fatalf("execution %d failed: %+v", i, result)
+		}
+	}
+	if issued != 2 {
+		t.Errorf("expected token to be fetched twice (initial + after 401), got %d", issued)
+	}
+}
+
+func TestAPINodeAuthRejectsInlineCredentials(t *testing.T) {
+	config := core.ItemConfig{
+		"url":    "https://example.com",
+		"method": "GET",
+		"auth":   map[string]interface{}{"type": "bearer", "token": "plain-text"},
+	}
+	if err := engine_nodes.NewAPINodeExecutor().ValidateConfig(config); err == nil {
+		t.Error("inline bearer token should be rejected")
+	}
+}
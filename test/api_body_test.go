@@ -0,0 +1,136 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// echoServer 返回收到的查询参数、Content-Type 与表单内容
+func echoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := map[string]interface{}{
+			"query":       r.URL.RawQuery,
+			"contentType": r.Header.Get("Content-Type"),
+		}
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			result["form"] = r.MultipartForm.Value
+			if files := r.MultipartForm.File["avatar"]; len(files) == 1 {
+				file, _ := files[0].Open()
+				content, _ := io.ReadAll(file)
+				result["file"] = files[0].Filename + ":" + string(content)
+			}
+		} else if err := r.ParseForm(); err == nil && len(r.PostForm) > 0 {
+			result["form"] = r.PostForm
+		} else {
+			body, _ := io.ReadAll(r.Body)
+			result["body"] = string(body)
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+}
+
+func executeAPINode(t *testing.T, config core.ItemConfig, inputs map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	node := &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: config}
+	executor := engine_nodes.NewAPINodeExecutor()
+	if err := executor.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	result := executor.Execute(context.Background(), node, inputs)
+	if result.Status != core.ExecuteStatusSuccess {
+		t.Fatalf("execution failed: %+v", result)
+	}
+	return result.Data["response"].(map[string]interface{})
+}
+
+func TestAPINodeQueryParamsAndBodyModes(t *testing.T) {
+	server := echoServer()
+	defer server.Close()
+	inputs := map[string]interface{}{"name": "张三", "page": 2}
+
+	response := executeAPINode(t, core.ItemConfig{
+		"url":    server.URL + "?page=1&lang=zh",
+		"method": "GET",
+		"params": map[string]interface{}{"page": "{{.page}}", "tag": []interface{}{"a", "b"}},
+	}, inputs)
+	if response["query"] != "lang=zh&page=2&tag=a&tag=b" {
+		t.Errorf("unexpected query: %v", response["query"])
+	}
+
+	response = executeAPINode(t, core.ItemConfig{
+		"url":      server.URL,
+		"method":   "POST",
+		"bodyType": "form",
+		"body":     `{"name": "{{.name}}", "age": 18}`,
+	}, inputs)
+	if response["contentType"] != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected content type: %v", response["contentType"])
+	}
+	if form, _ := json.Marshal(response["form"]); string(form) != `{"age":["18"],"name":["张三"]}` {
+		t.Errorf("unexpected form: %s", form)
+	}
+
+	response = executeAPINode(t, core.ItemConfig{
+		"url":      server.URL,
+		"method":   "POST",
+		"bodyType": "multipart",
+		"body": map[string]interface{}{
+			"name":   "{{.name}}",
+			"avatar": map[string]interface{}{"filename": "a.txt", "content": "aGVsbG8=", "encoding": "base64"},
+		},
+	}, inputs)
+	if response["file"] != "a.txt:hello" {
+		t.Errorf("unexpected file part: %v", response["file"])
+	}
+
+	response = executeAPINode(t, core.ItemConfig{
+		"url":    server.URL,
+		"method": "POST",
+		"body":   map[string]interface{}{"name": "{{.name}}"},
+	}, inputs)
+	if response["contentType"] != "application/json" || response["body"] != `{"name":"张三"}` {
+		t.Errorf("unexpected json body: %v", response)
+	}
+}
+
+func TestAPINodeResponseDetails(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Header().Set("Link", `</new?page=2>; rel="next"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	node := &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: core.ItemConfig{"url": server.URL + "/old", "method": "GET"}}
+	result := engine_nodes.NewAPINodeExecutor().Execute(context.Background(), node, nil)
+	if result.Status != core.ExecuteStatusSuccess {
+		t.Fatalf("execution failed: %+v", result)
+	}
+	data := result.Data
+	if data["status"] != http.StatusCreated || data["url"] != server.URL+"/new" || data["size"] != 8 {
+		t.Errorf("unexpected response details: %v", data)
+	}
+	if data["headers"].(map[string]interface{})["Link"] != `</new?page=2>; rel="next"` {
+		t.Errorf("unexpected headers: %v", data["headers"])
+	}
+	if data["cookies"].(map[string]interface{})["session"] != "abc" {
+		t.Errorf("unexpected cookies: %v", data["cookies"])
+	}
+	if _, ok := data["timing"].(map[string]interface{})["ttfb"]; !ok {
+		t.Errorf("timing missing: %v", data["timing"])
+	}
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+func TestAPINodePagination(t *testing.T) {
+	// 共 5 条数据, 每页 2 条
+	var failures int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		// 第 2 页第一次请求返回 503, 验证分页请求会走重试
+		if r.URL.Query().Get("page") == "2" && atomic.AddInt32(&failures, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		items := ""
+		for i := (page-1)*2 + 1; i <= page*2 && i <= 5; i++ {
+			if items != "" {
+				items += ","
+			}
+			items += strconv.Itoa(i)
+		}
+		fmt.Fprintf(w, `{"data":{"list":[%s]}}`, items)
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"items":[1,2],"next":"c1"}`)
+		case "c1":
+			fmt.Fprint(w, `{"items":[3],"next":null}`)
+		}
+	})
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("p") == "" {
+			w.Header().Set("Link", `</link?p=2>; rel="next", </link?p=2>; rel="last"`)
+			fmt.Fprint(w, `[1,2]`)
+			return
+		}
+		fmt.Fprint(w, `[3]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := []struct {
+		name       string
+		url        string
+		pagination map[string]interface{}
+		items      string
+		pages      int
+	}{
+		{"page", "/page", map[string]interface{}{"type": "page", "itemsPath": "$.data.list", "sizeParam": "size", "size": 2.0}, "[1 2 3 4 5]", 3},
+		{"cursor", "/cursor", map[string]interface{}{"type": "cursor", "itemsPath": "items", "cursorParam": "after", "cursorPath": "$.next"}, "[1 2 3]", 2},
+		{"link", "/link", map[string]interface{}{"type": "link"}, "[1 2 3]", 2},
+		{"maxPages", "/page", map[string]interface{}{"type": "page", "itemsPath": "$.data.list", "maxPages": 1.0}, "[1 2]", 1},
+	}
+	executor := engine_nodes.NewAPINodeExecutor()
+	for _, c := range cases {
+		config := core.ItemConfig{
+			"url":           server.URL + c.url,
+			"method":        "GET",
+			"retry":         1.0,
+			"retryInterval": 0.0,
+			"pagination":    c.pagination,
+		}
+		if err := executor.ValidateConfig(config); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		result := executor.Execute(context.Background(), &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: config}, nil)
+		if result.Status != core.ExecuteStatusSuccess {
+			t.Fatalf("%s: execution failed: %s", c.name, result.Error)
+		}
+		if items := core.Sprint(result.Data["items"]); items != c.items || result.Data["pages"] != c.pages {
+			t.Errorf("%s: unexpected items %s (%v pages)", c.name, items, result.Data["pages"])
+		}
+	}
+}
+
+func TestAPINodeRetryCancelled(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// 重试间隔 10s, 执行取消后应立即停止重试
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	config := core.ItemConfig{
+		"url":           server.URL,
+		"method":        "GET",
+		"retry":         3.0,
+		"retryInterval": 10.0,
+	}
+	start := time.Now()
+	result := engine_nodes.NewAPINodeExecutor().Execute(ctx, &engine_nodes.Node{NodeKey: "api", NodeType: "api", Config: config}, nil)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("retry should stop when the execution is cancelled, took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); result.Status != core.ExecuteStatusError || n != 1 {
+		t.Errorf("expected a failed result after one attempt, got %v (%d calls)", result.Status, n)
+	}
+}
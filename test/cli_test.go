@@ -0,0 +1,70 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"api-flow/cli"
+)
+
+// 文本节点输出输入参数 content, content 不是字符串时节点执行失败
+const cliFlow = `{
+  "version": 1,
+  "name": "echo",
+  "nodes": [{"key": "echo", "name": "回显", "type": "text", "config": {"content_type": "plain_text"}}]
+}`
+
+func runCLI(t *testing.T, args ...string) (int, map[string]interface{}, string) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Execute(args, &stdout, &stderr)
+	var output map[string]interface{}
+	if stdout.Len() > 0 {
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+		}
+	}
+	return code, output, stderr.String()
+}
+
+func TestCLIRun(t *testing.T) {
+	dir, err := os.MkdirTemp("", "api-flow-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "flow.json")
+	if err := os.WriteFile(path, []byte(cliFlow), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 输入参数不是合法JSON时按字符串处理, 文件路径可以出现在参数之后
+	code, output, stderr := runCLI(t, "--json", "--input", "content=hello world", path)
+	if code != cli.ExitSuccess || output["success"] != true {
+		t.Fatalf("run should succeed, got %d %v %s", code, output, stderr)
+	}
+	results, _ := output["nodeResults"].([]interface{})
+	if len(results) != 1 {
+		t.Fatalf("unexpected results: %v", output)
+	}
+	data, _ := results[0].(map[string]interface{})["data"].(map[string]interface{})
+	if data["output"] != "hello world" {
+		t.Errorf("input should be passed as a string: %v", data)
+	}
+
+	// 合法JSON按JSON解析: 42 是数字, 文本节点执行失败, 退出码非0
+	code, output, _ = runCLI(t, path, "--json", "--input", "content=42")
+	if code != cli.ExitFailure || output["success"] != false {
+		t.Errorf("failed node should exit with %d, got %d %v", cli.ExitFailure, code, output)
+	}
+
+	if code, _, stderr := runCLI(t, path, "--input", "content"); code != cli.ExitUsage || !strings.Contains(stderr, "key=value") {
+		t.Errorf("malformed input should be a usage error, got %d %s", code, stderr)
+	}
+	if code, _, _ := runCLI(t, filepath.Join(dir, "missing.json")); code != cli.ExitFailure {
+		t.Errorf("missing definition file should fail, got %d", code)
+	}
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+func debugWorkflow() ([]engine_nodes.Node, []engine.Edge) {
+	text := func(key, content string) engine_nodes.Node {
+		return engine_nodes.Node{NodeKey: key, NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": content},
+		}}
+	}
+	nodes := []engine_nodes.Node{text("a", "{{.name}}"), text("b", "${a.output}"), text("c", "${b.output}!")}
+	return nodes, []engine.Edge{engine.NewEdge("a", "b"), engine.NewEdge("b", "c")}
+}
+
+func TestDebugSession(t *testing.T) {
+	nodes, edges := debugWorkflow()
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	session := engine.NewDebugSession([]string{"b"}, time.Minute)
+	session.Run(context.Background(), runner, nodes, edges, map[string]interface{}{"name": "bob"})
+
+	state := session.Wait(context.Background())
+	if state.Status != engine.DebugPaused || state.Paused.NodeKey != "b" || !state.Paused.Breakpoint || len(state.Results) != 1 {
+		t.Fatalf("should pause at breakpoint b: %+v", state)
+	}
+
+	// 修改输入后单步执行, 在 c 前暂停
+	if err := session.Command(engine.DebugCommand{Action: engine.DebugStep, Inputs: map[string]interface{}{"content": "edited"}}); err != nil {
+		t.Fatal(err)
+	}
+	state = session.Wait(context.Background())
+	if state.Status != engine.DebugPaused || state.Paused.NodeKey != "c" || state.Paused.Breakpoint {
+		t.Fatalf("should pause at c after step: %+v", state)
+	}
+	if output := state.Results[1].Data["output"]; output != "edited" {
+		t.Errorf("b should use edited inputs, got %v", output)
+	}
+
+	if err := session.Command(engine.DebugCommand{Action: engine.DebugSkip}); err != nil {
+		t.Fatal(err)
+	}
+	state = session.Wait(context.Background())
+	if state.Status != engine.DebugCompleted || len(state.Results) != 3 || state.Results[2].Status != core.ExecuteStatusReady {
+		t.Fatalf("should complete with c skipped: %+v", state)
+	}
+	if err := session.Command(engine.DebugCommand{Action: engine.DebugContinue}); !errors.Is(err, engine.ErrDebugNotPaused) {
+		t.Errorf("command after completion should fail: %v", err)
+	}
+}
+
+func TestDebugSessionTimeoutAndAbort(t *testing.T) {
+	nodes, edges := debugWorkflow()
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+
+	abandoned := engine.NewDebugSession([]string{"a"}, 50*time.Millisecond)
+	abandoned.Run(context.Background(), runner, nodes, edges, nil)
+	<-abandoned.Done()
+	if state := abandoned.State(); state.Status != engine.DebugAborted || !strings.Contains(state.Error, "超时") {
+		t.Errorf("abandoned session should time out: %+v", state)
+	}
+
+	aborted := engine.NewDebugSession([]string{"c"}, time.Minute)
+	aborted.Run(context.Background(), runner, nodes, edges, nil)
+	if state := aborted.Wait(context.Background()); state.Paused == nil || state.Paused.NodeKey != "c" {
+		t.Fatalf("should pause at c: %+v", state)
+	}
+	aborted.Abort()
+	<-aborted.Done()
+	if state := aborted.State(); state.Status != engine.DebugAborted || !strings.Contains(state.Error, "终止") {
+		t.Errorf("session should be aborted: %+v", state)
+	}
+}
+
+// panicExecutor 执行时 panic 的节点执行器
+type panicExecutor struct{}
+
+func (panicExecutor) Execute(ctx context.Context, node *engine_nodes.Node, inputs map[string]interface{}) *core.ExecuteResult {
+	panic("boom")
+}
+
+func (panicExecutor) ValidateConfig(config core.ItemConfig) error { return nil }
+
+func (panicExecutor) GetOutputFormat() core.ParamFormat { return nil }
+
+func TestDebugSessionRecoversPanic(t *testing.T) {
+	nodeEngine := engine_nodes.NewNodeEngine()
+	nodeEngine.RegisterExecutor("panic", panicExecutor{})
+	runner := engine.NewRunner(nodeEngine)
+	nodes := []engine_nodes.Node{{NodeKey: "a", NodeType: "panic"}}
+
+	session := engine.NewDebugSession(nil, time.Minute)
+	session.Run(context.Background(), runner, nodes, nil, nil)
+	select {
+	case <-session.Done():
+	case <-time.After(time.Second):
+		t.Fatal("session should finish when a node panics")
+	}
+	if state := session.State(); state.Status != engine.DebugAborted || !strings.Contains(state.Error, "boom") {
+		t.Errorf("session should be aborted with the panic message: %+v", state)
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"testing"
+
+	"api-flow/engine/core"
+	"api-flow/engine/definition"
+	"api-flow/engine/engine_nodes"
+)
+
+const definitionYAML = `
+version: 1
+name: 示例流程
+nodes:
+  - key: input
+    type: execInput
+    name: 输入
+    config:
+      params:
+        - field: content
+          type: string
+  - key: echo
+    type: text
+    name: 文本
+    config:
+      inputs:
+        content: ${input.content}
+    ui:
+      x: 100
+      y: 200
+edges:
+  - source: input
+    target: echo
+`
+
+func TestDefinitionRoundTrip(t *testing.T) {
+	def, err := definition.Unmarshal([]byte(definitionYAML), definition.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := def.Validate(nil); len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if x, ok := def.Nodes[1].UI["x"].(float64); !ok || x != 100 {
+		t.Errorf("ui.x should decode as float64, got %#v", def.Nodes[1].UI["x"])
+	}
+
+	data, err := definition.Marshal(def, definition.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := definition.Unmarshal(data, definition.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Nodes) != 2 || len(again.Edges) != 1 {
+		t.Errorf("round trip lost nodes or edges: %+v", again)
+	}
+}
+
+func TestDefinitionRemapKeys(t *testing.T) {
+	def, err := definition.Unmarshal([]byte(definitionYAML), definition.FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping := def.RemapKeys(func(key string) bool { return key == "input" })
+	newKey, ok := mapping["input"]
+	if !ok {
+		t.Fatal("conflicting key was not remapped")
+	}
+	if def.Edges[0].Source != newKey {
+		t.Errorf("edge source not remapped: %s", def.Edges[0].Source)
+	}
+	inputs := def.Nodes[1].Config["inputs"].(map[string]interface{})
+	if inputs["content"] != "${"+newKey+".content}" {
+		t.Errorf("expression not remapped: %v", inputs["content"])
+	}
+}
+
+func TestDefinitionValidate(t *testing.T) {
+	def := &definition.WorkflowDefinition{
+		Version: 1,
+		Name:    "bad",
+		Nodes: []definition.NodeDefinition{
+			{Key: "a", Type: "unknown", Name: "a"},
+			{Key: "a", Type: "text", Name: "b"},
+		},
+		Edges: []definition.EdgeDefinition{{Source: "a", Target: "missing"}},
+	}
+	problems := def.Validate(func(code string) bool { return code == "text" })
+	if len(problems) != 3 {
+		t.Errorf("expected 3 problems, got %v", problems)
+	}
+}
+
+// 节点配置中的 outputFormat 由用户编辑, 可能包含 null 或没有字段名的元素
+func TestWorkflowOutputsSkipsInvalidParams(t *testing.T) {
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"outputFormat": []interface{}{nil, map[string]interface{}{"type": "string"}, map[string]interface{}{"field": "token", "type": "string"}},
+		}},
+	}
+	outputs := definition.WorkflowOutputs(nodes, nil)
+	if len(outputs) != 1 || outputs[0].Field != "login.token" {
+		t.Errorf("invalid output params should be skipped: %+v", outputs)
+	}
+}
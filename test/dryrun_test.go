@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+	"api-flow/secrets"
+)
+
+func TestDryRun(t *testing.T) {
+	secrets.Register(map[string]string{"dry_run_token": "t0ps3cret"})
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    server.URL + "/login",
+			"method": "POST",
+			"body":   map[string]interface{}{"user": "{{.user}}"},
+			"auth":   map[string]interface{}{"type": "bearer", "token": map[string]interface{}{"secret": "dry_run_token"}},
+		}},
+		{NodeKey: "profile", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    server.URL + "/profile/{{.user}}",
+			"method": "GET",
+			"params": map[string]interface{}{"via": "{{.via}}"},
+			"inputs": map[string]interface{}{"via": "${login.statusText}"},
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": "${login.statusText}"},
+		}},
+	}
+	edges := []engine.Edge{engine.NewEdge("login", "profile"), engine.NewEdge("login", "greet")}
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	results, err := runner.RunContext(outbound.WithDryRun(context.Background()), nodes, edges, map[string]interface{}{"user": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatalf("dry run should not send requests, got %d", calls)
+	}
+
+	byKey := make(map[string]core.ExecuteResult)
+	for _, result := range results {
+		if result.Status != core.ExecuteStatusSuccess {
+			t.Fatalf("%s failed: %+v", result.NodeKey, result)
+		}
+		byKey[result.NodeKey] = result
+	}
+
+	login := byKey["login"].Data
+	request := login["request"].(map[string]interface{})
+	headers := request["headers"].(map[string]interface{})
+	if request["method"] != "POST" || request["url"] != server.URL+"/login" || request["body"] != `{"user":"bob"}` {
+		t.Errorf("unexpected rendered request: %v", request)
+	}
+	if headers["Authorization"] != "Bearer ***" || headers["Content-Type"] != "application/json" {
+		t.Errorf("unexpected rendered headers: %v", headers)
+	}
+	if login["status"] != 0 || login["statusText"] != "<login.statusText>" {
+		t.Errorf("unexpected placeholder outputs: %v", login)
+	}
+
+	profile := byKey["profile"].Data["request"].(map[string]interface{})
+	if url := profile["url"].(string); !strings.HasPrefix(url, server.URL+"/profile/bob?via=") || !strings.Contains(url, "login.statusText") {
+		t.Errorf("downstream node should use placeholder outputs: %v", url)
+	}
+	if output := byKey["greet"].Data["output"]; output != "<login.statusText>" {
+		t.Errorf("unexpected text output: %v", output)
+	}
+
+	// 未实现 DryRunner 的执行器发出的请求也会被拒绝
+	req, _ := http.NewRequestWithContext(outbound.WithDryRun(context.Background()), "GET", server.URL, nil)
+	if _, err := outbound.Client().Do(req); !errors.Is(err, outbound.ErrDryRun) {
+		t.Errorf("request should be refused in dry run: %v", err)
+	}
+}
+
+// 导入的 API 节点以 response.id 的形式声明输出字段, 试运行的占位输出应与执行时一样是嵌套对象
+func TestDryRunNestedOutputFormat(t *testing.T) {
+	nodes := []engine_nodes.Node{
+		{NodeKey: "create", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    "http://example.com/users",
+			"method": "POST",
+			"outputFormat": core.ParamFormat{
+				core.NewParamDefination("response.id", core.DataTypeString, "用户ID"),
+				core.NewParamDefination("response.profile.age", core.DataTypeNumber, "年龄"),
+				core.NewParamDefination("status", core.DataTypeNumber, "状态码"),
+			},
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": "${create.response.id}"},
+		}},
+	}
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	results, err := runner.RunContext(outbound.WithDryRun(context.Background()), nodes, []engine.Edge{engine.NewEdge("create", "greet")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := make(map[string]core.ExecuteResult)
+	for _, result := range results {
+		if result.Status != core.ExecuteStatusSuccess {
+			t.Fatalf("%s failed: %+v", result.NodeKey, result)
+		}
+		byKey[result.NodeKey] = result
+	}
+
+	response, ok := byKey["create"].Data["response"].(map[string]interface{})
+	if !ok || response["id"] != "<create.response.id>" {
+		t.Fatalf("dotted fields should become nested placeholders: %v", byKey["create"].Data)
+	}
+	if profile, _ := response["profile"].(map[string]interface{}); profile == nil || profile["age"] != 0 {
+		t.Errorf("unexpected nested placeholder: %v", response)
+	}
+	if output := byKey["greet"].Data["output"]; output != "<create.response.id>" {
+		t.Errorf("downstream reference should resolve to the placeholder, got %v", output)
+	}
+}
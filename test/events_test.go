@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/wsclient"
+)
+
+func TestExecutionEvents(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Bob"}`))
+	}))
+	defer server.Close()
+
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":           server.URL + "/login",
+			"method":        "GET",
+			"retry":         1.0,
+			"retryInterval": 0.0,
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": "${login.response.name}", "extra": "${missing.value}"},
+		}},
+	}
+	edges := []engine.Edge{engine.NewEdge("login", "greet")}
+
+	stream := engine.NewEventStream()
+	received := make(chan []engine.ExecutionEvent)
+	go func() {
+		var all []engine.ExecutionEvent
+		after := 0
+		for {
+			events, more := stream.Wait(context.Background(), after)
+			if !more {
+				received <- all
+				return
+			}
+			all = append(all, events...)
+			after = events[len(events)-1].Seq
+		}
+	}()
+
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	if _, err := runner.RunContext(engine.WithEventStream(context.Background(), stream), nodes, edges, nil); err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+	events := <-received
+
+	expected := []struct{ kind, nodeKey string }{
+		{engine.EventNodeStarted, "login"},
+		{engine.EventNodeRetry, "login"},
+		{engine.EventNodeFinished, "login"},
+		{engine.EventLog, "greet"},
+		{engine.EventNodeStarted, "greet"},
+		{engine.EventNodeFinished, "greet"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	for i, event := range events {
+		if event.Seq != i+1 || event.Type != expected[i].kind || event.NodeKey != expected[i].nodeKey {
+			t.Errorf("event %d: expected %s %s, got %+v", i, expected[i].kind, expected[i].nodeKey, event)
+		}
+	}
+	if retry := events[1]; retry.Attempt != 2 || retry.Message != "HTTP 503" {
+		t.Errorf("unexpected retry event: %+v", retry)
+	}
+	if finished := events[2]; finished.Attempt != 2 || finished.Result == nil || finished.Result.Status != core.ExecuteStatusSuccess {
+		t.Errorf("unexpected finished event: %+v", finished)
+	}
+	if finished := events[5]; finished.Result == nil || finished.Result.Data["output"] != "Bob" {
+		t.Errorf("unexpected finished event: %+v", finished)
+	}
+
+	// 已结束的事件流从指定序号之后回放
+	replay := engine.ClosedEventStream(stream.Events())
+	tail, more := replay.Wait(context.Background(), 4)
+	if !more || len(tail) != 2 || tail[0].Seq != 5 {
+		t.Fatalf("unexpected replay: %+v", tail)
+	}
+	if _, more := replay.Wait(context.Background(), 6); more {
+		t.Error("closed stream should have no more events")
+	}
+}
+
+func TestWebSocketAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsclient.Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.WriteMessage(wsclient.OpText, append([]byte("echo: "), message...))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := wsclient.Dial(ctx, server.Client(), "ws"+server.URL[len("http"):], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(wsclient.OpText, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	opcode, message, err := conn.ReadMessage()
+	if err != nil || opcode != wsclient.OpText || string(message) != "echo: hello" {
+		t.Fatalf("unexpected message %d %q: %v", opcode, message, err)
+	}
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain request should be rejected, got %d", resp.StatusCode)
+	}
+}
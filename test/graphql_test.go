@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// graphqlServer 根据查询返回固定结果: user 查询回显变量, broken 返回部分数据与错误, 内省查询返回简单的 schema
+func graphqlServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []interface{}{map[string]interface{}{"message": "bad request"}}})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(request.Query, "__schema"):
+			w.Write([]byte(introspectionResult))
+		case strings.Contains(request.Query, "broken"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":   map[string]interface{}{"broken": nil, "ok": true},
+				"errors": []interface{}{map[string]interface{}{"message": "boom", "path": []interface{}{"broken"}}},
+			})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"user": map[string]interface{}{
+					"variables":     request.Variables,
+					"operationName": request.OperationName,
+					"auth":          r.Header.Get("X-Token"),
+				}},
+			})
+		}
+	}))
+}
+
+const introspectionResult = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "description": "按ID查询用户", "args": [
+        {"name": "id", "defaultValue": null, "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}}
+      ], "type": {"kind": "OBJECT", "name": "User", "ofType": null}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}},
+      {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String", "ofType": null}},
+      {"name": "friends", "args": [], "type": {"kind": "LIST", "name": null, "ofType": {"kind": "OBJECT", "name": "User", "ofType": null}}}
+    ]}
+  ]
+}}}`
+
+func executeGraphQLNode(t *testing.T, config core.ItemConfig, inputs map[string]interface{}) *core.ExecuteResult {
+	t.Helper()
+	node := &engine_nodes.Node{NodeKey: "gql", NodeType: "graphql", Config: config}
+	executor := engine_nodes.NewGraphQLNodeExecutor()
+	if err := executor.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	return executor.Execute(context.Background(), node, inputs)
+}
+
+func TestGraphQLNodeVariablesAndHeaders(t *testing.T) {
+	server := graphqlServer()
+	defer server.Close()
+
+	result := executeGraphQLNode(t, core.ItemConfig{
+		"endpoint":      server.URL,
+		"query":         "query GetUser($id: ID!, $filter: Filter) { user(id: $id) { name } }",
+		"operationName": "GetUser",
+		"variables":     map[string]interface{}{"id": "{{.id}}", "filter": map[string]interface{}{"name": "u-{{.name}}", "tags": "{{index . \"tag-list\"}}"}},
+		"headers":       map[string]interface{}{"X-Token": "t-{{.id}}"},
+	}, map[string]interface{}{"id": 42.0, "name": "张三", "tag-list": []interface{}{"a"}})
+	if result.Status != core.ExecuteStatusSuccess {
+		t.Fatalf("execution failed: %+v", result)
+	}
+	user := result.Data["data"].(map[string]interface{})["user"].(map[string]interface{})
+	if variables, _ := json.Marshal(user["variables"]); string(variables) != `{"filter":{"name":"u-张三","tags":["a"]},"id":42}` {
+		t.Errorf("unexpected variables: %s", variables)
+	}
+	if user["operationName"] != "GetUser" || user["auth"] != "t-42" {
+		t.Errorf("unexpected request: %v", user)
+	}
+	if errs := result.Data["errors"].([]interface{}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestGraphQLNodeErrorPolicy(t *testing.T) {
+	server := graphqlServer()
+	defer server.Close()
+	config := func(policy string) core.ItemConfig {
+		return core.ItemConfig{"endpoint": server.URL, "query": "{ broken ok }", "errorPolicy": policy}
+	}
+
+	result := executeGraphQLNode(t, config(""), nil)
+	if result.Status != core.ExecuteStatusError || result.Error != "GraphQL错误: boom (broken)" {
+		t.Errorf("default policy should fail: %+v", result)
+	}
+	if len(result.Data["errors"].([]interface{})) != 1 || result.Data["data"] == nil {
+		t.Errorf("data and errors should both be surfaced: %v", result.Data)
+	}
+
+	if result := executeGraphQLNode(t, config(engine_nodes.GraphQLErrorsPartial), nil); result.Status != core.ExecuteStatusSuccess {
+		t.Errorf("partial policy should succeed with data: %+v", result)
+	}
+	if result := executeGraphQLNode(t, config(engine_nodes.GraphQLErrorsIgnore), nil); result.Status != core.ExecuteStatusSuccess {
+		t.Errorf("ignore policy should succeed: %+v", result)
+	}
+
+	if err := engine_nodes.NewGraphQLNodeExecutor().ValidateConfig(config("bogus")); err == nil {
+		t.Error("expected invalid errorPolicy to be rejected")
+	}
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	server := graphqlServer()
+	defer server.Close()
+
+	operations, err := engine_nodes.NewGraphQLNodeExecutor().Introspect(core.ItemConfig{"endpoint": server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operations) != 1 {
+		t.Fatalf("unexpected operations: %+v", operations)
+	}
+	op := operations[0]
+	if op.Type != "query" || op.Name != "user" || op.ReturnType != "User" {
+		t.Errorf("unexpected operation: %+v", op)
+	}
+	if len(op.Args) != 1 || op.Args[0].Type != "ID!" || !op.Args[0].Required {
+		t.Errorf("unexpected args: %+v", op.Args)
+	}
+	expected := "query user($id: ID!) {\n  user(id: $id) {\n    id\n    name\n  }\n}"
+	if op.Query != expected {
+		t.Errorf("unexpected query:\n%s", op.Query)
+	}
+	if op.Variables["id"] != "{{.id}}" {
+		t.Errorf("unexpected variables: %v", op.Variables)
+	}
+}
@@ -0,0 +1,229 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/grpcclient"
+)
+
+// greeterFile test.greeter.Greeter/SayHello(HelloRequest{name, times}) returns (HelloReply{message, tags})
+var greeterFile = &descriptorpb.FileDescriptorProto{
+	Name:    proto.String("greeter.proto"),
+	Package: proto.String("test.greeter"),
+	Syntax:  proto.String("proto3"),
+	MessageType: []*descriptorpb.DescriptorProto{
+		{
+			Name: proto.String("HelloRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("times"), JsonName: proto.String("times"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+			},
+		},
+		{
+			Name: proto.String("HelloReply"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("message"), JsonName: proto.String("message"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("tags"), JsonName: proto.String("tags"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+			},
+		},
+	},
+	Service: []*descriptorpb.ServiceDescriptorProto{{
+		Name: proto.String("Greeter"),
+		Method: []*descriptorpb.MethodDescriptorProto{{
+			Name:       proto.String("SayHello"),
+			InputType:  proto.String(".test.greeter.HelloRequest"),
+			OutputType: proto.String(".test.greeter.HelloReply"),
+		}},
+	}},
+}
+
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(message)))
+	copy(frame[5:], message)
+	return frame
+}
+
+// grpcServer 明文 HTTP/2 的 gRPC 服务端, 实现 Greeter 与 v1 反射服务(reflection=false 时返回 UNIMPLEMENTED)
+func grpcServer(t *testing.T, reflection bool) *httptest.Server {
+	file, err := protodesc.NewFile(greeterFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileBytes, _ := proto.Marshal(greeterFile)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		message := body[5:]
+		w.Header().Set("Content-Type", "application/grpc")
+		finish := func(code int, msg string) {
+			w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+			w.Header().Set(http.TrailerPrefix+"Grpc-Message", msg)
+		}
+
+		switch r.URL.Path {
+		case "/test.greeter.Greeter/SayHello":
+			request := dynamicpb.NewMessage(file.Messages().ByName("HelloRequest"))
+			proto.Unmarshal(message, request)
+			name := request.Get(request.Descriptor().Fields().ByName("name")).String()
+			if name == "nobody" {
+				finish(5, "user%20not%20found")
+				return
+			}
+			reply := dynamicpb.NewMessage(file.Messages().ByName("HelloReply"))
+			greeting := strings.Repeat("hello "+name+" ", int(request.Get(request.Descriptor().Fields().ByName("times")).Int()))
+			reply.Set(reply.Descriptor().Fields().ByName("message"), protoreflect.ValueOfString(strings.TrimSpace(greeting)))
+			w.Header().Set("X-Server", "test")
+			data, _ := proto.Marshal(reply)
+			w.Write(grpcFrame(data))
+			finish(0, "")
+		case "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":
+			if !reflection {
+				finish(12, "")
+				return
+			}
+			num, _, n := protowire.ConsumeTag(message)
+			var response, inner []byte
+			switch num {
+			case 7: // list_services
+				var service []byte
+				service = protowire.AppendTag(service, 1, protowire.BytesType)
+				service = protowire.AppendString(service, "test.greeter.Greeter")
+				inner = protowire.AppendTag(inner, 1, protowire.BytesType)
+				inner = protowire.AppendBytes(inner, service)
+				response = protowire.AppendTag(response, 6, protowire.BytesType)
+			default: // file_containing_symbol
+				symbol, _ := protowire.ConsumeString(message[n:])
+				if symbol != "test.greeter.Greeter" {
+					inner = protowire.AppendTag(inner, 2, protowire.BytesType)
+					inner = protowire.AppendString(inner, "symbol not found")
+					response = protowire.AppendTag(response, 7, protowire.BytesType)
+					break
+				}
+				inner = protowire.AppendTag(inner, 1, protowire.BytesType)
+				inner = protowire.AppendBytes(inner, fileBytes)
+				response = protowire.AppendTag(response, 4, protowire.BytesType)
+			}
+			response = protowire.AppendBytes(response, inner)
+			w.Write(grpcFrame(response))
+			finish(0, "")
+		default:
+			finish(12, "unknown method")
+		}
+	})
+	return httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+}
+
+func executeGrpcNode(t *testing.T, config core.ItemConfig, inputs map[string]interface{}) *core.ExecuteResult {
+	t.Helper()
+	node := &engine_nodes.Node{NodeKey: "grpc", NodeType: "grpc", Config: config}
+	executor := engine_nodes.NewGrpcNodeExecutor()
+	if err := executor.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	return executor.Execute(context.Background(), node, inputs)
+}
+
+func TestGrpcNodeReflection(t *testing.T) {
+	server := grpcServer(t, true)
+	defer server.Close()
+	config := core.ItemConfig{
+		"target":  strings.TrimPrefix(server.URL, "http://"),
+		"service": "test.greeter.Greeter",
+		"method":  "SayHello",
+		"message": map[string]interface{}{"name": "{{.name}}", "times": "{{.times}}"},
+	}
+
+	result := executeGrpcNode(t, config, map[string]interface{}{"name": "张三", "times": 2.0})
+	if result.Status != core.ExecuteStatusSuccess {
+		t.Fatalf("execution failed: %+v", result)
+	}
+	response := result.Data["response"].(map[string]interface{})
+	if response["message"] != "hello 张三 hello 张三" {
+		t.Errorf("unexpected response: %v", response)
+	}
+	if tags, ok := response["tags"].([]interface{}); !ok || len(tags) != 0 {
+		t.Errorf("unset fields should be emitted: %v", response)
+	}
+	if result.Data["status"] != 0 || result.Data["statusName"] != "OK" {
+		t.Errorf("unexpected status: %v", result.Data)
+	}
+	if result.Data["headers"].(map[string]interface{})["x-server"] != "test" {
+		t.Errorf("unexpected headers: %v", result.Data["headers"])
+	}
+	if result.Data["trailers"].(map[string]interface{})["grpc-status"] != "0" {
+		t.Errorf("unexpected trailers: %v", result.Data["trailers"])
+	}
+
+	result = executeGrpcNode(t, config, map[string]interface{}{"name": "nobody", "times": 1.0})
+	if result.Status != core.ExecuteStatusError || result.Data["statusName"] != "NOT_FOUND" || result.Data["statusMessage"] != "user not found" {
+		t.Errorf("non-OK status should fail: %+v", result)
+	}
+}
+
+func TestGrpcNodeDescriptorSet(t *testing.T) {
+	server := grpcServer(t, false)
+	defer server.Close()
+	target := strings.TrimPrefix(server.URL, "http://")
+	set, _ := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{greeterFile}})
+
+	result := executeGrpcNode(t, core.ItemConfig{
+		"target":        target,
+		"service":       "test.greeter.Greeter",
+		"method":        "SayHello",
+		"message":       `{"name": "李四", "times": 1}`,
+		"descriptorSet": base64.StdEncoding.EncodeToString(set),
+	}, nil)
+	if result.Status != core.ExecuteStatusSuccess || result.Data["response"].(map[string]interface{})["message"] != "hello 李四" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result = executeGrpcNode(t, core.ItemConfig{"target": target, "service": "test.greeter.Greeter", "method": "SayHello"}, nil)
+	if result.Status != core.ExecuteStatusError || !strings.Contains(result.Error, "反射") {
+		t.Errorf("reflection should be reported as unavailable: %+v", result)
+	}
+}
+
+func TestGrpcServices(t *testing.T) {
+	server := grpcServer(t, true)
+	defer server.Close()
+	executor := engine_nodes.NewGrpcNodeExecutor()
+
+	set, _ := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{greeterFile}})
+	for _, descriptorSet := range [][]byte{nil, set} {
+		services, err := executor.Services(grpcclient.Target{Address: strings.TrimPrefix(server.URL, "http://")}, descriptorSet, 5*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(services) != 1 || len(services[0].Methods) != 1 {
+			t.Fatalf("unexpected services: %+v", services)
+		}
+		method := services[0].Methods[0]
+		if method.FullMethod != "test.greeter.Greeter/SayHello" || method.Input != "test.greeter.HelloRequest" {
+			t.Errorf("unexpected method: %+v", method)
+		}
+		if example := method.Example.(map[string]interface{}); example["name"] != "" || example["times"] != 0 {
+			t.Errorf("unexpected example: %v", example)
+		}
+	}
+}
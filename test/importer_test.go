@@ -0,0 +1,106 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"api-flow/engine/engine_nodes"
+	"api-flow/importer"
+)
+
+func TestParseCurl(t *testing.T) {
+	command := `curl 'https://api.example.com/users?page=2' \
+  -H 'Authorization: Bearer abc' \
+  -H "Content-Type: application/json" \
+  --data-raw $'{"name":"it\'s me"}' \
+  --compressed`
+	request, err := importer.ParseCurl(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Method != "POST" {
+		t.Errorf("method should default to POST with data, got %s", request.Method)
+	}
+	if request.URL != "https://api.example.com/users" || request.Params.Get("page") != "2" {
+		t.Errorf("query should be split into params: %s %v", request.URL, request.Params)
+	}
+	if request.Header("authorization") != "Bearer abc" {
+		t.Errorf("unexpected headers: %v", request.Headers)
+	}
+	if request.Body != `{"name":"it's me"}` {
+		t.Errorf("unexpected body: %s", request.Body)
+	}
+}
+
+func TestParseCurlGetAndForm(t *testing.T) {
+	request, err := importer.ParseCurl(`curl -G https://example.com/search -d q=go -u user:pass`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Method != "GET" || request.URL != "https://example.com/search" || request.Params.Get("q") != "go" || request.Body != "" {
+		t.Errorf("unexpected -G request: %+v", request)
+	}
+	if !strings.HasPrefix(request.Header("Authorization"), "Basic ") {
+		t.Errorf("basic auth header missing: %v", request.Headers)
+	}
+
+	request, err = importer.ParseCurl(`curl -F name=demo -F tag=a -F tag=b -F file=@photo.png -H 'Content-Type: multipart/form-data; boundary=x' https://example.com/upload`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := request.NodeConfig()
+	form, _ := config["body"].(map[string]interface{})
+	if request.Method != "POST" || config["bodyType"] != engine_nodes.BodyTypeMultipart || form["name"] != "demo" {
+		t.Errorf("form fields should become a multipart body: %v", config)
+	}
+	if tags, _ := form["tag"].([]interface{}); len(tags) != 2 {
+		t.Errorf("repeated fields should become an array: %v", form["tag"])
+	}
+	if _, ok := form["file"]; ok || len(request.Warnings) != 1 || !strings.Contains(request.Warnings[0], "photo.png") {
+		t.Errorf("file fields should be skipped with a warning: %v %v", form, request.Warnings)
+	}
+	if request.Header("Content-Type") != "" {
+		t.Errorf("multipart content type should be generated at execution: %v", request.Headers)
+	}
+
+	request, err = importer.ParseCurl(`curl 'https://example.com/items?id=1&id=2&q=a%20b'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, _ := request.NodeConfig()["params"].(map[string]interface{})
+	if request.URL != "https://example.com/items" || params["q"] != "a b" {
+		t.Errorf("unexpected params: %s %v", request.URL, params)
+	}
+	if ids, _ := params["id"].([]interface{}); len(ids) != 2 {
+		t.Errorf("repeated query params should become an array: %v", params["id"])
+	}
+
+	if _, err := importer.ParseCurl(`curl -X OPTIONS https://example.com`); err == nil {
+		t.Error("expected unsupported method error")
+	}
+}
+
+func TestParseHAR(t *testing.T) {
+	har := `{"log":{"entries":[
+		{"request":{"method":"OPTIONS","url":"https://example.com/api/a","headers":[]}},
+		{"request":{"method":"GET","url":"https://example.com/api/a?page=1&q=a%20b","headers":[{"name":":authority","value":"example.com"},{"name":"Accept","value":"application/json"}],"queryString":[{"name":"page","value":"1"},{"name":"q","value":"a b"}]}},
+		{"request":{"method":"POST","url":"https://example.com/api/b","headers":[],"postData":{"mimeType":"application/x-www-form-urlencoded","params":[{"name":"a","value":"1"}]}}},
+		{"request":{"method":"GET","url":"https://cdn.example.com/app.js","headers":[]}}
+	]}}`
+	requests, skipped, err := importer.ParseHAR([]byte(har), "/api/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || len(skipped) != 1 {
+		t.Fatalf("expected 2 requests and 1 skipped, got %d and %d", len(requests), len(skipped))
+	}
+	if _, ok := requests[0].Headers[":authority"]; ok {
+		t.Error("pseudo headers should be dropped")
+	}
+	if requests[0].URL != "https://example.com/api/a" || requests[0].Params.Get("page") != "1" || requests[0].Params.Get("q") != "a b" {
+		t.Errorf("queryString should be split into params: %s %v", requests[0].URL, requests[0].Params)
+	}
+	if requests[1].Body != "a=1" || requests[1].Header("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected form request: %+v", requests[1])
+	}
+}
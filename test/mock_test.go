@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/mock"
+)
+
+func mockRoutes(t *testing.T) *mock.Server {
+	var routes []mock.Route
+	err := json.Unmarshal([]byte(`[
+		{"method": "GET", "path": "/users/:id", "responses": [
+			{"status": 200, "body": {"id": "{{.params.id}}", "name": "user-{{.params.id}}", "page": "{{.query.page}}"}}
+		]},
+		{"method": "GET", "path": "/users/me", "responses": [{"body": {"id": "me"}}]},
+		{"method": "GET", "path": "/jobs/{id}", "responses": [
+			{"body": {"status": "running"}},
+			{"body": {"status": "done", "call": "{{.count}}"}, "headers": {"X-Job": "{{.params.id}}"}}
+		]},
+		{"method": "POST", "path": "/login", "mode": "conditional", "responses": [
+			{"when": [{"in": "body", "name": "$.password", "equals": "secret"}], "body": {"token": "t-{{.body.user}}", "echo": "{{.body}}"}},
+			{"when": [{"in": "header", "name": "x-debug", "exists": true}], "status": 418, "body": "debug"},
+			{"status": 401, "body": {"error": "invalid"}}
+		]},
+		{"path": "/files/*rest", "responses": [{"body": "{{.method}} {{.params.rest}}"}]}
+	]`), &routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := mock.NewServer()
+	if err := server.SetRoutes(routes); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestMockServer(t *testing.T) {
+	server := mockRoutes(t)
+	do := func(method, path, body string, header map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		var data map[string]interface{}
+		json.Unmarshal(recorder.Body.Bytes(), &data)
+		return recorder, data
+	}
+
+	if _, data := do("GET", "/users/7?page=2", "", nil); data["id"] != "7" || data["name"] != "user-7" || data["page"] != "2" {
+		t.Errorf("unexpected path params response: %v", data)
+	}
+	if _, data := do("GET", "/users/me", "", nil); data["id"] != "me" {
+		t.Errorf("static route should win over params: %v", data)
+	}
+
+	// 依次返回, 用完后重复最后一个
+	statuses := []string{}
+	for i := 0; i < 3; i++ {
+		recorder, data := do("GET", "/jobs/9", "", nil)
+		statuses = append(statuses, data["status"].(string))
+		if i == 2 && (recorder.Header().Get("X-Job") != "9" || data["call"] != 3.0) {
+			t.Errorf("unexpected last response: %v %v", recorder.Header(), data)
+		}
+	}
+	if strings.Join(statuses, ",") != "running,done,done" {
+		t.Errorf("unexpected sequence: %v", statuses)
+	}
+	server.Reset()
+	if _, data := do("GET", "/jobs/9", "", nil); data["status"] != "running" {
+		t.Errorf("reset should restart the sequence: %v", data)
+	}
+
+	// 条件响应, 只引用一个字段时保留原始类型
+	if recorder, data := do("POST", "/login", `{"user": "bob", "password": "secret"}`, nil); recorder.Code != 200 || data["token"] != "t-bob" || data["echo"].(map[string]interface{})["user"] != "bob" {
+		t.Errorf("unexpected login response: %d %v", recorder.Code, data)
+	}
+	if recorder, _ := do("POST", "/login", `{"password": "wrong"}`, map[string]string{"X-Debug": "1"}); recorder.Code != http.StatusTeapot || recorder.Body.String() != "debug" {
+		t.Errorf("unexpected debug response: %d %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder, _ := do("POST", "/login", `{"password": "wrong"}`, nil); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected fallback status: %d", recorder.Code)
+	}
+
+	if recorder, _ := do("DELETE", "/files/a/b.txt", "", nil); recorder.Body.String() != "DELETE a/b.txt" {
+		t.Errorf("unexpected wildcard response: %s", recorder.Body.String())
+	}
+	if recorder, _ := do("DELETE", "/users/7", "", nil); recorder.Code != http.StatusNotFound {
+		t.Errorf("method mismatch should be 404: %d", recorder.Code)
+	}
+}
+
+func TestMockRouteValidate(t *testing.T) {
+	invalid := []mock.Route{
+		{Path: "users", Responses: mock.Responses{{}}},
+		{Path: "/users", Method: "FETCH", Responses: mock.Responses{{}}},
+		{Path: "/users"},
+		{Path: "/users/*rest/x", Responses: mock.Responses{{}}},
+		{Path: "/users", Mode: "random", Responses: mock.Responses{{}}},
+		{Path: "/users", Responses: mock.Responses{{When: []mock.Condition{{In: "cookie", Name: "a"}}}}},
+	}
+	for _, route := range invalid {
+		if err := route.Validate(); err == nil {
+			t.Errorf("route should be invalid: %+v", route)
+		}
+	}
+}
+
+func TestRunnerWithMocks(t *testing.T) {
+	server := mockRoutes(t)
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    "https://auth.upstream.invalid/login",
+			"method": "POST",
+			"body":   map[string]interface{}{"user": "{{.user}}", "password": "secret"},
+		}},
+		{NodeKey: "profile", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":     "https://api.upstream.invalid/users/{{.id}}",
+			"method":  "GET",
+			"headers": map[string]interface{}{"Authorization": "Bearer {{.token}}"},
+			"inputs":  map[string]interface{}{"token": "${login.response.token}"},
+		}},
+	}
+	edges := []engine.Edge{engine.NewEdge("login", "profile")}
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+
+	results, err := runner.RunContext(server.Redirect(context.Background()), nodes, edges, map[string]interface{}{"user": "bob", "id": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Status != core.ExecuteStatusSuccess {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if response := results[1].Data["response"].(map[string]interface{}); response["name"] != "user-42" {
+		t.Errorf("unexpected profile response: %v", response)
+	}
+
+	// 不使用模拟接口时请求发往真实地址
+	results, _ = runner.Run(nodes[:1], nil, map[string]interface{}{"user": "bob"})
+	if len(results) != 1 || results[0].Status != core.ExecuteStatusError {
+		t.Errorf("request without mocks should fail: %+v", results)
+	}
+}
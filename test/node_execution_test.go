@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+func TestExecutionLog(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "Bob"}`))
+	}))
+	defer server.Close()
+
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":           server.URL + "/login/{{.user}}",
+			"method":        "GET",
+			"retry":         2.0,
+			"retryInterval": 0.0,
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": "${login.response.name}"},
+		}},
+	}
+	edges := []engine.Edge{engine.NewEdge("login", "greet")}
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+
+	executionLog := engine.NewExecutionLog()
+	ctx := engine.WithExecutionLog(context.Background(), executionLog)
+	if _, err := runner.RunContext(ctx, nodes, edges, map[string]interface{}{"user": "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	executions := executionLog.Executions()
+	if len(executions) != 2 || executions[0].NodeKey != "login" || executions[1].NodeKey != "greet" {
+		t.Fatalf("unexpected executions: %+v", executions)
+	}
+	login, greet := executions[0], executions[1]
+	if login.Status != core.ExecuteStatusSuccess || login.Attempt != 2 || login.NodeType != engine_nodes.ApiNodeType.Code {
+		t.Errorf("unexpected login execution: %+v", login)
+	}
+	if login.StartTime.IsZero() || login.EndTime.Before(login.StartTime) || login.Inputs["user"] != "bob" {
+		t.Errorf("unexpected login timing or inputs: %+v", login)
+	}
+	if greet.Attempt != 1 || greet.Inputs["content"] != "Bob" || greet.Output["output"] != "Bob" {
+		t.Errorf("unexpected greet execution: %+v", greet)
+	}
+	if result := greet.Result(); result.NodeKey != "greet" || result.Data["output"] != "Bob" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// 使用原实例结果的节点记为复用, 不计请求次数
+	executionLog = engine.NewExecutionLog()
+	ctx = engine.WithExecutionLog(context.Background(), executionLog)
+	ctx = engine.WithStubResults(ctx, map[string]*core.ExecuteResult{
+		"login": {Status: core.ExecuteStatusSuccess, Data: core.ExecuteOutput{"response": map[string]interface{}{"name": "Alice"}}},
+	})
+	if _, err := runner.RunContext(ctx, nodes, edges, nil); err != nil {
+		t.Fatal(err)
+	}
+	executions = executionLog.Executions()
+	if len(executions) != 2 || !executions[0].Reused || executions[0].Attempt != 0 || executions[1].Reused {
+		t.Fatalf("unexpected executions: %+v", executions)
+	}
+	if executions[1].Output["output"] != "Alice" {
+		t.Errorf("unexpected greet output: %+v", executions[1].Output)
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"testing"
+
+	"api-flow/services"
+)
+
+const petstoreV3 = `
+openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://petstore.example.com/v1
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      operationId: showPetById
+      summary: Info for a specific pet
+      parameters:
+        - name: X-Request-Id
+          in: header
+          schema:
+            type: string
+        - name: verbose
+          in: query
+          schema:
+            type: boolean
+      responses:
+        "200":
+          description: Expected response to a valid request
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+          example: doggie
+        status:
+          type: string
+          enum: [available, sold]
+`
+
+func TestOpenAPIImportNodes(t *testing.T) {
+	importService := services.NewImportService()
+	_, operations, err := importService.OpenAPIOperations([]byte(petstoreV3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operations) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(operations))
+	}
+
+	nodes, err := importService.OpenAPINodes([]byte(petstoreV3), []string{"showPetById", "createPet"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	show := nodes[0].Config
+	if show["url"] != "https://petstore.example.com/v1/pets/{{.petId}}" {
+		t.Errorf("unexpected url: %v", show["url"])
+	}
+	headers := show["headers"].(map[string]interface{})
+	if headers["X-Request-Id"] != `{{index . "X-Request-Id"}}` {
+		t.Errorf("unexpected header template: %v", headers["X-Request-Id"])
+	}
+	if params := show["params"].(map[string]interface{}); params["verbose"] != "{{.verbose}}" {
+		t.Errorf("unexpected query params: %v", params)
+	}
+
+	create := nodes[1].Config
+	if create["method"] != "POST" || create["body"] == "" {
+		t.Errorf("unexpected create config: %v", create)
+	}
+
+	if _, err := importService.OpenAPINodes([]byte(petstoreV3), []string{"missing"}, ""); err == nil {
+		t.Error("expected error for unknown operation")
+	}
+}
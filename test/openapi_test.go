@@ -0,0 +1,109 @@
+package test
+
+import (
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/openapi"
+	"api-flow/services"
+)
+
+// 导入节点的输出字段形如 response.id, 应按第一个 "." 拆出节点键, 其余部分生成嵌套对象
+func TestOutputsSchemaDottedFields(t *testing.T) {
+	outputs := core.ParamFormat{
+		core.NewParamDefination("login.response.id", core.DataTypeString, "用户ID"),
+		core.NewParamDefination("login.response.profile.age", core.DataTypeNumber, "年龄"),
+		core.NewParamDefination("login.status", core.DataTypeNumber, "状态码"),
+		core.NewParamDefination("greet.output", core.DataTypeString, "问候语"),
+	}
+	schema, example := openapi.OutputsSchema(outputs)
+
+	if len(schema.Properties) != 2 || schema.Properties["login"] == nil || schema.Properties["greet"] == nil {
+		t.Fatalf("outputs should be grouped by node key: %v", schema.Properties)
+	}
+	login := schema.Properties["login"]
+	if login.Properties["status"] == nil || login.Properties["status"].Type != "number" {
+		t.Errorf("unexpected login schema: %+v", login.Properties)
+	}
+	response := login.Properties["response"]
+	if response == nil || response.Type != "object" || response.Properties["id"] == nil || response.Properties["id"].Type != "string" {
+		t.Fatalf("dotted fields should become nested objects: %+v", login.Properties)
+	}
+	if profile := response.Properties["profile"]; profile == nil || profile.Properties["age"] == nil {
+		t.Errorf("unexpected nested schema: %+v", response.Properties)
+	}
+
+	loginExample, _ := example["login"].(map[string]interface{})
+	responseExample, _ := loginExample["response"].(map[string]interface{})
+	if responseExample["id"] != "string" || loginExample["status"] != 0 {
+		t.Errorf("unexpected example: %v", example)
+	}
+	if _, ok := example["login.response"]; ok {
+		t.Errorf("node key should not contain dots: %v", example)
+	}
+}
+
+func TestBuildOpenAPI(t *testing.T) {
+	workflow := engine.Workflow{Name: "问候", Description: "根据用户名生成问候语", Status: engine.WorkflowPublished}
+	workflow.ID = 3
+	nodes := []engine_nodes.Node{
+		{NodeKey: "input", NodeType: engine_nodes.InputNodeType.Code, Config: core.ItemConfig{
+			"params": []interface{}{
+				map[string]interface{}{"field": "user", "type": "string", "desc": "用户名", "default": "bob"},
+				map[string]interface{}{"field": "age", "type": "number"},
+			},
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code},
+	}
+	edges := []engine.Edge{{SourceNodeKey: "input", TargetNodeKey: "greet"}}
+
+	doc := services.BuildOpenAPI("http://localhost:8080", []services.PublishedWorkflow{{Workflow: workflow, Nodes: nodes, Edges: edges}})
+	if doc.OpenAPI != openapi.Version || len(doc.Servers) != 1 || doc.Servers[0].URL != "http://localhost:8080" {
+		t.Errorf("unexpected document header: %+v %+v", doc.OpenAPI, doc.Servers)
+	}
+	for _, name := range []string{"ExecuteResult", "WorkflowExecutionResult", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("component schema %s missing", name)
+		}
+	}
+
+	item := doc.Paths["/api/workflows/3/execute"]
+	if item == nil || item.Post == nil {
+		t.Fatalf("execute path missing: %v", doc.Paths)
+	}
+	operation := item.Post
+	if operation.OperationID != "executeWorkflow3" || operation.Summary != "问候" || operation.Description != "根据用户名生成问候语" {
+		t.Errorf("unexpected operation: %+v", operation)
+	}
+
+	body := operation.RequestBody.Content["application/json"]
+	if !operation.RequestBody.Required || body.Schema.Properties["user"] == nil || body.Schema.Properties["user"].Type != "string" ||
+		body.Schema.Properties["age"] == nil || body.Schema.Properties["age"].Type != "number" {
+		t.Errorf("request body should come from execInput params: %+v", body.Schema.Properties)
+	}
+	if example, _ := body.Example.(map[string]interface{}); example["user"] != "bob" || example["age"] != 0 {
+		t.Errorf("unexpected request example: %v", body.Example)
+	}
+
+	response := operation.Responses["200"].Content["application/json"]
+	if len(response.Schema.AllOf) != 2 || response.Schema.AllOf[0].Ref != "#/components/schemas/WorkflowExecutionResult" {
+		t.Fatalf("response should extend WorkflowExecutionResult: %+v", response.Schema)
+	}
+	outputs := response.Schema.AllOf[1].Properties["outputs"]
+	if outputs == nil || outputs.Properties["greet"] == nil || outputs.Properties["greet"].Properties["output"] == nil {
+		t.Errorf("outputs should describe the terminal node: %+v", outputs)
+	}
+	if _, ok := outputs.Properties["input"]; ok {
+		t.Error("input node should not be a workflow output")
+	}
+	example, _ := response.Example.(map[string]interface{})
+	greet, _ := example["outputs"].(map[string]interface{})["greet"].(map[string]interface{})
+	if example["workflowId"] != uint(3) || greet == nil {
+		t.Errorf("unexpected response example: %v", response.Example)
+	}
+	if operation.Responses["400"].Ref != "#/components/responses/Error" {
+		t.Errorf("error responses should reference the shared component: %+v", operation.Responses["400"])
+	}
+}
@@ -0,0 +1,212 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"api-flow/engine/outbound"
+
+	"gopkg.in/yaml.v2"
+)
+
+// outboundConfig 按配置文件中 outbound 段的格式解析配置
+func outboundConfig(t *testing.T, text string) outbound.Config {
+	var config outbound.Config
+	if err := yaml.Unmarshal([]byte(text), &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestOutboundCircuitBreaker(t *testing.T) {
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	manager := outbound.NewManager(outboundConfig(t, `
+defaults:
+  failureThreshold: 2
+  cooldown: 1
+`))
+	client := &http.Client{Transport: &outbound.Transport{Manager: manager}}
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	get()
+	get()
+	var openErr *outbound.CircuitOpenError
+	if err := get(); !errors.As(err, &openErr) {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	if breakers := manager.Breakers(); len(breakers) != 1 || breakers[0].State != outbound.BreakerOpen || breakers[0].Trips != 1 {
+		t.Fatalf("unexpected breaker status: %+v", breakers)
+	}
+
+	// 冷却后半开, 探测成功则关闭
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(1100 * time.Millisecond)
+	if err := get(); err != nil {
+		t.Fatalf("half-open probe should pass: %v", err)
+	}
+	if state := manager.Breakers()[0].State; state != outbound.BreakerClosed {
+		t.Errorf("breaker should be closed after probe, got %s", state)
+	}
+}
+
+func TestOutboundRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	manager := outbound.NewManager(outboundConfig(t, `
+hosts:
+  "127.0.0.1":
+    rateLimit: 20
+    burst: 1
+`))
+	client := &http.Client{Transport: &outbound.Transport{Manager: manager}}
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// 5 个请求, 令牌桶容量 1, 每秒 20 个, 至少需要 200ms
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("requests were not throttled: %s", elapsed)
+	}
+}
+
+func TestOutboundHostOverrideDisables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	// 主机配置显式设置为 0 时关闭默认配置启用的熔断与限流
+	manager := outbound.NewManager(outboundConfig(t, `
+defaults:
+  failureThreshold: 1
+  rateLimit: 1
+hosts:
+  "127.0.0.1":
+    failureThreshold: 0
+    rateLimit: 0
+`))
+	client := &http.Client{Transport: &outbound.Transport{Manager: manager}}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request %d should not be blocked: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("requests should not be throttled: %s", elapsed)
+	}
+	if breakers := manager.Breakers(); len(breakers) != 0 {
+		t.Errorf("breaker should be disabled for the host: %+v", breakers)
+	}
+}
+
+func TestOutboundPolicy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/redirect", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	localhostURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	request := func(policy outbound.Policy, target string) error {
+		manager := outbound.NewManager(outbound.Config{Policy: policy})
+		client := &http.Client{Transport: &outbound.Transport{Manager: manager}, CheckRedirect: manager.CheckRedirect}
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	isPolicyError := func(err error) bool {
+		var policyErr *outbound.PolicyError
+		return errors.As(err, &policyErr)
+	}
+
+	// 主机名解析到回环地址, 应在连接时被拒绝
+	if err := request(outbound.Policy{BlockPrivate: true}, localhostURL+"/ok"); !isPolicyError(err) {
+		t.Errorf("loopback should be blocked after DNS resolution, got %v", err)
+	}
+	if err := request(outbound.Policy{BlockPrivate: true, AllowHosts: []string{"127.0.0.0/8"}}, localhostURL+"/ok"); err != nil {
+		t.Errorf("CIDR allow rule should permit loopback: %v", err)
+	}
+	if err := request(outbound.Policy{DenyHosts: []string{"local*"}}, localhostURL+"/ok"); !isPolicyError(err) {
+		t.Errorf("denied host should be blocked, got %v", err)
+	}
+	if err := request(outbound.Policy{AllowSchemes: []string{"https"}}, server.URL+"/ok"); !isPolicyError(err) {
+		t.Errorf("http scheme should be blocked, got %v", err)
+	}
+	if err := request(outbound.Policy{AllowPorts: []int{443}}, server.URL+"/ok"); !isPolicyError(err) {
+		t.Errorf("port should be blocked, got %v", err)
+	}
+	maxRedirects := 2
+	if err := request(outbound.Policy{MaxRedirects: &maxRedirects}, server.URL+"/redirect"); !isPolicyError(err) {
+		t.Errorf("redirect loop should be stopped, got %v", err)
+	}
+}
+
+// 设置了 HTTP_PROXY 时请求也不经过代理, 否则连接时检查的是代理的 IP, 内网目标可以绕过 BlockPrivate。
+// 代理环境变量在进程内只读取一次, 因此在子进程中执行检查
+func TestOutboundPolicyIgnoresProxy(t *testing.T) {
+	if os.Getenv("OUTBOUND_PROXY_TEST") == "1" {
+		manager := outbound.NewManager(outbound.Config{Policy: outbound.Policy{
+			BlockPrivate: true,
+			// 放行回环地址上的代理, 只有直接连接目标时请求才会被拒绝
+			AllowHosts: []string{"127.0.0.0/8"},
+		}})
+		client := &http.Client{Transport: &outbound.Transport{Manager: manager}, CheckRedirect: manager.CheckRedirect}
+		resp, err := client.Get("http://169.254.169.254/latest/meta-data/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		var policyErr *outbound.PolicyError
+		if !errors.As(err, &policyErr) {
+			t.Fatalf("metadata address should be blocked with a proxy configured, got %v", err)
+		}
+		return
+	}
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		w.Write([]byte("metadata"))
+	}))
+	defer proxy.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestOutboundPolicyIgnoresProxy$")
+	cmd.Env = append(os.Environ(), "OUTBOUND_PROXY_TEST=1", "HTTP_PROXY="+proxy.URL, "http_proxy="+proxy.URL)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+	if n := atomic.LoadInt32(&proxied); n != 0 {
+		t.Errorf("request should not go through the proxy, proxied %d", n)
+	}
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+func TestPartialExecution(t *testing.T) {
+	text := func(key, content string) engine_nodes.Node {
+		return engine_nodes.Node{NodeKey: key, NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": content},
+		}}
+	}
+	// fetch -> transform -> report, audit 与 transform 无关
+	nodes := []engine_nodes.Node{
+		text("fetch", "live"),
+		text("transform", "${fetch.output}"),
+		text("report", "${transform.output}"),
+		text("audit", "audit"),
+	}
+	edges := []engine.Edge{engine.NewEdge("fetch", "transform"), engine.NewEdge("transform", "report")}
+	previous := []core.ExecuteResult{
+		{NodeKey: "fetch", Status: core.ExecuteStatusSuccess, Data: core.ExecuteOutput{"output": "cached"}},
+		{NodeKey: "transform", Status: core.ExecuteStatusSuccess, Data: core.ExecuteOutput{"output": "old"}},
+	}
+
+	stubs, err := engine.ReuseResults(nodes, edges, "transform", previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stubs["transform"]; ok || stubs["fetch"] == nil || stubs["audit"].Status != core.ExecuteStatusReady {
+		t.Fatalf("unexpected stubs: %+v", stubs)
+	}
+
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	results, err := runner.RunContext(engine.WithStubResults(context.Background(), stubs), nodes, edges, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := make(map[string]interface{})
+	for _, result := range results {
+		outputs[result.NodeKey] = result.Data["output"]
+	}
+	if outputs["fetch"] != "cached" || outputs["transform"] != "cached" || outputs["report"] != "cached" || outputs["audit"] != nil {
+		t.Errorf("unexpected outputs: %v", outputs)
+	}
+
+	if _, err := engine.ReuseResults(nodes, edges, "report", previous[1:]); err == nil || !strings.Contains(err.Error(), "fetch") {
+		t.Errorf("missing upstream result should fail: %v", err)
+	}
+	if _, err := engine.ReuseResults(nodes, edges, "missing", previous); err == nil {
+		t.Error("unknown start node should fail")
+	}
+}
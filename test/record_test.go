@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+	"api-flow/engine/outbound"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login":
+			fmt.Fprintf(w, `{"token":"t-%d"}`, calls)
+		default:
+			fmt.Fprintf(w, `{"name":"user-%s","call":%d}`, r.URL.Query().Get("id"), calls)
+		}
+	}))
+
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":     server.URL + "/login",
+			"method":  "POST",
+			"headers": map[string]interface{}{"Authorization": "Bearer secret"},
+			"body":    map[string]interface{}{"user": "{{.user}}"},
+		}},
+		{NodeKey: "profile", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    server.URL + "/profile?id={{.user}}",
+			"method": "GET",
+		}},
+	}
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+	inputs := map[string]interface{}{"user": "bob"}
+
+	recorder := outbound.NewRecorder()
+	recorded, err := runner.RunContext(outbound.WithRecorder(context.Background(), recorder), nodes, nil, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	exchanges := recorder.Exchanges()
+	if len(exchanges) != 2 {
+		t.Fatalf("expected 2 exchanges, got %+v", exchanges)
+	}
+	for _, exchange := range exchanges {
+		if exchange.Status != http.StatusOK || exchange.ResponseBody == "" {
+			t.Errorf("incomplete exchange: %+v", exchange)
+		}
+		if exchange.Method == "POST" {
+			if exchange.RequestHeaders.Get("Authorization") != "***" || !strings.Contains(exchange.RequestBody, "bob") {
+				t.Errorf("unexpected recorded request: %+v", exchange)
+			}
+		}
+	}
+
+	// 服务已关闭, 回放得到与录制时相同的结果
+	replayed, err := runner.RunContext(outbound.WithPlayer(context.Background(), outbound.NewPlayer(exchanges)), nodes, nil, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range replayed {
+		if result.Status != core.ExecuteStatusSuccess {
+			t.Fatalf("replay failed: %+v", result)
+		}
+		for _, original := range recorded {
+			if original.NodeKey == result.NodeKey && !reflect.DeepEqual(original.Data["response"], result.Data["response"]) {
+				t.Errorf("%s: replayed %v, recorded %v", result.NodeKey, result.Data["response"], original.Data["response"])
+			}
+		}
+	}
+
+	// 查询参数不同时按路径匹配; 录制用完后请求失败
+	inputs["user"] = "alice"
+	var profileOnly []outbound.Exchange
+	for _, exchange := range exchanges {
+		if exchange.Method == "GET" {
+			profileOnly = append(profileOnly, exchange)
+		}
+	}
+	player := outbound.NewPlayer(profileOnly)
+	replayed, _ = runner.RunContext(outbound.WithPlayer(context.Background(), player), nodes, nil, inputs)
+	for _, result := range replayed {
+		switch result.NodeKey {
+		case "profile":
+			if result.Status != core.ExecuteStatusSuccess {
+				t.Errorf("profile should match by path: %+v", result)
+			}
+		case "login":
+			if result.Status != core.ExecuteStatusError || !strings.Contains(result.Error, "没有匹配的录制请求") {
+				t.Errorf("login should miss: %+v", result)
+			}
+		}
+	}
+}
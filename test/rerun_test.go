@@ -0,0 +1,83 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"api-flow/dto"
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/definition"
+	"api-flow/engine/engine_nodes"
+	"api-flow/services"
+)
+
+func rerunInstance(t *testing.T, withDefinition bool) *engine.WorkflowInstance {
+	instance := &engine.WorkflowInstance{
+		WorkflowID: 7,
+		Inputs:     `{"user": "bob", "page": 1, "filter": {"active": true}}`,
+	}
+	instance.ID = 12
+	if withDefinition {
+		nodes := []engine_nodes.Node{
+			{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{"content": "hi"}},
+		}
+		def := definition.FromWorkflow(&engine.Workflow{Name: "问候"}, nodes, nil)
+		data, err := json.Marshal(def)
+		if err != nil {
+			t.Fatal(err)
+		}
+		instance.Definition = string(data)
+	}
+	return instance
+}
+
+func TestPrepareRerun(t *testing.T) {
+	instance := rerunInstance(t, true)
+	execution, def, err := services.PrepareRerun(instance, &dto.WorkflowRerunRequest{
+		Inputs: map[string]interface{}{"page": 2, "filter": map[string]interface{}{"role": "admin"}},
+		Mock:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def != nil {
+		t.Error("current version should not use the definition snapshot")
+	}
+	if execution.WorkflowID != 7 || execution.Async || !execution.Mock || execution.StartNode != "" {
+		t.Errorf("unexpected execution request: %+v", execution)
+	}
+	// 按顶层字段覆盖, 对象整体替换
+	filter, _ := execution.Inputs["filter"].(map[string]interface{})
+	if execution.Inputs["user"] != "bob" || execution.Inputs["page"] != 2 || filter["role"] != "admin" || filter["active"] != nil {
+		t.Errorf("inputs should be merged by top-level field: %v", execution.Inputs)
+	}
+
+	execution, def, err = services.PrepareRerun(instance, &dto.WorkflowRerunRequest{Version: services.RerunVersionOriginal, StartNode: "greet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def == nil || def.Name != "问候" || len(def.Nodes) != 1 || def.Nodes[0].Key != "greet" {
+		t.Errorf("original version should use the stored definition: %+v", def)
+	}
+	if execution.StartNode != "greet" || execution.FromInstance != 12 {
+		t.Errorf("partial rerun should reuse the original instance: %+v", execution)
+	}
+}
+
+func TestPrepareRerunErrors(t *testing.T) {
+	_, _, err := services.PrepareRerun(rerunInstance(t, false), &dto.WorkflowRerunRequest{Version: services.RerunVersionOriginal})
+	if !errors.Is(err, services.ErrNoDefinitionSnapshot) {
+		t.Errorf("instances without a snapshot should return ErrNoDefinitionSnapshot, got %v", err)
+	}
+	// 没有快照时仍可以使用当前版本
+	if _, _, err := services.PrepareRerun(rerunInstance(t, false), &dto.WorkflowRerunRequest{}); err != nil {
+		t.Errorf("current version should not need a snapshot: %v", err)
+	}
+
+	_, _, err = services.PrepareRerun(rerunInstance(t, true), &dto.WorkflowRerunRequest{Version: "latest"})
+	if !errors.Is(err, services.ErrInvalidRerun) {
+		t.Errorf("unknown version should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// wsServer 收到订阅消息 {"job": id} 后推送进度, 最后一条为 {"status": "done"}, 之后保持连接直到客户端关闭
+func wsServer(t *testing.T, idle bool) *httptest.Server {
+	writeFrame := func(w io.Writer, payload []byte) {
+		w.Write(append([]byte{0x81, byte(len(payload))}, payload...))
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+		rw.Flush()
+
+		// 读取客户端的订阅消息(带掩码)
+		reader := bufio.NewReader(conn)
+		head := make([]byte, 2)
+		io.ReadFull(reader, head)
+		length := int(head[1] & 0x7F)
+		if length == 126 {
+			ext := make([]byte, 2)
+			io.ReadFull(reader, ext)
+			length = int(binary.BigEndian.Uint16(ext))
+		}
+		mask := make([]byte, 4)
+		io.ReadFull(reader, mask)
+		payload := make([]byte, length)
+		io.ReadFull(reader, payload)
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+		var subscribe map[string]interface{}
+		json.Unmarshal(payload, &subscribe)
+
+		if idle {
+			writeFrame(conn, []byte("waiting"))
+		} else {
+			for _, progress := range []int{10, 50} {
+				writeFrame(conn, []byte(fmt.Sprintf(`{"job": %v, "progress": %d}`, subscribe["job"], progress)))
+			}
+			writeFrame(conn, []byte(fmt.Sprintf(`{"job": %v, "status": "done"}`, subscribe["job"])))
+		}
+		// 等待客户端关闭
+		io.Copy(io.Discard, reader)
+	}))
+}
+
+func executeStreamNode(t *testing.T, executor engine_nodes.NodeExecutor, config core.ItemConfig, inputs map[string]interface{}) *core.ExecuteResult {
+	t.Helper()
+	node := &engine_nodes.Node{NodeKey: "stream", Config: config}
+	if err := executor.ValidateConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	return executor.Execute(context.Background(), node, inputs)
+}
+
+func TestWebSocketNode(t *testing.T) {
+	server := wsServer(t, false)
+	defer server.Close()
+	executor := engine_nodes.NewWebSocketNodeExecutor()
+	config := core.ItemConfig{
+		"url":      "ws" + strings.TrimPrefix(server.URL, "http"),
+		"headers":  map[string]interface{}{"X-Token": "secret"},
+		"messages": []interface{}{map[string]interface{}{"job": "{{.job}}"}},
+		"until":    map[string]interface{}{"path": "$.status", "equals": "done"},
+		"timeout":  5,
+	}
+
+	result := executeStreamNode(t, executor, config, map[string]interface{}{"job": 7.0})
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopMatch || result.Data["count"] != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if last := result.Data["messages"].([]interface{})[2].(map[string]interface{}); last["job"] != 7.0 {
+		t.Errorf("initial message should carry the job id: %v", last)
+	}
+
+	delete(config, "until")
+	config["maxMessages"] = 2
+	result = executeStreamNode(t, executor, config, map[string]interface{}{"job": 7.0})
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopCount || result.Data["count"] != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	config["headers"] = map[string]interface{}{}
+	if result := executeStreamNode(t, executor, config, nil); result.Status != core.ExecuteStatusError || result.Data["status"] != http.StatusUnauthorized {
+		t.Errorf("handshake failure should fail: %+v", result)
+	}
+}
+
+func TestWebSocketNodeTimeout(t *testing.T) {
+	server := wsServer(t, true)
+	defer server.Close()
+	executor := engine_nodes.NewWebSocketNodeExecutor()
+	config := core.ItemConfig{
+		"url":      "ws" + strings.TrimPrefix(server.URL, "http"),
+		"headers":  map[string]interface{}{"X-Token": "secret"},
+		"messages": []interface{}{"hello"},
+		"timeout":  0.2,
+	}
+
+	result := executeStreamNode(t, executor, config, nil)
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopTimeout {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if messages := result.Data["messages"].([]interface{}); len(messages) != 1 || messages[0] != "waiting" {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+
+	config["until"] = map[string]interface{}{"path": "$", "equals": "done"}
+	if result := executeStreamNode(t, executor, config, nil); result.Status != core.ExecuteStatusError {
+		t.Errorf("unmatched until should fail: %+v", result)
+	}
+}
+
+func sseServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, ": connected\n\n")
+		fmt.Fprintf(w, "event: progress\nid: 1\ndata: {\"progress\": 50, \"method\": %q}\n\n", r.Method)
+		fmt.Fprintf(w, "data: line1\ndata: line2\n\n")
+		fmt.Fprintf(w, "event: progress\nid: 2\ndata: {\"status\": \"done\", \"lastId\": %q}\n\n", r.Header.Get("Last-Event-ID"))
+		fmt.Fprintf(w, "data: after\n\n")
+	}))
+}
+
+func TestSSENode(t *testing.T) {
+	server := sseServer()
+	defer server.Close()
+	executor := engine_nodes.NewSSENodeExecutor()
+	config := core.ItemConfig{
+		"url":         server.URL,
+		"method":      "POST",
+		"body":        map[string]interface{}{"prompt": "hi"},
+		"lastEventId": "{{.cursor}}",
+		"until":       map[string]interface{}{"path": "$.data.status", "equals": "done"},
+	}
+
+	result := executeStreamNode(t, executor, config, map[string]interface{}{"cursor": "0"})
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopMatch {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	messages := result.Data["messages"].([]interface{})
+	if data, _ := json.Marshal(messages); string(data) != `[{"data":{"method":"POST","progress":50},"event":"progress","id":"1"},{"data":"line1\nline2","event":"message","id":"1"},{"data":{"lastId":"0","status":"done"},"event":"progress","id":"2"}]` {
+		t.Errorf("unexpected messages: %s", data)
+	}
+
+	delete(config, "until")
+	result = executeStreamNode(t, executor, config, map[string]interface{}{"cursor": "0"})
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopClosed || result.Data["count"] != 4 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	config["until"] = map[string]interface{}{"path": "$.event", "equals": "finished"}
+	if result := executeStreamNode(t, executor, config, nil); result.Status != core.ExecuteStatusError {
+		t.Errorf("unmatched until should fail when the stream closes: %+v", result)
+	}
+}
+
+func TestStreamNodeWaitsForTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		fmt.Fprintf(w, "data: tick\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	result := executeStreamNode(t, engine_nodes.NewSSENodeExecutor(), core.ItemConfig{"url": server.URL, "timeout": 0.2}, nil)
+	if result.Status != core.ExecuteStatusSuccess || result.Data["reason"] != engine_nodes.StreamStopTimeout || result.Data["count"] != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout not honoured: %v", elapsed)
+	}
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"api-flow/engine"
+	"api-flow/engine/core"
+	"api-flow/engine/engine_nodes"
+)
+
+// testCaseWorkflow login(API) -> greet(文本), greet 的内容来自 login 的响应
+func testCaseWorkflow() ([]engine_nodes.Node, []engine.Edge) {
+	nodes := []engine_nodes.Node{
+		{NodeKey: "login", NodeType: engine_nodes.ApiNodeType.Code, Config: core.ItemConfig{
+			"url":    "https://auth.upstream.invalid/login",
+			"method": "POST",
+			"body":   map[string]interface{}{"user": "{{.user}}"},
+		}},
+		{NodeKey: "greet", NodeType: engine_nodes.TextNodeType.Code, Config: core.ItemConfig{
+			"inputs": map[string]interface{}{"content": "${login.response.name}"},
+		}},
+	}
+	return nodes, []engine.Edge{engine.NewEdge("login", "greet")}
+}
+
+func newTestCase(t *testing.T, raw string) *engine.TestCase {
+	var testCase engine.TestCase
+	if err := json.Unmarshal([]byte(raw), &testCase); err != nil {
+		t.Fatal(err)
+	}
+	if err := testCase.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return &testCase
+}
+
+func TestWorkflowTestCase(t *testing.T) {
+	nodes, edges := testCaseWorkflow()
+	runner := engine.NewRunner(engine_nodes.NewNodeEngine())
+
+	passing := newTestCase(t, `{
+		"name": "greets the logged in user",
+		"inputs": {"user": "bob"},
+		"nodeOutputs": {"login": {"status": 200, "response": {"name": "Bob"}}},
+		"assertions": [
+			{"nodeKey": "login"},
+			{"nodeKey": "greet", "path": "$.output", "equals": "Bob"},
+			{"path": "$.greet.output", "regex": "^B"}
+		]
+	}`)
+	result := passing.Run(context.Background(), runner, nodes, edges)
+	if !result.Passed || len(result.Assertions) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	failing := newTestCase(t, `{
+		"name": "wrong expectation",
+		"nodeOutputs": {"login": {"response": {"name": "Bob"}}},
+		"assertions": [
+			{"nodeKey": "greet", "path": "$.output", "equals": "Alice"},
+			{"nodeKey": "missing"},
+			{"nodeKey": "greet", "status": "error"}
+		]
+	}`)
+	result = failing.Run(context.Background(), runner, nodes, edges)
+	if result.Passed {
+		t.Fatalf("test case should fail: %+v", result)
+	}
+	for i, assertion := range result.Assertions {
+		if assertion.Passed {
+			t.Errorf("assertion %d should fail: %+v", i, assertion)
+		}
+	}
+
+	// 没有断言时要求所有节点执行成功, 未模拟的 login 请求会失败
+	unstubbed := newTestCase(t, `{"name": "live call", "inputs": {"user": "bob"}}`)
+	result = unstubbed.Run(context.Background(), runner, nodes, edges)
+	if result.Passed || !strings.Contains(result.Error, "login") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	report := &engine.TestReport{WorkflowName: "login flow"}
+	report.Add(passing.Run(context.Background(), runner, nodes, edges))
+	report.Add(failing.Run(context.Background(), runner, nodes, edges))
+	if report.Total != 2 || report.Passed != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	data, err := report.JUnitXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xml := string(data)
+	for _, expected := range []string{
+		`<testsuites tests="2" failures="1"`,
+		`<testsuite name="login flow" tests="2" failures="1"`,
+		`<testcase name="greets the logged in user" classname="login flow"`,
+		`<failure message="greet $.output 应等于 &#34;Alice&#34;, 实际为 &#34;Bob&#34;">`,
+	} {
+		if !strings.Contains(xml, expected) {
+			t.Errorf("JUnit XML should contain %s:\n%s", expected, xml)
+		}
+	}
+}
+
+func TestWorkflowTestCaseValidate(t *testing.T) {
+	invalid := []string{
+		`{"assertions": []}`,
+		`{"name": "a", "assertions": [{"status": "success"}]}`,
+		`{"name": "a", "assertions": [{"nodeKey": "n", "status": "done"}]}`,
+		`{"name": "a", "assertions": [{"path": "$[", "equals": 1}]}`,
+		`{"name": "a", "assertions": [{"nodeKey": "n", "path": "$.x", "regex": "("}]}`,
+		`{"name": "a", "nodeOutputs": {"login": "token"}}`,
+		`{"name": "a", "nodeOutputs": {"login": null}}`,
+	}
+	for _, raw := range invalid {
+		var testCase engine.TestCase
+		if err := json.Unmarshal([]byte(raw), &testCase); err != nil {
+			t.Fatal(err)
+		}
+		if err := testCase.Validate(); err == nil {
+			t.Errorf("test case should be invalid: %s", raw)
+		}
+	}
+
+	var testCase engine.TestCase
+	if err := json.Unmarshal([]byte(`{"name": "a", "nodeOutputs": {"login": {"token": "t"}}}`), &testCase); err != nil {
+		t.Fatal(err)
+	}
+	if err := testCase.Validate(); err != nil {
+		t.Errorf("object node outputs should be valid: %v", err)
+	}
+}